@@ -22,7 +22,9 @@ App({
     return `${year}-${month}-${day} ${hour}:${minute}`
   },
 
-  async onLaunch() {
+  async onLaunch(options) {
+    this.recordShareTicket(options)
+
     // 展示本地存储能力
     const logs = wx.getStorageSync('logs') || []
     logs.unshift(Date.now())
@@ -35,6 +37,40 @@ App({
     await this.silentAutoLogin()
   },
 
+  onShow(options) {
+    this.recordShareTicket(options)
+  },
+
+  // 从群聊中的分享卡片打开时会带上shareTicket，用于把房间关联到该群
+  recordShareTicket(options) {
+    if (options && options.shareTicket) {
+      this.globalData.shareTicket = options.shareTicket
+    }
+  },
+
+  // 获取当前群的加密信息，作为创建、加入房间的参数；不是从群聊打开时返回空对象
+  getShareGroup() {
+    const shareTicket = this.globalData.shareTicket
+    if (!shareTicket) {
+      return Promise.resolve({})
+    }
+    return new Promise((resolve) => {
+      wx.getShareInfo({
+        shareTicket,
+        success: (res) => {
+          resolve({
+            share_encrypted_data: res.encryptedData,
+            share_iv: res.iv
+          })
+        },
+        fail: (error) => {
+          console.log('获取群信息失败:', error)
+          resolve({})
+        }
+      })
+    })
+  },
+
   // 初始化用户信息
   initUserInfo() {
     const userInfo = userCache.getCachedUserInfo()
@@ -187,6 +223,7 @@ App({
   },
 
   globalData: {
-    userInfo: null
+    userInfo: null,
+    shareTicket: ''
   }
 })
@@ -372,7 +372,9 @@ Page({
       const defaultRoomName = `麻将房间_${new Date().getTime()}`
       
       // 调用创建房间API
-      const response = await api.createRoom(userInfo.user_id, defaultRoomName)
+      // 从群聊打开时，新房间关联到该群
+      const shareGroup = await app.getShareGroup()
+      const response = await api.createRoom(userInfo.user_id, defaultRoomName, shareGroup)
       
       if (response.code === 200) {
         console.log('创建房间响应:', response)
@@ -710,82 +712,27 @@ Page({
       try {
         wx.showLoading({ title: '上传头像中...' })
         
-        // 引入COS上传工具
-        const cosUploader = require('../../utils/cos.js')
-        
-        // 获取当前用户信息
-        const globalUserInfo = app.globalData.userInfo
-        const storageUserInfo = wx.getStorageSync('userInfo')
-        const userInfo = globalUserInfo || storageUserInfo
-        
-        console.log('全局用户信息:', globalUserInfo)
-        console.log('存储用户信息:', storageUserInfo)
-        console.log('最终用户信息:', userInfo)
-        
-        if (!userInfo) {
-          wx.hideLoading()
-          wx.showToast({
-            title: '用户信息为空，请重新登录',
-            icon: 'none'
-          })
-          return
-        }
-        
-        // 获取openid，兼容不同的字段名
-        const openid = userInfo.openid || userInfo.Openid
-        console.log('用户openid:', openid)
-        
-        if (!openid) {
-          wx.hideLoading()
-          wx.showToast({
-            title: '用户openid缺失，请重新登录',
-            icon: 'none'
-          })
-          return
-        }
-        
-        // 先测试COS存储桶访问权限
-        console.log('测试COS存储桶访问权限...')
-        const testResult = await cosUploader.testBucketAccess()
-        console.log('COS访问权限测试结果:', testResult)
-        
-        // 上传头像到COS，使用openid作为文件名
-        const uploadResult = await cosUploader.uploadAvatar(avatarUrl, openid)
+        // 上传到服务端，服务端校验格式和大小并裁剪为正方形
+        const uploadResult = await api.uploadAvatar(avatarUrl)
         
         wx.hideLoading()
         
-        if (uploadResult.success) {
-          // 上传成功，使用COS的URL
-          this.setData({
-            'profileForm.avatarUrl': uploadResult.url
-          })
-          console.log('头像上传到COS成功:', uploadResult.url)
-          wx.showToast({
-            title: '头像上传成功',
-            icon: 'success'
-          })
-        } else {
-          // 上传失败，使用临时URL作为备选
-          this.setData({
-            'profileForm.avatarUrl': avatarUrl
-          })
-          console.log('COS上传失败，使用临时URL:', avatarUrl)
-          wx.showToast({
-            title: '头像选择成功（临时）',
-            icon: 'success'
-          })
-        }
-      } catch (error) {
-        wx.hideLoading()
-        console.error('头像上传失败:', error)
-        // 上传失败时使用临时URL
         this.setData({
-          'profileForm.avatarUrl': avatarUrl
+          'profileForm.avatarUrl': uploadResult.avatar_url
         })
+        console.log('头像上传成功:', uploadResult.avatar_url)
         wx.showToast({
-          title: '头像选择成功（临时）',
+          title: '头像上传成功',
           icon: 'success'
         })
+      } catch (error) {
+        wx.hideLoading()
+        console.error('头像上传失败:', error)
+        // 临时路径只在本机有效，不能作为头像保存
+        wx.showToast({
+          title: error.message || '头像上传失败',
+          icon: 'none'
+        })
       }
     } else {
       console.log('头像选择失败或取消')
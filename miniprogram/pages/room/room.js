@@ -17,7 +17,7 @@ Page({
     showSettlementModal: false,
     settlementData: [],
     loading: false,
-    qrCodeData: null,
+    qrCodeUrl: '',
     qrCodeLoading: false,
     showAvatarOverlay: false, // 是否显示头像蒙层提示
     avatarOverlayDismissed: false, // 头像蒙层是否已被用户主动关闭
@@ -165,23 +165,34 @@ Page({
     
     // 设置WebSocket事件监听
     this.setupWebSocketListeners();
+
+    // 预先获取订阅消息模板，点击时才能同步弹出授权框
+    api.getSubscribeTemplates().catch((error) => {
+      console.log('获取订阅消息模板失败:', error);
+    });
   },
 
   onShow() {
-    if (this.data.roomId) {
-      this.loadRoomData();
-      // WebSocket连接将在loadRoomData完成后建立
+    if (!this.data.roomId) {
+      return;
     }
+    // 从后台回到前台（如锁屏后），带last_seq重连，由服务端补发错过的消息；缓冲已过期时会收到resync再全量刷新
+    if (wsManager.canResume(this.data.roomId)) {
+      this.connectWebSocket();
+      return;
+    }
+    this.loadRoomData();
+    // WebSocket连接将在loadRoomData完成后建立
   },
 
   onHide() {
-    // 断开WebSocket连接
+    // 断开WebSocket连接，保留已收到的序号
     this.disconnectWebSocket();
   },
 
   onUnload() {
-    // 断开WebSocket连接
-    this.disconnectWebSocket();
+    // 离开房间，断开WebSocket连接并清除序号
+    this.disconnectWebSocket(true);
     
     // 清理缓存，释放内存
     this.cleanupCache();
@@ -573,6 +584,12 @@ Page({
         const userInRoom = players.some(player => player.user_id === userInfo.user_id);
         if (userInRoom) {
           console.log('用户已在房间中');
+          // 从群聊打开时仍通知服务端，把房间关联到该群
+          if (app.globalData.shareTicket) {
+            app.getShareGroup()
+              .then((shareGroup) => api.joinRoom(userInfo.user_id, this.data.roomId, shareGroup))
+              .catch((error) => console.log('关联群失败:', error));
+          }
           return true;
         }
       }
@@ -582,7 +599,8 @@ Page({
       wx.showLoading({ title: '正在加入房间...' });
 
       try {
-        const joinResponse = await api.joinRoom(userInfo.user_id, this.data.roomId);
+        const shareGroup = await app.getShareGroup();
+        const joinResponse = await api.joinRoom(userInfo.user_id, this.data.roomId, shareGroup);
         
         // 添加详细的响应日志
         console.log('加入房间API响应:', joinResponse);
@@ -761,6 +779,13 @@ Page({
       return;
     }
 
+    // 每个房间第一次转移时请求订阅结算通知和付款提醒
+    const subscribeKey = `subscribeAsked_${this.data.roomId}`;
+    if (!wx.getStorageSync(subscribeKey)) {
+      wx.setStorageSync(subscribeKey, true);
+      await api.requestSubscribe();
+    }
+
     // 点击他人头像，设置选中状态并显示转移分数浮窗
     this.setData({
       selectedPlayerId: player.user_id
@@ -1064,8 +1089,9 @@ Page({
 
       if (response.code === 200) {
         const qrData = typeof response.data === 'string' ? JSON.parse(response.data) : response.data;
+        // 服务端返回图片路径，图片按房间和版本缓存，可直接作为image的src
         this.setData({
-          qrCodeData: qrData.qr_code
+          qrCodeUrl: `${api.baseURL}${qrData.qr_code_url}`
         });
         wx.showToast({
           title: '二维码生成成功',
@@ -1270,6 +1296,33 @@ Page({
     }
   },
 
+  // 提醒欠自己分数的玩家付款
+  async remindPayment(e) {
+    const userId = e.currentTarget.dataset.userId;
+    try {
+      wx.showLoading({ title: '发送中...' });
+      const response = await api.remindPayment(this.data.roomId, userId);
+      wx.hideLoading();
+      const result = JSON.parse(response.data || '{}');
+      if (result.queued && result.queued.length > 0) {
+        wx.showToast({ title: '已提醒对方', icon: 'success' });
+      } else {
+        const skipped = result.skipped && result.skipped[0];
+        wx.showToast({
+          title: skipped ? skipped.reason : '提醒失败',
+          icon: 'none'
+        });
+      }
+    } catch (error) {
+      wx.hideLoading();
+      console.error('提醒付款失败:', error);
+      wx.showToast({
+        title: error.message || '提醒失败',
+        icon: 'none'
+      });
+    }
+  },
+
   // 隐藏结算浮窗
   hideSettlementModal() {
     this.setData({
@@ -1386,82 +1439,27 @@ Page({
       try {
         wx.showLoading({ title: '上传头像中...' })
         
-        // 引入COS上传工具
-        const cosUploader = require('../../utils/cos.js')
-        
-        // 获取当前用户信息
-        const globalUserInfo = app.globalData.userInfo
-        const storageUserInfo = wx.getStorageSync('userInfo')
-        const userInfo = globalUserInfo || storageUserInfo
-        
-        console.log('全局用户信息:', globalUserInfo)
-        console.log('存储用户信息:', storageUserInfo)
-        console.log('最终用户信息:', userInfo)
-        
-        if (!userInfo) {
-          wx.hideLoading()
-          wx.showToast({
-            title: '用户信息为空，请重新登录',
-            icon: 'none'
-          })
-          return
-        }
-        
-        // 获取openid，兼容不同的字段名
-        const openid = userInfo.openid || userInfo.Openid
-        console.log('用户openid:', openid)
-        
-        if (!openid) {
-          wx.hideLoading()
-          wx.showToast({
-            title: '用户openid缺失，请重新登录',
-            icon: 'none'
-          })
-          return
-        }
-        
-        // 先测试COS存储桶访问权限
-        console.log('测试COS存储桶访问权限...')
-        const testResult = await cosUploader.testBucketAccess()
-        console.log('COS访问权限测试结果:', testResult)
-        
-        // 上传头像到COS，使用openid作为文件名
-        const uploadResult = await cosUploader.uploadAvatar(avatarUrl, openid)
+        // 上传到服务端，服务端校验格式和大小并裁剪为正方形
+        const uploadResult = await api.uploadAvatar(avatarUrl)
         
         wx.hideLoading()
         
-        if (uploadResult.success) {
-          // 上传成功，使用COS的URL
-          this.setData({
-            'profileForm.avatarUrl': uploadResult.url
-          })
-          console.log('头像上传到COS成功:', uploadResult.url)
-          wx.showToast({
-            title: '头像上传成功',
-            icon: 'success'
-          })
-        } else {
-          // 上传失败，使用临时URL作为备选
-          this.setData({
-            'profileForm.avatarUrl': avatarUrl
-          })
-          console.log('COS上传失败，使用临时URL:', avatarUrl)
-          wx.showToast({
-            title: '头像选择成功（临时）',
-            icon: 'success'
-          })
-        }
-      } catch (error) {
-        wx.hideLoading()
-        console.error('头像上传失败:', error)
-        // 上传失败时使用临时URL
         this.setData({
-          'profileForm.avatarUrl': avatarUrl
+          'profileForm.avatarUrl': uploadResult.avatar_url
         })
+        console.log('头像上传成功:', uploadResult.avatar_url)
         wx.showToast({
-          title: '头像选择成功（临时）',
+          title: '头像上传成功',
           icon: 'success'
         })
+      } catch (error) {
+        wx.hideLoading()
+        console.error('头像上传失败:', error)
+        // 临时路径只在本机有效，不能作为头像保存
+        wx.showToast({
+          title: error.message || '头像上传失败',
+          icon: 'none'
+        })
       }
     } else {
       console.log('用户取消选择头像')
@@ -1514,11 +1512,22 @@ Page({
       wx.hideLoading()
 
       if (response.code === 200) {
+        // 服务端可能清理或打码昵称，以返回值为准
+        let savedNickname = nickname.trim()
+        try {
+          const result = typeof response.data === 'string' ? JSON.parse(response.data) : response.data
+          if (result && result.nickname) {
+            savedNickname = result.nickname
+          }
+        } catch (error) {
+          console.error('解析昵称失败:', error)
+        }
+
         // 更新本地用户信息
         const updatedUserInfo = {
           ...userInfo,
-          nickName: nickname.trim(),
-          nickname: nickname.trim(),
+          nickName: savedNickname,
+          nickname: savedNickname,
           avatarUrl: avatarUrl,
           avatar_url: avatarUrl
         }
@@ -1549,7 +1558,8 @@ Page({
       wx.hideLoading()
       console.error('保存个人信息失败:', error)
       wx.showToast({
-        title: '保存失败',
+        // 昵称未通过校验时提示具体原因
+        title: error.message || '保存失败',
         icon: 'none'
       })
     }
@@ -1726,6 +1736,12 @@ Page({
       // 移除连接成功的弹窗提示，避免打扰用户
     });
 
+    // 断线太久，服务端无法补发错过的消息，重新拉取房间数据
+    wsManager.on('resync', () => {
+      console.log('WebSocket需要全量同步');
+      this.loadRoomData();
+    });
+
     wsManager.on('disconnected', () => {
       console.log('WebSocket连接已断开');
       // 移除断开连接的弹窗提示，避免打扰用户
@@ -1755,9 +1771,9 @@ Page({
     }
   },
 
-  // 断开WebSocket连接
-  disconnectWebSocket() {
-    wsManager.disconnect();
+  // 断开WebSocket连接，resetSeq为true时不再补发该房间的消息
+  disconnectWebSocket(resetSeq = false) {
+    wsManager.disconnect(resetSeq);
     console.log('WebSocket连接已断开');
   },
 
@@ -76,22 +76,27 @@ class ApiService {
   }
 
   // 房间相关API
-  async createRoom(creatorId, roomName) {
+  // shareGroup为app.getShareGroup()的结果，从群聊打开时把房间关联到该群，服务端按session_id校验用户
+  async createRoom(creatorId, roomName, shareGroup) {
     return this.request('/api/v1/createRoom', {
       method: 'POST',
       data: {
         creator_id: creatorId,
         room_name: roomName,
+        session_id: wx.getStorageSync('sessionID'),
+        ...shareGroup,
       },
     });
   }
 
-  async joinRoom(userId, roomId) {
+  async joinRoom(userId, roomId, shareGroup) {
     return this.request('/api/v1/joinRoom', {
       method: 'POST',
       data: {
         user_id: userId,
         room_id: roomId,
+        session_id: wx.getStorageSync('sessionID'),
+        ...shareGroup,
       },
     });
   }
@@ -172,6 +177,135 @@ class ApiService {
     });
   }
 
+  // 上传头像，服务端校验并裁剪后保存，返回data.avatar_url
+  async uploadAvatar(filePath) {
+    return new Promise((resolve, reject) => {
+      wx.uploadFile({
+        url: `${this.baseURL}/api/v1/uploadAvatar`,
+        filePath,
+        name: 'file',
+        header: {
+          'X-Session-Id': wx.getStorageSync('sessionID'),
+        },
+        success: (response) => {
+          let data = null;
+          try {
+            data = JSON.parse(response.data);
+          } catch (e) {
+            reject(new Error(`上传失败: ${response.statusCode}`));
+            return;
+          }
+          if (data && data.code === 200) {
+            resolve(JSON.parse(data.data));
+          } else {
+            reject(new Error(data && data.message ? data.message : '上传失败'));
+          }
+        },
+        fail: (error) => {
+          console.error('头像上传错误:', error);
+          reject(error);
+        }
+      });
+    });
+  }
+
+  // 订阅消息模板，key为消息类型（room_settled、payment_reminder），value为模板ID
+  async getSubscribeTemplates() {
+    if (!this.subscribeTemplates) {
+      const response = await this.request('/api/v1/getSubscribeTemplates');
+      this.subscribeTemplates = JSON.parse(response.data || '{}');
+    }
+    return this.subscribeTemplates;
+  }
+
+  // 请求订阅消息授权并上报用户同意的模板，需在点击事件中调用；失败时不影响后续操作
+  async requestSubscribe() {
+    try {
+      const templates = await this.getSubscribeTemplates();
+      const tmplIds = Object.values(templates);
+      if (tmplIds.length === 0) {
+        return;
+      }
+      const result = await new Promise((resolve, reject) => {
+        wx.requestSubscribeMessage({ tmplIds, success: resolve, fail: reject });
+      });
+      const accepted = tmplIds.filter((id) => result[id] === 'accept');
+      if (accepted.length > 0) {
+        await this.request('/api/v1/subscribeConsent', {
+          method: 'POST',
+          data: {
+            session_id: wx.getStorageSync('sessionID'),
+            template_ids: accepted,
+          },
+        });
+      }
+    } catch (error) {
+      console.log('订阅消息授权未完成:', error);
+    }
+  }
+
+  // 提醒欠自己分数的玩家付款，userId为空时提醒所有人
+  async remindPayment(roomId, userId) {
+    return this.request('/api/v1/remindPayment', {
+      method: 'POST',
+      data: {
+        session_id: wx.getStorageSync('sessionID'),
+        room_id: roomId,
+        user_id: userId || 0,
+      },
+    });
+  }
+
+  // 解密<button open-type="getPhoneNumber">返回的手机号，e为bindgetphonenumber的事件
+  async decryptPhoneNumber(e) {
+    return this.request('/api/v1/decryptPhoneNumber', {
+      method: 'POST',
+      data: {
+        session_id: wx.getStorageSync('sessionID'),
+        encrypted_data: e.detail.encryptedData,
+        iv: e.detail.iv,
+      },
+    });
+  }
+
+  // 解密wx.getShareInfo返回的群信息，得到群的open_gid
+  async decryptShareInfo(shareInfo) {
+    return this.request('/api/v1/decryptShareInfo', {
+      method: 'POST',
+      data: {
+        session_id: wx.getStorageSync('sessionID'),
+        encrypted_data: shareInfo.encryptedData,
+        iv: shareInfo.iv,
+      },
+    });
+  }
+
+  // 群相关API，需要是该群的成员
+  async getUserGroups() {
+    return this.request('/api/v1/getUserGroups', {
+      header: { 'X-Session-Id': wx.getStorageSync('sessionID') },
+    });
+  }
+
+  async getGroupRooms(groupId, page = 1, pageSize = 10) {
+    return this.request(`/api/v1/getGroupRooms?group_id=${groupId}&page=${page}&page_size=${pageSize}`, {
+      header: { 'X-Session-Id': wx.getStorageSync('sessionID') },
+    });
+  }
+
+  // days为0时统计全部已结算的房间
+  async getGroupLeaderboard(groupId, days = 0) {
+    return this.request(`/api/v1/getGroupLeaderboard?group_id=${groupId}&days=${days}`, {
+      header: { 'X-Session-Id': wx.getStorageSync('sessionID') },
+    });
+  }
+
+  async getGroupSuggestedMembers(groupId, limit = 10) {
+    return this.request(`/api/v1/getGroupSuggestedMembers?group_id=${groupId}&limit=${limit}`, {
+      header: { 'X-Session-Id': wx.getStorageSync('sessionID') },
+    });
+  }
+
   // 生成房间二维码
   async generateQRCode(roomId, envVersion) {
     return this.request('/api/v1/generateQRCode', {
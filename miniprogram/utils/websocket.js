@@ -4,13 +4,18 @@ class WebSocketManager {
     this.socket = null
     this.roomId = null
     this.userId = null
+    this.lastSeq = null // 已收到的最后消息序号，重连时用于补发
     this.isConnected = false
     this.reconnectAttempts = 0
     this.maxReconnectAttempts = 5
     this.reconnectInterval = 3000
+    this.restartDelay = null // 服务重启时建议的重连等待时间
     this.heartbeatInterval = null
     this.messageHandlers = new Map()
     this.connectionHandlers = new Map()
+    this.pendingRequests = new Map() // request_id -> { resolve, reject, timer }
+    this.nextRequestId = 1
+    this.requestTimeout = 10000
   }
 
   // 连接到WebSocket服务器
@@ -20,14 +25,22 @@ class WebSocketManager {
       return Promise.resolve()
     }
 
+    // 切换房间时序号不再有效
+    if (this.roomId !== roomId) {
+      this.lastSeq = null
+    }
     this.roomId = roomId
     this.userId = userId
 
     return new Promise((resolve, reject) => {
       try {
-        // 构建WebSocket URL
-        const wsUrl = `wss://www.aipaint.cloud/ws?room_id=${roomId}&user_id=${userId}`
-        console.log('正在连接WebSocket:', wsUrl)
+        // 构建WebSocket URL，服务端按session_id确定连接的用户，重连时携带last_seq以补发错过的消息
+        const sessionID = encodeURIComponent(wx.getStorageSync('sessionID') || '')
+        let wsUrl = `wss://www.aipaint.cloud/ws?room_id=${roomId}&session_id=${sessionID}`
+        if (this.lastSeq !== null) {
+          wsUrl += `&last_seq=${this.lastSeq}`
+        }
+        console.log('正在连接WebSocket:', roomId, userId)
 
         this.socket = wx.connectSocket({
           url: wsUrl,
@@ -52,13 +65,19 @@ class WebSocketManager {
 
         // 监听消息事件
         this.socket.onMessage((res) => {
-          try {
-            const message = JSON.parse(res.data)
-            console.log('收到WebSocket消息:', message)
-            this.handleMessage(message)
-          } catch (error) {
-            console.error('解析WebSocket消息失败:', error)
-          }
+          // 服务端可能在一帧中以换行分隔发送多条消息
+          String(res.data).split('\n').forEach(line => {
+            if (!line) {
+              return
+            }
+            try {
+              const message = JSON.parse(line)
+              console.log('收到WebSocket消息:', message)
+              this.handleMessage(message)
+            } catch (error) {
+              console.error('解析WebSocket消息失败:', error)
+            }
+          })
         })
 
         // 监听连接关闭事件
@@ -66,6 +85,7 @@ class WebSocketManager {
           console.log('WebSocket连接已关闭:', res)
           this.isConnected = false
           this.stopHeartbeat()
+          this.rejectPendingRequests()
           this.emit('disconnected', res)
           
           // 如果不是主动关闭，尝试重连
@@ -89,8 +109,11 @@ class WebSocketManager {
     })
   }
 
-  // 断开连接
-  disconnect() {
+  // 断开连接；切到后台时保留lastSeq，回到前台重连同一房间可补发错过的消息，离开房间时传入resetSeq
+  disconnect(resetSeq = false) {
+    if (resetSeq) {
+      this.lastSeq = null
+    }
     if (this.socket) {
       console.log('主动断开WebSocket连接')
       this.stopHeartbeat()
@@ -103,6 +126,11 @@ class WebSocketManager {
     }
   }
 
+  // 是否可以带last_seq重连该房间，只补发错过的消息而不必重新拉取房间数据
+  canResume(roomId) {
+    return !this.isConnected && this.roomId === roomId && this.lastSeq !== null
+  }
+
   // 发送消息
   send(data) {
     if (this.socket && this.isConnected) {
@@ -125,9 +153,76 @@ class WebSocketManager {
     }
   }
 
+  // 发送命令并等待应答，可用命令：transfer、undo、ready、chat、ping
+  request(type, data = {}) {
+    if (!this.socket || !this.isConnected) {
+      return Promise.reject(new Error('WebSocket未连接'))
+    }
+
+    const requestId = `${Date.now()}_${this.nextRequestId++}`
+    return new Promise((resolve, reject) => {
+      const timer = setTimeout(() => {
+        this.pendingRequests.delete(requestId)
+        reject(new Error(`命令${type}超时`))
+      }, this.requestTimeout)
+
+      this.pendingRequests.set(requestId, { resolve, reject, timer })
+      this.send({ type, request_id: requestId, data })
+    })
+  }
+
+  // 处理命令应答
+  handleReply(message) {
+    const pending = this.pendingRequests.get(message.request_id)
+    if (!pending) {
+      return
+    }
+
+    this.pendingRequests.delete(message.request_id)
+    clearTimeout(pending.timer)
+    if (message.type === 'error') {
+      const error = new Error(message.data && message.data.message)
+      error.code = message.data && message.data.code
+      pending.reject(error)
+    } else {
+      pending.resolve(message.data)
+    }
+  }
+
+  // 连接断开时拒绝所有未完成的命令
+  rejectPendingRequests() {
+    this.pendingRequests.forEach(pending => {
+      clearTimeout(pending.timer)
+      pending.reject(new Error('WebSocket连接已断开'))
+    })
+    this.pendingRequests.clear()
+  }
+
   // 处理接收到的消息
   handleMessage(message) {
-    const { type, data } = message
+    const { type, data, seq } = message
+
+    if (message.request_id) {
+      this.handleReply(message)
+      return
+    }
+
+    // 记录最后收到的序号；resync_required表示缓冲已过期，需要全量刷新
+    if (typeof seq === 'number') {
+      this.lastSeq = seq
+    } else if (type === 'connected' || type === 'resync_required') {
+      // 房间还没有任何消息时序号为0，字段被省略
+      this.lastSeq = 0
+    }
+    if (type === 'resync_required') {
+      this.emit('resync')
+    }
+    // 服务即将重启，随后连接会以1012关闭，按服务端建议的时间重连
+    if (type === 'server_restarting') {
+      const retryAfter = (data && data.retry_after) || 0
+      this.restartDelay = retryAfter * 1000
+      this.reconnectAttempts = 0
+    }
     
     // 触发对应类型的消息处理器
     if (this.messageHandlers.has(type)) {
@@ -219,6 +314,13 @@ class WebSocketManager {
   scheduleReconnect() {
     this.reconnectAttempts++
     console.log(`WebSocket重连尝试 ${this.reconnectAttempts}/${this.maxReconnectAttempts}`)
+
+    // 服务重启时加上随机抖动，避免所有客户端同时重连
+    let delay = this.reconnectInterval
+    if (this.restartDelay !== null) {
+      delay = this.restartDelay + Math.floor(Math.random() * 2000)
+      this.restartDelay = null
+    }
     
     setTimeout(() => {
       if (this.roomId && this.userId) {
@@ -230,7 +332,7 @@ class WebSocketManager {
           }
         })
       }
-    }, this.reconnectInterval)
+    }, delay)
   }
 
   // 获取连接状态
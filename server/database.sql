@@ -6,9 +6,12 @@ USE mahjong_score;
 -- 用户表
 CREATE TABLE users (
     id BIGINT PRIMARY KEY AUTO_INCREMENT,
-    openid VARCHAR(64) NOT NULL UNIQUE COMMENT '微信openid',
+    openid VARCHAR(64) NOT NULL UNIQUE COMMENT '首次登录的小程序中的openid，发送消息等按user_openids取当前小程序的openid',
+    unionid VARCHAR(64) NULL UNIQUE COMMENT '微信unionid，同一开放平台账号下的公众号、小程序相同',
     nickname VARCHAR(50) NOT NULL DEFAULT '' COMMENT '用户昵称',
     avatar_url VARCHAR(255) DEFAULT '' COMMENT '头像URL',
+    phone_number VARCHAR(20) NOT NULL DEFAULT '' COMMENT '手机号（解密getPhoneNumber得到，只返回给本人）',
+    merged_into BIGINT NOT NULL DEFAULT 0 COMMENT '已合并到的用户ID，0表示未合并',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     INDEX idx_openid (openid)
@@ -23,7 +26,9 @@ CREATE TABLE rooms (
     status TINYINT NOT NULL DEFAULT 1 COMMENT '房间状态：1-进行中，2-已结算',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     settled_at TIMESTAMP NULL COMMENT '结算时间',
+    group_id BIGINT NOT NULL DEFAULT 0 COMMENT '关联的微信群ID，0表示未关联',
     INDEX idx_room_code (room_code),
+    INDEX idx_group_id (group_id),
     INDEX idx_creator_id (creator_id),
     INDEX idx_status (status)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='房间表';
@@ -49,6 +54,7 @@ CREATE TABLE score_transfers (
     to_user_id BIGINT NOT NULL COMMENT '转入用户ID',
     amount INT NOT NULL COMMENT '转移分数',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    voided_at TIMESTAMP NULL COMMENT '撤销时间',
     INDEX idx_room_id (room_id),
     INDEX idx_from_user (from_user_id),
     INDEX idx_to_user (to_user_id),
@@ -88,3 +94,106 @@ CREATE TABLE user_sessions (
     INDEX idx_session_id (session_id),
     INDEX idx_expires_at (expires_at)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户会话表';
+
+-- 用户openid表（同一个人在同一开放平台下的各小程序中openid不同，按小程序分别保存）
+CREATE TABLE user_openids (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    app_id VARCHAR(64) NOT NULL COMMENT '小程序AppID',
+    openid VARCHAR(64) NOT NULL COMMENT '用户在该小程序的openid',
+    user_id BIGINT NOT NULL COMMENT '用户ID',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_app_openid (app_id, openid),
+    UNIQUE KEY uk_user_app (user_id, app_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户openid表';
+
+-- 用户session_key表（加密保存，用于解密开放数据，重新登录时更新）
+CREATE TABLE user_session_keys (
+    user_id BIGINT PRIMARY KEY COMMENT '用户ID',
+    session_key VARCHAR(128) NOT NULL COMMENT 'AES-GCM加密后的session_key（base64）',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户session_key表';
+
+-- 微信群表（由分享卡片的shareTicket解密得到openGId）
+CREATE TABLE wechat_groups (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    open_gid VARCHAR(64) NOT NULL UNIQUE COMMENT '群的openGId，同一个群对同一个小程序不变',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='微信群表';
+
+-- 群成员表（从该群的分享卡片打开过小程序）
+CREATE TABLE group_members (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    group_id BIGINT NOT NULL COMMENT '群ID',
+    user_id BIGINT NOT NULL COMMENT '用户ID',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '最近一次从该群的分享卡片打开',
+    UNIQUE KEY uk_group_user (group_id, user_id),
+    INDEX idx_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='群成员表';
+
+-- 房间事件表（只追加，房间状态可由事件回放得到）
+CREATE TABLE room_events (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    room_id BIGINT NOT NULL COMMENT '房间ID',
+    event_type VARCHAR(20) NOT NULL COMMENT '事件类型：joined, transferred, voided, settled',
+    user_id BIGINT NOT NULL DEFAULT 0 COMMENT '加入者/转出用户/操作者ID',
+    target_user_id BIGINT NOT NULL DEFAULT 0 COMMENT '转入用户ID',
+    amount INT NOT NULL DEFAULT 0 COMMENT '转移分数',
+    transfer_id BIGINT NOT NULL DEFAULT 0 COMMENT '关联的转移记录ID',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_room_id (room_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='房间事件表';
+
+-- 订阅消息授权表（一次性订阅，用户每同意一次可发送一条）
+CREATE TABLE subscribe_consents (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    user_id BIGINT NOT NULL COMMENT '用户ID',
+    template_id VARCHAR(64) NOT NULL COMMENT '订阅消息模板ID',
+    remaining INT NOT NULL DEFAULT 0 COMMENT '剩余可发送条数',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_user_template (user_id, template_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='订阅消息授权表';
+
+-- 订阅消息发送队列
+CREATE TABLE notifications (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    user_id BIGINT NOT NULL COMMENT '接收用户ID',
+    room_id BIGINT NOT NULL DEFAULT 0 COMMENT '关联房间ID',
+    kind VARCHAR(20) NOT NULL COMMENT '消息类型：room_settled, payment_reminder',
+    template_id VARCHAR(64) NOT NULL COMMENT '订阅消息模板ID',
+    page VARCHAR(128) NOT NULL DEFAULT '' COMMENT '点击消息打开的页面',
+    data TEXT NOT NULL COMMENT '模板数据JSON',
+    status TINYINT NOT NULL DEFAULT 0 COMMENT '状态：0待发送 1已发送 2失败',
+    attempts INT NOT NULL DEFAULT 0 COMMENT '已尝试次数',
+    last_error VARCHAR(255) NOT NULL DEFAULT '' COMMENT '最近一次失败原因',
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '下次发送时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP NULL COMMENT '发送成功时间',
+    INDEX idx_status_next (status, next_attempt_at),
+    INDEX idx_user_created (user_id, created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='订阅消息发送队列';
+
+-- 升级已有数据库：补充撤销字段，并由现有数据回填房间事件
+-- ALTER TABLE score_transfers ADD COLUMN voided_at TIMESTAMP NULL COMMENT '撤销时间' AFTER created_at;
+-- INSERT INTO room_events (room_id, event_type, user_id, created_at)
+--     SELECT room_id, 'joined', user_id, joined_at FROM room_players;
+-- INSERT INTO room_events (room_id, event_type, user_id, target_user_id, amount, transfer_id, created_at)
+--     SELECT room_id, 'transferred', from_user_id, to_user_id, amount, id, created_at FROM score_transfers ORDER BY id;
+-- INSERT INTO room_events (room_id, event_type, user_id, created_at)
+--     SELECT id, 'settled', creator_id, settled_at FROM rooms WHERE status = 2;
+
+-- 升级已有数据库：补充手机号字段
+-- ALTER TABLE users ADD COLUMN phone_number VARCHAR(20) NOT NULL DEFAULT '' COMMENT '手机号（解密getPhoneNumber得到，只返回给本人）' AFTER avatar_url;
+
+-- 升级已有数据库：房间关联微信群
+-- ALTER TABLE rooms ADD COLUMN group_id BIGINT NOT NULL DEFAULT 0 COMMENT '关联的微信群ID，0表示未关联' AFTER settled_at, ADD INDEX idx_group_id (group_id);
+
+-- 升级已有数据库：unionid和用户合并
+-- ALTER TABLE users ADD COLUMN unionid VARCHAR(64) NULL UNIQUE COMMENT '微信unionid，同一开放平台账号下的公众号、小程序相同' AFTER openid,
+--     ADD COLUMN merged_into BIGINT NOT NULL DEFAULT 0 COMMENT '已合并到的用户ID，0表示未合并' AFTER phone_number;
+
+-- 升级已有数据库：按小程序保存openid，<AppID>替换为之前使用的小程序AppID（已合并的用户归到合并后的用户）
+-- CREATE TABLE user_openids ...（见上方建表语句）
+-- INSERT IGNORE INTO user_openids (app_id, openid, user_id)
+--     SELECT '<AppID>', openid, IF(merged_into = 0, id, merged_into) FROM users ORDER BY merged_into = 0 DESC, id;
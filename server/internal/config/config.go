@@ -4,15 +4,22 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 )
 
 type Config struct {
-	Database DatabaseConfig
-	HTTP     HTTPConfig
-	WeChat   WeChatConfig
-	COS      COSConfig
-	Log      LogConfig
-	Service  ServiceConfig
+	Database  DatabaseConfig
+	HTTP      HTTPConfig
+	WeChat    WeChatConfig
+	COS       COSConfig
+	Storage   StorageConfig
+	Log       LogConfig
+	Service   ServiceConfig
+	Admin     AdminConfig
+	Broadcast BroadcastConfig
+	Tracing   TracingConfig
+	Notify    NotifyConfig
+	Content   ContentConfig
 }
 
 type DatabaseConfig struct {
@@ -24,14 +31,21 @@ type DatabaseConfig struct {
 }
 
 type HTTPConfig struct {
-	Port     int
-	CertFile string
-	KeyFile  string
+	Port           int
+	CertFile       string
+	KeyFile        string
+	RequestTimeout time.Duration // 单个HTTP请求或WebSocket命令的处理超时，超时后取消其数据库查询
+	AdminTimeout   time.Duration // 管理接口（如全库一致性检查）的处理超时，代替RequestTimeout
+	UploadTimeout  time.Duration // 头像上传的处理超时，慢速网络下上传需要更长时间
 }
 
 type WeChatConfig struct {
-	AppID     string
-	AppSecret string
+	AppID      string
+	AppSecret  string
+	TokenStore string // access_token的存储：memory（单实例）或 redis（多实例共享，使用Broadcast.Redis的连接配置）
+	APIBase    string // 微信接口地址，可指向代理或本地模拟服务
+	// SessionKeySecret 加密保存session_key的密钥，为空时不保存session_key，手机号、群信息解密接口不可用
+	SessionKeySecret string
 }
 
 type COSConfig struct {
@@ -41,9 +55,61 @@ type COSConfig struct {
 	SecretKey string
 }
 
+type StorageConfig struct {
+	Backend   string // local（默认，保存在LocalDir）或 cos（使用COSConfig，多实例部署时使用）
+	LocalDir  string
+	PublicURL string // 头像等公开文件的地址前缀；local默认为本服务的/api/v1/files，cos默认为存储桶域名
+}
+
+// NotifyConfig 订阅消息，模板ID为空时不发送对应消息
+type NotifyConfig struct {
+	RoomSettledTemplate     string
+	PaymentReminderTemplate string
+	RoomSettledFields       string // 模板关键词映射，如 room:thing1,score:character_string2，为空时使用默认映射
+	PaymentReminderFields   string
+	MiniprogramState        string        // 点击消息打开的小程序版本：developer、trial或formal
+	UserHourlyLimit         int           // 每个用户每小时最多收到的消息数，0表示不限
+	ReminderInterval        time.Duration // 同一房间对同一用户的付款提醒间隔
+	MaxAttempts             int           // 发送失败后的最多尝试次数
+}
+
+// ContentConfig 昵称、房间名的内容安全检测
+type ContentConfig struct {
+	Action       string // 命中敏感内容时：reject（拒绝保存）或 mask（打码后保存）
+	WeChatCheck  bool   // 调用微信msg_sec_check，失败时只使用本地词库
+	KeywordsFile string // 追加的本地敏感词文件，每行一个词
+}
+
 type LogConfig struct {
-	Level string
-	Dir   string
+	Level      string
+	Dir        string
+	Format     string // text、json或logfmt
+	MaxSizeMB  int    // 单个日志文件的最大大小，超过后轮转；另外每天零点也会轮转
+	MaxAgeDays int    // 轮转后的文件保留天数，0表示不按天数清理
+	MaxBackups int    // 最多保留的轮转文件数，0表示不限
+	Compress   bool   // 轮转后的文件用gzip压缩
+}
+
+type AdminConfig struct {
+	Token string // 管理接口令牌，为空时禁用管理接口
+}
+
+type BroadcastConfig struct {
+	Backend string // memory（单实例）或 redis（多实例）
+	Channel string
+	Redis   RedisConfig
+}
+
+type RedisConfig struct {
+	Addr     string
+	Password string
+	DB       int
+}
+
+type TracingConfig struct {
+	Exporter    string  // none（默认，不启用）、stdout（本地调试）、otlp
+	Endpoint    string  // OTLP/HTTP Collector地址
+	SampleRatio float64 // 根span采样比例，0~1
 }
 
 type ServiceConfig struct {
@@ -62,29 +128,78 @@ func Load() *Config {
 			Database: getEnv("DB_NAME", "mahjong_score"),
 		},
 		HTTP: HTTPConfig{
-			Port:     getEnvAsInt("HTTP_PORT", 8080),
-			CertFile: getEnv("SSL_CERT_FILE", "/etc/ssl/certs/aipaint.cloud.crt"),
-			KeyFile:  getEnv("SSL_KEY_FILE", "/etc/ssl/private/aipaint.cloud.key"),
+			Port:           getEnvAsInt("HTTP_PORT", 8080),
+			CertFile:       getEnv("SSL_CERT_FILE", "/etc/ssl/certs/aipaint.cloud.crt"),
+			KeyFile:        getEnv("SSL_KEY_FILE", "/etc/ssl/private/aipaint.cloud.key"),
+			RequestTimeout: time.Duration(getEnvAsInt("HTTP_REQUEST_TIMEOUT", 10)) * time.Second,
+			AdminTimeout:   time.Duration(getEnvAsInt("HTTP_ADMIN_TIMEOUT", 120)) * time.Second,
+			UploadTimeout:  time.Duration(getEnvAsInt("HTTP_UPLOAD_TIMEOUT", 60)) * time.Second,
 		},
 		WeChat: WeChatConfig{
-			AppID:     getEnvRequired("WECHAT_APP_ID"),
-			AppSecret: getEnvRequired("WECHAT_APP_SECRET"),
+			AppID:      getEnvRequired("WECHAT_APP_ID"),
+			AppSecret:  getEnvRequired("WECHAT_APP_SECRET"),
+			TokenStore: getEnv("WECHAT_TOKEN_STORE", "memory"),
+			APIBase:    getEnv("WECHAT_API_BASE", "https://api.weixin.qq.com"),
+
+			SessionKeySecret: getEnv("WECHAT_SESSION_KEY_SECRET", ""),
 		},
 		COS: COSConfig{
-			Bucket:    getEnvRequired("COS_BUCKET"),
-			Region:    getEnvRequired("COS_REGION"),
-			SecretID:  getEnvRequired("COS_SECRET_ID"),
-			SecretKey: getEnvRequired("COS_SECRET_KEY"),
+			Bucket:    getEnv("COS_BUCKET", ""),
+			Region:    getEnv("COS_REGION", ""),
+			SecretID:  getEnv("COS_SECRET_ID", ""),
+			SecretKey: getEnv("COS_SECRET_KEY", ""),
+		},
+		Storage: StorageConfig{
+			Backend:   getEnv("STORAGE_BACKEND", "local"),
+			LocalDir:  getEnv("STORAGE_LOCAL_DIR", "/root/horry/score/server/data"),
+			PublicURL: getEnv("STORAGE_PUBLIC_URL", ""),
 		},
 		Log: LogConfig{
-			Level: getEnv("LOG_LEVEL", "INFO"),
-			Dir:   getEnv("LOG_DIR", "/root/horry/score/server/logs"),
+			Level:      getEnv("LOG_LEVEL", "INFO"),
+			Dir:        getEnv("LOG_DIR", "/root/horry/score/server/logs"),
+			Format:     getEnv("LOG_FORMAT", "text"),
+			MaxSizeMB:  getEnvAsInt("LOG_MAX_SIZE_MB", 100),
+			MaxAgeDays: getEnvAsInt("LOG_MAX_AGE_DAYS", 14),
+			MaxBackups: getEnvAsInt("LOG_MAX_BACKUPS", 30),
+			Compress:   getEnvAsBool("LOG_COMPRESS", true),
 		},
 		Service: ServiceConfig{
 			Name:    getEnv("SERVICE_NAME", "mahjong-server"),
 			User:    getEnv("SERVICE_USER", "root"),
 			WorkDir: getEnv("SERVICE_WORK_DIR", "/root/horry/score/server"),
 		},
+		Admin: AdminConfig{
+			Token: getEnv("ADMIN_TOKEN", ""),
+		},
+		Broadcast: BroadcastConfig{
+			Backend: getEnv("BROADCAST_BACKEND", "memory"),
+			Channel: getEnv("BROADCAST_CHANNEL", "mahjong:room_events"),
+			Redis: RedisConfig{
+				Addr:     getEnv("REDIS_ADDR", "127.0.0.1:6379"),
+				Password: getEnv("REDIS_PASSWORD", ""),
+				DB:       getEnvAsInt("REDIS_DB", 0),
+			},
+		},
+		Tracing: TracingConfig{
+			Exporter:    getEnv("TRACING_EXPORTER", "none"),
+			Endpoint:    getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://127.0.0.1:4318"),
+			SampleRatio: getEnvAsFloat("TRACING_SAMPLE_RATIO", 1),
+		},
+		Notify: NotifyConfig{
+			RoomSettledTemplate:     getEnv("NOTIFY_TEMPLATE_ROOM_SETTLED", ""),
+			PaymentReminderTemplate: getEnv("NOTIFY_TEMPLATE_PAYMENT_REMINDER", ""),
+			RoomSettledFields:       getEnv("NOTIFY_ROOM_SETTLED_FIELDS", ""),
+			PaymentReminderFields:   getEnv("NOTIFY_PAYMENT_REMINDER_FIELDS", ""),
+			MiniprogramState:        getEnv("NOTIFY_MINIPROGRAM_STATE", "formal"),
+			UserHourlyLimit:         getEnvAsInt("NOTIFY_USER_HOURLY_LIMIT", 5),
+			ReminderInterval:        time.Duration(getEnvAsInt("NOTIFY_REMINDER_INTERVAL_MINUTES", 360)) * time.Minute,
+			MaxAttempts:             getEnvAsInt("NOTIFY_MAX_ATTEMPTS", 5),
+		},
+		Content: ContentConfig{
+			Action:       getEnv("CONTENT_CHECK_ACTION", "reject"),
+			WeChatCheck:  getEnvAsBool("CONTENT_WECHAT_CHECK", false),
+			KeywordsFile: getEnv("CONTENT_KEYWORDS_FILE", ""),
+		},
 	}
 }
 
@@ -104,6 +219,24 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
 func getEnvRequired(key string) string {
 	value := os.Getenv(key)
 	if value == "" {
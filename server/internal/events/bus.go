@@ -0,0 +1,61 @@
+package events
+
+import (
+	"context"
+	"sync"
+
+	"mahjong-server/internal/logger"
+)
+
+// EventPublisher 业务层发布事件的接口
+type EventPublisher interface {
+	Publish(ctx context.Context, event Event)
+}
+
+// Handler 事件订阅者的处理函数，ctx为发布方的请求上下文（带请求ID），不应用于订阅者自己的异步处理
+type Handler func(ctx context.Context, event Event)
+
+type subscription struct {
+	name    string
+	handler Handler
+}
+
+// Bus 进程内事件总线，按订阅顺序同步调用各订阅者
+// 订阅者应尽快返回，耗时操作（如调用外部接口）需自行异步处理
+type Bus struct {
+	mu            sync.RWMutex
+	subscriptions []subscription
+}
+
+// NewBus 创建事件总线
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe 注册订阅者，name用于日志
+func (b *Bus) Subscribe(name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscriptions = append(b.subscriptions, subscription{name: name, handler: handler})
+}
+
+// Publish 将事件分发给所有订阅者，单个订阅者panic不影响其他订阅者
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	b.mu.RLock()
+	subscriptions := b.subscriptions
+	b.mu.RUnlock()
+
+	for _, sub := range subscriptions {
+		b.dispatch(ctx, sub, event)
+	}
+}
+
+func (b *Bus) dispatch(ctx context.Context, sub subscription, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.ErrorContext(ctx, "事件订阅者处理panic", "subscriber", sub.name, "event_type", event.EventType(),
+				"room_id", event.RoomID(), "error", r)
+		}
+	}()
+	sub.handler(ctx, event)
+}
@@ -0,0 +1,99 @@
+package events
+
+// 事件类型，与推送给WebSocket客户端的消息类型一致
+const (
+	TypeRoomCreated      = "room_created"
+	TypePlayerJoined     = "player_joined"
+	TypeScoreTransferred = "score_transfer"
+	TypeTransferVoided   = "transfer_voided"
+	TypeRoomSettled      = "room_settled"
+)
+
+// Event 业务事件，序列化后的JSON即为推送给房间客户端的data
+type Event interface {
+	EventType() string
+	RoomID() int64
+}
+
+// RoomCreated 房间已创建
+type RoomCreated struct {
+	RoomId    int64  `json:"room_id"`
+	RoomCode  string `json:"room_code"`
+	RoomName  string `json:"room_name"`
+	CreatorId int64  `json:"creator_id"`
+}
+
+func (e *RoomCreated) EventType() string { return TypeRoomCreated }
+func (e *RoomCreated) RoomID() int64     { return e.RoomId }
+
+// Player 事件中的玩家信息
+type Player struct {
+	UserId       int64  `json:"user_id"`
+	Nickname     string `json:"nickname"`
+	AvatarUrl    string `json:"avatar_url"`
+	CurrentScore int32  `json:"current_score"`
+	FinalScore   int32  `json:"final_score"`
+}
+
+// PlayerJoined 玩家加入房间
+type PlayerJoined struct {
+	RoomId int64  `json:"-"`
+	Player Player `json:"player"`
+}
+
+func (e *PlayerJoined) EventType() string { return TypePlayerJoined }
+func (e *PlayerJoined) RoomID() int64     { return e.RoomId }
+
+// Transfer 事件中的分数转移
+type Transfer struct {
+	Id           int64  `json:"id"`
+	FromUserId   int64  `json:"from_user_id"`
+	ToUserId     int64  `json:"to_user_id"`
+	FromUserName string `json:"from_user_name,omitempty"`
+	ToUserName   string `json:"to_user_name,omitempty"`
+	Amount       int32  `json:"amount"`
+}
+
+// ScoreTransferred 分数已转移
+type ScoreTransferred struct {
+	RoomId   int64    `json:"-"`
+	Transfer Transfer `json:"transfer"`
+}
+
+func (e *ScoreTransferred) EventType() string { return TypeScoreTransferred }
+func (e *ScoreTransferred) RoomID() int64     { return e.RoomId }
+
+// TransferVoided 分数转移已撤销
+type TransferVoided struct {
+	RoomId     int64    `json:"-"`
+	Transfer   Transfer `json:"transfer"`
+	OperatorId int64    `json:"operator_id"`
+}
+
+func (e *TransferVoided) EventType() string { return TypeTransferVoided }
+func (e *TransferVoided) RoomID() int64     { return e.RoomId }
+
+// Settlement 结算转账
+type Settlement struct {
+	FromUserId int64 `json:"from_user_id"`
+	ToUserId   int64 `json:"to_user_id"`
+	Amount     int32 `json:"amount"`
+}
+
+// SettledPlayer 结算时的玩家分数
+type SettledPlayer struct {
+	UserId   int64  `json:"user_id"`
+	Nickname string `json:"nickname"`
+	Score    int32  `json:"score"`
+}
+
+// RoomSettled 房间已结算
+type RoomSettled struct {
+	RoomId      int64           `json:"-"`
+	SettledBy   int64           `json:"settled_by"`
+	Settlements []Settlement    `json:"settlements"`
+	Players     []SettledPlayer `json:"players"`
+}
+
+func (e *RoomSettled) EventType() string { return TypeRoomSettled }
+func (e *RoomSettled) RoomID() int64     { return e.RoomId }
@@ -0,0 +1,79 @@
+package events
+
+import (
+	"context"
+	"sync/atomic"
+
+	"mahjong-server/internal/logger"
+)
+
+// Stats 按事件累计的业务计数
+type Stats struct {
+	roomsCreated    atomic.Int64
+	playersJoined   atomic.Int64
+	transfers       atomic.Int64
+	transfersVoided atomic.Int64
+	roomsSettled    atomic.Int64
+}
+
+// StatsSnapshot 业务计数快照
+type StatsSnapshot struct {
+	RoomsCreated    int64 `json:"rooms_created"`
+	PlayersJoined   int64 `json:"players_joined"`
+	Transfers       int64 `json:"transfers"`
+	TransfersVoided int64 `json:"transfers_voided"`
+	RoomsSettled    int64 `json:"rooms_settled"`
+}
+
+// NewStats 创建业务计数器
+func NewStats() *Stats {
+	return &Stats{}
+}
+
+// Handle 作为事件订阅者更新计数
+func (s *Stats) Handle(_ context.Context, event Event) {
+	switch event.(type) {
+	case *RoomCreated:
+		s.roomsCreated.Add(1)
+	case *PlayerJoined:
+		s.playersJoined.Add(1)
+	case *ScoreTransferred:
+		s.transfers.Add(1)
+	case *TransferVoided:
+		s.transfersVoided.Add(1)
+	case *RoomSettled:
+		s.roomsSettled.Add(1)
+	}
+}
+
+// Snapshot 返回当前计数
+func (s *Stats) Snapshot() StatsSnapshot {
+	return StatsSnapshot{
+		RoomsCreated:    s.roomsCreated.Load(),
+		PlayersJoined:   s.playersJoined.Load(),
+		Transfers:       s.transfers.Load(),
+		TransfersVoided: s.transfersVoided.Load(),
+		RoomsSettled:    s.roomsSettled.Load(),
+	}
+}
+
+// AuditLog 将事件写入业务日志
+func AuditLog(ctx context.Context, event Event) {
+	switch e := event.(type) {
+	case *RoomCreated:
+		logger.LogBusiness(ctx, e.EventType(), e.CreatorId, "room_id", e.RoomId, "room_code", e.RoomCode)
+	case *PlayerJoined:
+		logger.LogBusiness(ctx, e.EventType(), e.Player.UserId, "room_id", e.RoomId)
+	case *ScoreTransferred:
+		logger.LogBusiness(ctx, e.EventType(), e.Transfer.FromUserId, "room_id", e.RoomId,
+			"transfer_id", e.Transfer.Id, "to_user_id", e.Transfer.ToUserId, "amount", e.Transfer.Amount)
+	case *TransferVoided:
+		logger.LogBusiness(ctx, e.EventType(), e.OperatorId, "room_id", e.RoomId,
+			"transfer_id", e.Transfer.Id, "amount", e.Transfer.Amount)
+	case *RoomSettled:
+		logger.LogBusiness(ctx, e.EventType(), e.SettledBy, "room_id", e.RoomId,
+			"settlements", len(e.Settlements), "players", len(e.Players))
+	default:
+		logger.LogBusiness(ctx, event.EventType(), 0, "room_id", event.RoomID())
+	}
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"mahjong-server/internal/logger"
+)
+
+// Broadcaster 在服务实例之间分发房间消息，每个实例的Hub都会收到所有已发布的消息
+type Broadcaster interface {
+	// Publish 发布一条房间消息
+	Publish(message *WebSocketMessage) error
+	// Subscribe 注册接收回调，每个Broadcaster只应订阅一次
+	// 订阅断开重连后调用resubscribed，断开期间的消息已经丢失
+	Subscribe(deliver func(message *WebSocketMessage), resubscribed func()) error
+	// CurrentSeq 返回房间已分配的最大序号，由Hub自行分配序号时返回0
+	CurrentSeq(roomID int64) (int64, error)
+	Close() error
+}
+
+// MemoryBroadcaster 单实例部署使用的进程内实现
+type MemoryBroadcaster struct {
+	deliver func(message *WebSocketMessage)
+}
+
+// NewMemoryBroadcaster 创建进程内Broadcaster
+func NewMemoryBroadcaster() *MemoryBroadcaster {
+	return &MemoryBroadcaster{}
+}
+
+func (b *MemoryBroadcaster) Publish(message *WebSocketMessage) error {
+	if b.deliver == nil {
+		return fmt.Errorf("broadcaster未订阅")
+	}
+	b.deliver(message)
+	return nil
+}
+
+func (b *MemoryBroadcaster) Subscribe(deliver func(message *WebSocketMessage), resubscribed func()) error {
+	b.deliver = deliver
+	return nil
+}
+
+func (b *MemoryBroadcaster) CurrentSeq(roomID int64) (int64, error) {
+	return 0, nil
+}
+
+func (b *MemoryBroadcaster) Close() error {
+	return nil
+}
+
+const (
+	// redisRoomSeqTTL 房间最后一条消息之后序号键的保留时间，与Hub历史保留时长一致
+	redisRoomSeqTTL = roomHistoryTTL
+	// redisResubscribeDelay 订阅断开后的重连间隔
+	redisResubscribeDelay = 2 * time.Second
+)
+
+// publishScript 分配房间序号、刷新过期时间并发布，三步在Redis中原子执行，
+// 因此频道上的消息顺序与序号顺序一致。发布内容为 序号:消息JSON，即时事件的序号为0
+var publishScript = redis.NewScript(`
+local seq = redis.call("INCR", KEYS[1])
+redis.call("PEXPIRE", KEYS[1], ARGV[1])
+redis.call("PUBLISH", ARGV[2], seq .. ":" .. ARGV[3])
+return seq
+`)
+
+// RedisBroadcaster 通过Redis pub/sub在多个实例之间分发消息
+// 房间序号由Redis INCR统一分配，保证不同实例上的客户端看到一致的seq
+type RedisBroadcaster struct {
+	client  *redis.Client
+	channel string
+	prefix  string
+	pubsub  *redis.PubSub
+	closed  chan struct{}
+}
+
+// NewRedisBroadcaster 创建基于Redis的Broadcaster
+func NewRedisBroadcaster(client *redis.Client, channel string) *RedisBroadcaster {
+	return &RedisBroadcaster{
+		client:  client,
+		channel: channel,
+		prefix:  channel + ":seq:",
+		closed:  make(chan struct{}),
+	}
+}
+
+func (b *RedisBroadcaster) seqKey(roomID int64) string {
+	return b.prefix + strconv.FormatInt(roomID, 10)
+}
+
+func (b *RedisBroadcaster) Publish(message *WebSocketMessage) error {
+	message.Seq = 0
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("消息序列化失败: %w", err)
+	}
+	// 即时事件不分配序号，序号位为0
+	if isEphemeral(message.Type) {
+		return b.client.Publish(context.Background(), b.channel, "0:"+string(payload)).Err()
+	}
+	// 每次发布都刷新过期时间，房间持续活跃时序号不会中途从1重新开始
+	seq, err := publishScript.Run(context.Background(), b.client, []string{b.seqKey(message.RoomID)},
+		redisRoomSeqTTL.Milliseconds(), b.channel, payload).Int64()
+	if err != nil {
+		return fmt.Errorf("发布房间消息失败: %w", err)
+	}
+	message.Seq = seq
+	return nil
+}
+
+func (b *RedisBroadcaster) CurrentSeq(roomID int64) (int64, error) {
+	seq, err := b.client.Get(context.Background(), b.seqKey(roomID)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return seq, err
+}
+
+// Subscribe 在后台协程中接收频道消息，连接断开后由go-redis重连并重新订阅
+func (b *RedisBroadcaster) Subscribe(deliver func(message *WebSocketMessage), resubscribed func()) error {
+	ctx := context.Background()
+	b.pubsub = b.client.Subscribe(ctx, b.channel)
+	// 首次订阅成功后才返回，之后发布的消息都不会错过
+	if _, err := b.pubsub.Receive(ctx); err != nil {
+		b.pubsub.Close()
+		return err
+	}
+	go func() {
+		for {
+			received, err := b.pubsub.Receive(ctx)
+			if err != nil {
+				select {
+				case <-b.closed:
+					return
+				default:
+				}
+				logger.Error("Redis订阅中断，稍后重连", "channel", b.channel, "error", err.Error())
+				time.Sleep(redisResubscribeDelay)
+				continue
+			}
+
+			switch received := received.(type) {
+			case *redis.Subscription:
+				// 首次订阅的确认已在上面读取，这里收到的都是重连后的重新订阅
+				if received.Kind == "subscribe" {
+					logger.Warn("Redis订阅已恢复，断开期间的消息需重新同步", "channel", b.channel)
+					resubscribed()
+				}
+			case *redis.Message:
+				message, err := parseRedisMessage(received.Payload)
+				if err != nil {
+					logger.Error("解析Redis广播消息失败", "error", err.Error())
+					continue
+				}
+				deliver(message)
+			}
+		}
+	}()
+	return nil
+}
+
+// parseRedisMessage 解析 序号:消息JSON 格式的发布内容
+func parseRedisMessage(payload string) (*WebSocketMessage, error) {
+	seqStr, body, ok := strings.Cut(payload, ":")
+	if !ok {
+		return nil, fmt.Errorf("缺少消息序号")
+	}
+	seq, err := strconv.ParseInt(seqStr, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("消息序号格式错误: %w", err)
+	}
+	var message WebSocketMessage
+	if err := json.Unmarshal([]byte(body), &message); err != nil {
+		return nil, err
+	}
+	message.Seq = seq
+	return &message, nil
+}
+
+func (b *RedisBroadcaster) Close() error {
+	close(b.closed)
+	if b.pubsub != nil {
+		b.pubsub.Close()
+	}
+	return b.client.Close()
+}
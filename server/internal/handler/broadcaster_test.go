@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestClient 不带网络连接的客户端，测试直接读取其发送队列
+func newTestClient(hub *Hub, roomID, userID int64) *Client {
+	return &Client{
+		roomID:  roomID,
+		userID:  userID,
+		send:    make(chan []byte, clientSendBuffer),
+		hub:     hub,
+		lastSeq: -1,
+	}
+}
+
+// nextMessage 从客户端发送队列中读取下一条指定类型的消息，跳过上下线等其他消息
+func nextMessage(t *testing.T, client *Client, eventType string) *WebSocketMessage {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case data, ok := <-client.send:
+			if !ok {
+				t.Fatalf("等待%s时客户端已被关闭", eventType)
+			}
+			var message WebSocketMessage
+			if err := json.Unmarshal(data, &message); err != nil {
+				t.Fatalf("解析消息失败: %v", err)
+			}
+			if message.Type == eventType {
+				return &message
+			}
+		case <-timeout:
+			t.Fatalf("未收到%s消息", eventType)
+		}
+	}
+}
+
+// newRedisFake 启动miniredis，测试结束时自动停止
+func newRedisFake(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+	return miniredis.RunT(t)
+}
+
+func newRedisBroadcaster(t *testing.T, server *miniredis.Miniredis) *RedisBroadcaster {
+	t.Helper()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	broadcaster := NewRedisBroadcaster(client, "mahjong:test")
+	t.Cleanup(func() { broadcaster.Close() })
+	return broadcaster
+}
+
+func TestMemoryBroadcasterRequiresSubscribe(t *testing.T) {
+	broadcaster := NewMemoryBroadcaster()
+	if err := broadcaster.Publish(&WebSocketMessage{RoomID: 1}); err == nil {
+		t.Fatal("未订阅时发布应返回错误")
+	}
+
+	var got *WebSocketMessage
+	broadcaster.Subscribe(func(message *WebSocketMessage) { got = message }, func() {})
+	if err := broadcaster.Publish(&WebSocketMessage{RoomID: 1, Type: EventScoreTransfer}); err != nil {
+		t.Fatalf("发布失败: %v", err)
+	}
+	if got == nil || got.Type != EventScoreTransfer {
+		t.Fatalf("未收到发布的消息: %+v", got)
+	}
+}
+
+func TestRedisBroadcasterRefreshesSeqTTL(t *testing.T) {
+	server := newRedisFake(t)
+	broadcaster := newRedisBroadcaster(t, server)
+	seqKey := broadcaster.prefix + "7"
+
+	// 房间连续活跃超过序号键的过期时间，序号也不能从1重新开始
+	for want := int64(1); want <= 4; want++ {
+		message := &WebSocketMessage{Type: EventScoreTransfer, RoomID: 7}
+		if err := broadcaster.Publish(message); err != nil {
+			t.Fatalf("发布失败: %v", err)
+		}
+		if message.Seq != want {
+			t.Fatalf("第%d条消息的seq = %d", want, message.Seq)
+		}
+		if ttl := server.TTL(seqKey); ttl < redisRoomSeqTTL-time.Minute {
+			t.Fatalf("发布后序号键的过期时间未刷新: %v", ttl)
+		}
+		server.FastForward(redisRoomSeqTTL - time.Hour)
+	}
+
+	// 房间长时间没有消息后序号键过期
+	server.FastForward(redisRoomSeqTTL)
+	message := &WebSocketMessage{Type: EventScoreTransfer, RoomID: 7}
+	if err := broadcaster.Publish(message); err != nil {
+		t.Fatalf("发布失败: %v", err)
+	}
+	if message.Seq != 1 {
+		t.Fatalf("序号键过期后seq = %d，期望1", message.Seq)
+	}
+}
+
+// newRedisHub 连接到server的Hub，等待订阅完成后返回
+func newRedisHub(t *testing.T, server *miniredis.Miniredis) *Hub {
+	t.Helper()
+	hub, err := NewHub(newRedisBroadcaster(t, server))
+	if err != nil {
+		t.Fatalf("创建Hub失败: %v", err)
+	}
+	return hub
+}
+
+func TestRedisBroadcasterPublishesSeqWithMessage(t *testing.T) {
+	server := newRedisFake(t)
+	hub := newRedisHub(t, server)
+	client := newTestClient(hub, 5, 1)
+	hub.Register(client)
+	nextMessage(t, client, EventConnected)
+
+	// 序号随消息一起原子发布，订阅方按发布内容中的序号投递
+	var last int64
+	for i := 0; i < 3; i++ {
+		hub.BroadcastToRoom(context.Background(), 5, EventScoreTransfer, nil)
+		got := nextMessage(t, client, EventScoreTransfer)
+		if got.Seq <= last {
+			t.Fatalf("第%d条消息的seq = %d，之前 %d", i+1, got.Seq, last)
+		}
+		last = got.Seq
+	}
+	if current, err := hub.broadcaster.CurrentSeq(5); err != nil || current != last {
+		t.Fatalf("CurrentSeq = %d, %v，已收到 %d", current, err, last)
+	}
+
+	// 即时事件不占用序号
+	hub.BroadcastToRoom(context.Background(), 5, EventChat, map[string]interface{}{"text": "hi"})
+	if chat := nextMessage(t, client, EventChat); chat.Seq != 0 {
+		t.Fatalf("聊天消息的seq = %d", chat.Seq)
+	}
+	if current, _ := hub.broadcaster.CurrentSeq(5); current != last {
+		t.Fatalf("发布聊天后CurrentSeq = %d，期望 %d", current, last)
+	}
+	if _, err := parseRedisMessage("{}"); err == nil {
+		t.Fatal("不带序号的发布内容应解析失败")
+	}
+}
+
+func TestRedisBroadcasterNewInstanceLoadsSeq(t *testing.T) {
+	server := newRedisFake(t)
+	hubA := newRedisHub(t, server)
+	for i := 0; i < 3; i++ {
+		hubA.BroadcastToRoom(context.Background(), 8, EventScoreTransfer, nil)
+	}
+
+	// 新实例没有收到过该房间的消息，连接时也应报告全局序号而不是0
+	hubB := newRedisHub(t, server)
+	fresh := newTestClient(hubB, 8, 1)
+	hubB.Register(fresh)
+	if got := nextMessage(t, fresh, EventConnected); got.Seq < 3 {
+		t.Fatalf("新实例报告的seq = %d，期望至少3", got.Seq)
+	}
+
+	// 新实例没有之前的历史，带旧序号重连只能全量同步
+	resumed := newTestClient(hubB, 8, 2)
+	resumed.lastSeq = 1
+	hubB.Register(resumed)
+	if got := nextMessage(t, resumed, EventResyncRequired); got.Seq < 3 {
+		t.Fatalf("resync_required的seq = %d，期望至少3", got.Seq)
+	}
+}
+
+func TestHubResyncsOnSeqGap(t *testing.T) {
+	hub := newTestHub(t)
+	client := newTestClient(hub, 6, 1)
+	hub.Register(client)
+	defer hub.writers.Done() // 测试客户端没有writePump
+	nextMessage(t, client, EventConnected)
+
+	hub.deliver(&WebSocketMessage{Type: EventScoreTransfer, RoomID: 6, Seq: 1})
+	nextMessage(t, client, EventScoreTransfer)
+
+	// 漏收了序号2，历史中缺少该消息，客户端需全量同步
+	hub.deliver(&WebSocketMessage{Type: EventScoreTransfer, RoomID: 6, Seq: 3})
+	if got := nextMessage(t, client, EventResyncRequired); got.Seq != 3 {
+		t.Fatalf("resync_required的seq = %d，期望3", got.Seq)
+	}
+	resumed := newTestClient(hub, 6, 2)
+	resumed.lastSeq = 1
+	hub.Register(resumed)
+	defer hub.writers.Done()
+	nextMessage(t, resumed, EventResyncRequired)
+}
+
+func TestRedisBroadcasterResyncsAfterReconnect(t *testing.T) {
+	server := newRedisFake(t)
+	hub := newRedisHub(t, server)
+	client := newTestClient(hub, 9, 1)
+	hub.Register(client)
+	hub.BroadcastToRoom(context.Background(), 9, EventScoreTransfer, nil)
+	nextMessage(t, client, EventScoreTransfer)
+
+	// 订阅断开期间其他实例发布的消息本实例收不到
+	server.Close()
+	if _, err := server.Incr(hub.broadcaster.(*RedisBroadcaster).seqKey(9), 2); err != nil {
+		t.Fatalf("修改序号失败: %v", err)
+	}
+	if err := server.Restart(); err != nil {
+		t.Fatalf("重启miniredis失败: %v", err)
+	}
+
+	got := nextMessage(t, client, EventResyncRequired)
+	if current, _ := hub.broadcaster.CurrentSeq(9); got.Seq != current {
+		t.Fatalf("重连后resync_required的seq = %d，期望 %d", got.Seq, current)
+	}
+}
+
+func TestRedisBroadcasterFansOutAcrossHubs(t *testing.T) {
+	server := newRedisFake(t)
+
+	// 两个Hub模拟两个服务实例，各自连接同一个Redis
+	hubA, err := NewHub(newRedisBroadcaster(t, server))
+	if err != nil {
+		t.Fatalf("创建Hub失败: %v", err)
+	}
+	hubB, err := NewHub(newRedisBroadcaster(t, server))
+	if err != nil {
+		t.Fatalf("创建Hub失败: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for server.PubSubNumSub("mahjong:test")["mahjong:test"] < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("Broadcaster未完成订阅")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	const roomID = 42
+	clientA := newTestClient(hubA, roomID, 1)
+	clientB := newTestClient(hubB, roomID, 2)
+	hubA.Register(clientA)
+	hubB.Register(clientB)
+
+	ctx := context.Background()
+	hubA.BroadcastToRoom(ctx, roomID, EventScoreTransfer, map[string]interface{}{"amount": 10})
+	fromA := nextMessage(t, clientA, EventScoreTransfer)
+	onB := nextMessage(t, clientB, EventScoreTransfer)
+	if fromA.Seq != onB.Seq {
+		t.Fatalf("两个实例上的seq不一致: %d, %d", fromA.Seq, onB.Seq)
+	}
+
+	hubB.BroadcastToRoom(ctx, roomID, EventTransferVoided, nil)
+	voidedA := nextMessage(t, clientA, EventTransferVoided)
+	voidedB := nextMessage(t, clientB, EventTransferVoided)
+	if voidedA.Seq != voidedB.Seq || voidedA.Seq <= fromA.Seq {
+		t.Fatalf("后发布的消息seq应更大且一致: %d, %d, 之前 %d", voidedA.Seq, voidedB.Seq, fromA.Seq)
+	}
+
+	// 另一实例上的新连接可用已收到的序号补发错过的消息
+	resumed := newTestClient(hubB, roomID, 3)
+	resumed.lastSeq = fromA.Seq
+	hubB.Register(resumed)
+	replayed := nextMessage(t, resumed, EventTransferVoided)
+	if replayed.Seq != voidedB.Seq {
+		t.Fatalf("补发的seq = %d，期望 %d", replayed.Seq, voidedB.Seq)
+	}
+}
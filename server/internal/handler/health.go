@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+const (
+	// databasePingTimeout 就绪检查中数据库ping的超时
+	databasePingTimeout = 2 * time.Second
+	// hubHeartbeatTimeout Hub心跳超过该时长未更新视为Run循环已卡住
+	hubHeartbeatTimeout = 3 * hubHeartbeatInterval
+)
+
+// 检查结果状态
+const (
+	checkOK       = "ok"
+	checkDegraded = "degraded" // 部分功能不可用，仍可接收流量
+	checkDown     = "down"
+)
+
+// BuildInfo 构建信息，由main包通过 -ldflags "-X" 注入
+type BuildInfo struct {
+	Version   string
+	Commit    string
+	BuildTime string
+	StartTime time.Time
+}
+
+// SetBuildInfo 设置健康检查中报告的版本信息和启动时间
+func (h *HTTPHandler) SetBuildInfo(info BuildInfo) {
+	h.buildInfo = info
+}
+
+// checkResult 单项依赖的检查结果
+type checkResult struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// ServeLiveness 存活检查（/healthz）：进程能响应且Hub的Run循环未卡住，不检查外部依赖
+// 失败时应重启进程；数据库等依赖故障由就绪检查反映，避免依赖抖动导致反复重启
+func (h *HTTPHandler) ServeLiveness(w http.ResponseWriter, r *http.Request) {
+	startTime := time.Now()
+	recorder := NewResponseRecorder(w)
+
+	hub := h.checkHub(false)
+	statusCode, message := http.StatusOK, "服务运行正常"
+	if hub.Status != checkOK {
+		statusCode, message = http.StatusServiceUnavailable, "服务不可用"
+	}
+	h.writeHealth(recorder, statusCode, message, hub.Status, map[string]checkResult{"hub": hub})
+	observeRequest("healthz", r.Method, recorder.statusCode, startTime)
+}
+
+// ServeReadiness 就绪检查（/readyz）：数据库可用、Hub正常运行且未在关闭中时返回200
+// 微信access_token获取失败只影响小程序码等功能，报告为degraded但仍返回200
+func (h *HTTPHandler) ServeReadiness(w http.ResponseWriter, r *http.Request) {
+	startTime := time.Now()
+	recorder := NewResponseRecorder(w)
+
+	statusCode, message, status, checks := h.readiness(r.Context())
+	h.writeHealth(recorder, statusCode, message, status, checks)
+	observeRequest("readyz", r.Method, recorder.statusCode, startTime)
+}
+
+// ServePublicHealth 对外的旧健康检查接口（/health、/api/v1/health），状态码与/readyz一致，
+// 但只返回整体状态，各项依赖的错误信息和版本信息只在Nginx禁止外部访问的/readyz中提供
+func (h *HTTPHandler) ServePublicHealth(w http.ResponseWriter, r *http.Request) {
+	startTime := time.Now()
+	recorder := NewResponseRecorder(w)
+
+	statusCode, message, status, _ := h.readiness(r.Context())
+	recorder.Header().Set("Content-Type", "application/json")
+	recorder.Header().Set("Cache-Control", "no-store")
+	recorder.WriteHeader(statusCode)
+	json.NewEncoder(recorder).Encode(map[string]interface{}{
+		"code":    statusCode,
+		"message": message,
+		"data":    map[string]interface{}{"status": status},
+	})
+	observeRequest("health", r.Method, recorder.statusCode, startTime)
+}
+
+// readiness 执行就绪检查的各项依赖检查并汇总
+func (h *HTTPHandler) readiness(ctx context.Context) (statusCode int, message, status string, checks map[string]checkResult) {
+	checks = map[string]checkResult{
+		"database": h.checkDatabase(ctx),
+		"hub":      h.checkHub(true),
+		"wechat":   h.checkWeChat(),
+	}
+	status = checkOK
+	for _, check := range checks {
+		if check.Status == checkDown {
+			status = checkDown
+			break
+		}
+		if check.Status == checkDegraded {
+			status = checkDegraded
+		}
+	}
+
+	statusCode, message = http.StatusOK, "服务运行正常"
+	switch status {
+	case checkDegraded:
+		message = "服务部分功能不可用"
+	case checkDown:
+		statusCode, message = http.StatusServiceUnavailable, "服务不可用"
+	}
+	return statusCode, message, status, checks
+}
+
+func (h *HTTPHandler) writeHealth(w *ResponseRecorder, statusCode int, message, status string, checks map[string]checkResult) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code":    statusCode,
+		"message": message,
+		"data": map[string]interface{}{
+			"service":        "麻将记分小程序后端服务",
+			"status":         status,
+			"version":        h.buildInfo.Version,
+			"commit":         h.buildInfo.Commit,
+			"build_time":     h.buildInfo.BuildTime,
+			"uptime_seconds": int64(time.Since(h.buildInfo.StartTime).Seconds()),
+			"timestamp":      time.Now().Format(time.RFC3339),
+			"checks":         checks,
+		},
+	})
+}
+
+// checkDatabase ping数据库
+func (h *HTTPHandler) checkDatabase(ctx context.Context) checkResult {
+	ctx, cancel := context.WithTimeout(ctx, databasePingTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := h.db.PingContext(ctx)
+	result := checkResult{Status: checkOK, LatencyMs: float64(time.Since(start).Microseconds()) / 1000}
+	if err != nil {
+		result.Status = checkDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// checkHub 检查Hub的Run循环心跳，forReadiness为true时关闭中也视为不可用
+func (h *HTTPHandler) checkHub(forReadiness bool) checkResult {
+	if forReadiness && h.hub.closing.Load() {
+		return checkResult{Status: checkDown, Error: "服务正在关闭"}
+	}
+	lastHeartbeat := h.hub.LastHeartbeat()
+	if lastHeartbeat.IsZero() {
+		return checkResult{Status: checkDown, Error: "Hub未启动"}
+	}
+	if time.Since(lastHeartbeat) > hubHeartbeatTimeout {
+		return checkResult{Status: checkDown, Error: "Hub心跳超时，最近一次心跳于 " + lastHeartbeat.Format(time.RFC3339)}
+	}
+	return checkResult{Status: checkOK}
+}
+
+// checkWeChat 根据最近一次获取access_token的结果判断微信接口是否可用，不主动请求微信
+// （access_token接口每日调用次数有限）
+func (h *HTTPHandler) checkWeChat() checkResult {
+	status := h.wechat.TokenStatus()
+	switch {
+	case !status.Configured:
+		return checkResult{Status: checkDegraded, Error: "未配置微信AppID或AppSecret"}
+	case !status.Healthy():
+		return checkResult{Status: checkDegraded, Error: "获取access_token失败: " + status.LastError}
+	}
+	return checkResult{Status: checkOK}
+}
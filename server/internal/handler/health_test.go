@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"mahjong-server/internal/service"
+)
+
+// newHealthHandler 数据库ping失败、Hub正常运行的处理器
+func newHealthHandler(t *testing.T) *HTTPHandler {
+	t.Helper()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("创建sqlmock失败: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	for i := 0; i < 2; i++ {
+		mock.ExpectPing().WillReturnError(errors.New("dial tcp 10.0.0.12:3306: connection refused"))
+	}
+
+	hub := newTestHub(t)
+	deadline := time.Now().Add(2 * time.Second)
+	for hub.LastHeartbeat().IsZero() {
+		if time.Now().After(deadline) {
+			t.Fatal("Hub未启动")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return &HTTPHandler{
+		hub:       hub,
+		db:        db,
+		wechat:    service.NewWeChatService("", ""),
+		buildInfo: BuildInfo{Version: "1.2.3", Commit: "abc123", StartTime: time.Now()},
+	}
+}
+
+func TestPublicHealthHidesDetails(t *testing.T) {
+	h := newHealthHandler(t)
+
+	public := httptest.NewRecorder()
+	h.ServeHTTP(public, httptest.NewRequest("GET", "/api/v1/health", nil))
+	if public.Code != http.StatusServiceUnavailable {
+		t.Fatalf("数据库不可用时/api/v1/health应返回503，实际 %d", public.Code)
+	}
+	var body struct {
+		Code int                    `json:"code"`
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(public.Body.Bytes(), &body); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	if len(body.Data) != 1 || body.Data["status"] != checkDown {
+		t.Fatalf("对外接口只应返回整体状态: %v", body.Data)
+	}
+	for _, secret := range []string{"10.0.0.12", "abc123", "1.2.3", "AppID"} {
+		if strings.Contains(public.Body.String(), secret) {
+			t.Errorf("对外接口泄露了 %q: %s", secret, public.Body.String())
+		}
+	}
+
+	// 内网的/readyz保留各项检查的详情
+	ready := httptest.NewRecorder()
+	h.ServeReadiness(ready, httptest.NewRequest("GET", "/readyz", nil))
+	if ready.Code != http.StatusServiceUnavailable {
+		t.Fatalf("/readyz返回 %d", ready.Code)
+	}
+	for _, detail := range []string{"10.0.0.12", "abc123", "AppID"} {
+		if !strings.Contains(ready.Body.String(), detail) {
+			t.Errorf("/readyz应包含 %q: %s", detail, ready.Body.String())
+		}
+	}
+}
@@ -2,8 +2,13 @@ package handler
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,14 +16,34 @@ import (
 	"strings"
 	"time"
 
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"mahjong-server/internal/events"
 	"mahjong-server/internal/logger"
+	"mahjong-server/internal/metrics"
 	"mahjong-server/internal/service"
+	"mahjong-server/internal/storage"
+)
+
+var (
+	httpRequestsTotal = metrics.NewCounterVec("mahjong_http_requests_total",
+		"HTTP请求数", "route", "method", "code")
+	httpRequestDuration = metrics.NewHistogramVec("mahjong_http_request_duration_seconds",
+		"HTTP请求处理耗时（秒）", nil, "route")
 )
 
 type HTTPHandler struct {
 	service *service.MahjongService
 	wsHandler *WebSocketHandler
 	hub *Hub
+	db *sql.DB
+	wechat *service.WeChatService
+	adminToken string
+	requestTimeout time.Duration
+	routeTimeouts map[string]time.Duration // 按路由覆盖requestTimeout，键为去掉/api/v1/前缀的路径
+	buildInfo BuildInfo
+	qrcodes *service.RoomQRCodes
+	storage storage.Storage
 }
 
 // ResponseRecorder 用于记录HTTP响应
@@ -46,29 +71,114 @@ func (r *ResponseRecorder) Write(b []byte) (int, error) {
 	return r.ResponseWriter.Write(b)
 }
 
-func NewHTTPHandler(db *sql.DB, wechatService *service.WeChatService) *HTTPHandler {
-	hub := NewHub()
-	wsHandler := NewWebSocketHandler(hub)
-	
+// Unwrap 供http.ResponseController访问底层连接
+func (r *ResponseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func NewHTTPHandler(db *sql.DB, wechatService *service.WeChatService, hub *Hub, publisher events.EventPublisher, adminToken string, requestTimeout time.Duration) *HTTPHandler {
 	// 启动Hub的消息处理循环
 	go hub.Run()
 	
-	// 创建麻将服务并设置Hub
-	mahjongService := service.NewMahjongService(db, wechatService)
-	mahjongService.SetHub(hub)
+	// 创建麻将服务，在线状态由Hub提供
+	mahjongService := service.NewMahjongService(db, wechatService, publisher)
+	mahjongService.SetPresenceProvider(hub)
+
+	wsHandler := NewWebSocketHandler(hub, mahjongService, requestTimeout)
 	
 	return &HTTPHandler{
 		service: mahjongService,
 		wsHandler: wsHandler,
 		hub: hub,
+		db: db,
+		wechat: wechatService,
+		adminToken: adminToken,
+		requestTimeout: requestTimeout,
+	}
+}
+
+// SetRoomQRCodes 设置房间小程序码的生成和缓存，需在处理请求前调用
+func (h *HTTPHandler) SetRoomQRCodes(qrcodes *service.RoomQRCodes) {
+	h.qrcodes = qrcodes
+	h.service.SetRoomQRCodes(qrcodes)
+}
+
+// SetNotifier 设置订阅消息服务，需在处理请求前调用
+func (h *HTTPHandler) SetNotifier(notifier *service.Notifier) {
+	h.service.SetNotifier(notifier)
+}
+
+// SetContentChecker 设置昵称、房间名的内容安全检测，需在处理请求前调用
+func (h *HTTPHandler) SetContentChecker(checker *service.ContentChecker) {
+	h.service.SetContentChecker(checker)
+}
+
+// SetSessionKeyCipher 设置session_key的加密方式，需在处理请求前调用
+func (h *HTTPHandler) SetSessionKeyCipher(sessionKeys *service.SessionKeyCipher) {
+	h.service.SetSessionKeyCipher(sessionKeys)
+}
+
+// SetStorage 设置头像等文件的存储，需在处理请求前调用
+func (h *HTTPHandler) SetStorage(store storage.Storage) {
+	h.storage = store
+	h.service.SetStorage(store)
+}
+
+// SetRouteTimeout 为耗时较长的路由单独设置处理超时，path为去掉/api/v1/前缀的路径，需在处理请求前调用
+func (h *HTTPHandler) SetRouteTimeout(path string, timeout time.Duration) {
+	if h.routeTimeouts == nil {
+		h.routeTimeouts = make(map[string]time.Duration)
+	}
+	h.routeTimeouts[path] = timeout
+}
+
+// routeTimeout 返回路由的处理超时；单独设置过的路由同时放宽连接的读写期限，
+// 否则http.Server的ReadTimeout/WriteTimeout会先于ctx中断请求
+func (h *HTTPHandler) routeTimeout(w http.ResponseWriter, path string) time.Duration {
+	timeout, ok := h.routeTimeouts[path]
+	if !ok {
+		return h.requestTimeout
+	}
+	var deadline time.Time
+	if timeout > 0 {
+		// 留出超时后写回504响应的时间
+		deadline = time.Now().Add(timeout + routeDeadlineGrace)
+	}
+	controller := http.NewResponseController(w)
+	if err := controller.SetReadDeadline(deadline); err != nil {
+		logger.Warn("设置请求读取期限失败", "path", path, "error", err.Error())
 	}
+	if err := controller.SetWriteDeadline(deadline); err != nil {
+		logger.Warn("设置响应写入期限失败", "path", path, "error", err.Error())
+	}
+	return timeout
+}
+
+// routeDeadlineGrace 单独设置超时的路由，连接读写期限比处理超时多出的时间
+const routeDeadlineGrace = 5 * time.Second
+
+// withRequestTimeout 为请求设置统一的处理超时，timeout不大于0时不限制
+// 超时或客户端断开后ctx被取消，进行中的查询和事务随之中止
+func withRequestTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// Shutdown 在HTTP服务器停止后调用：通知并关闭所有WebSocket连接，再等待进行中的写事务完成
+func (h *HTTPHandler) Shutdown(ctx context.Context, retryAfter time.Duration) error {
+	if err := h.hub.Shutdown(ctx, retryAfter); err != nil {
+		logger.Warn("WebSocket连接未全部关闭", "error", err.Error())
+	}
+	return h.service.Drain(ctx)
 }
 
 func (h *HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// 添加panic恢复
 	defer func() {
 		if panicErr := recover(); panicErr != nil {
-			logger.Error("HTTP请求处理panic", "error", panicErr, "path", r.URL.Path)
+			logger.ErrorContext(r.Context(), "HTTP请求处理panic", "error", panicErr, "path", r.URL.Path)
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		}
 	}()
@@ -76,20 +186,18 @@ func (h *HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// 记录请求开始时间
 	startTime := time.Now()
 	
-	logger.Info("HTTP请求", "method", r.Method, "path", r.URL.Path, "query", r.URL.RawQuery)
+	logger.InfoContext(r.Context(), "HTTP请求", "method", r.Method, "path", r.URL.Path, "query", logger.RedactQuery(r.URL.RawQuery))
 
-	// 健康检查接口
+	// 旧的健康检查接口对外开放，只返回整体状态
 	if r.URL.Path == "/health" || r.URL.Path == "/api/v1/health" {
-		recorder := NewResponseRecorder(w)
-		h.handleHealth(recorder, r)
-		h.logRequest(r, recorder, startTime)
+		h.ServePublicHealth(w, r)
 		return
 	}
 
 	// WebSocket连接处理
 	if r.URL.Path == "/ws" {
-		logger.Info("匹配到WebSocket路由", "path", r.URL.Path, "method", r.Method)
-		logger.Info("ResponseWriter类型", "type", fmt.Sprintf("%T", w))
+		logger.InfoContext(r.Context(), "匹配到WebSocket路由", "path", r.URL.Path, "method", r.Method)
+		logger.InfoContext(r.Context(), "ResponseWriter类型", "type", fmt.Sprintf("%T", w))
 		// WebSocket升级需要直接使用原始的ResponseWriter，不能使用包装器
 		h.wsHandler.HandleWebSocket(w, r)
 		return
@@ -97,14 +205,25 @@ func (h *HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	
 	// 创建响应记录器（用于其他HTTP请求）
 	recorder := NewResponseRecorder(w)
+
+	// 请求的span由外层的otelhttp创建，上游带traceparent时接入其追踪
+	span := trace.SpanFromContext(r.Context())
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/")
+	ctx, cancel := withRequestTimeout(r.Context(), h.routeTimeout(w, path))
+	defer cancel()
+	r = r.WithContext(ctx)
+	if traceID := span.SpanContext().TraceID(); traceID.IsValid() {
+		recorder.Header().Set("X-Trace-Id", traceID.String())
+	}
 	
 	// 设置响应头
 	recorder.Header().Set("Content-Type", "application/json")
 	
 	// 路由处理
-	path := strings.TrimPrefix(r.URL.Path, "/api/v1/")
-	logger.Debug("处理HTTP请求", "method", r.Method, "path", r.URL.Path, "processed_path", path)
+	logger.DebugContext(r.Context(), "处理HTTP请求", "method", r.Method, "path", r.URL.Path, "processed_path", path)
 	
+	// 指标按路由统计，未匹配的路径统一归为not_found，避免标签无限增长
+	route := path
 	switch {
 	case r.Method == "POST" && path == "autoLogin":
 		h.handleAutoLogin(recorder, r)
@@ -120,10 +239,16 @@ func (h *HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.handleGetRoom(recorder, r)
 	case r.Method == "GET" && path == "getRoomPlayers":
 		h.handleGetRoomPlayers(recorder, r)
+	case r.Method == "GET" && path == "getRoomPresence":
+		h.handleGetRoomPresence(recorder, r)
 	case r.Method == "GET" && path == "getRoomTransfers":
 		h.handleGetRoomTransfers(recorder, r)
+	case r.Method == "GET" && path == "getRoomScoreSeries":
+		h.handleGetRoomScoreSeries(recorder, r)
 	case r.Method == "POST" && path == "transferScore":
 		h.handleTransferScore(recorder, r)
+	case r.Method == "POST" && path == "voidTransfer":
+		h.handleVoidTransfer(recorder, r)
 	case r.Method == "POST" && path == "settleRoom":
 		h.handleSettleRoom(recorder, r)
 	case r.Method == "GET" && path == "getUserRooms":
@@ -132,18 +257,67 @@ func (h *HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.handleGetRoomDetail(recorder, r)
 	case r.Method == "GET" && path == "getRecentRoom":
 		h.handleGetRecentRoom(recorder, r)
-	case r.Method == "GET" && path == "health":
-		h.handleHealth(recorder, r)
 	case r.Method == "POST" && path == "validateSession":
 		h.handleValidateSession(recorder, r)
 	case r.Method == "POST" && path == "generateQRCode":
 		h.handleGenerateQRCode(recorder, r)
+	case r.Method == "GET" && path == "roomQRCode":
+		h.handleRoomQRCode(recorder, r)
+	case r.Method == "POST" && path == "uploadAvatar":
+		h.handleUploadAvatar(recorder, r)
+	case r.Method == "POST" && path == "getAvatarUploadURL":
+		h.handleGetAvatarUploadURL(recorder, r)
+	case r.Method == "POST" && path == "confirmAvatarUpload":
+		h.handleConfirmAvatarUpload(recorder, r)
+	case r.Method == "GET" && path == "getSubscribeTemplates":
+		h.handleGetSubscribeTemplates(recorder, r)
+	case r.Method == "POST" && path == "subscribeConsent":
+		h.handleSubscribeConsent(recorder, r)
+	case r.Method == "POST" && path == "remindPayment":
+		h.handleRemindPayment(recorder, r)
+	case r.Method == "POST" && path == "decryptPhoneNumber":
+		h.handleDecryptPhoneNumber(recorder, r)
+	case r.Method == "POST" && path == "decryptShareInfo":
+		h.handleDecryptShareInfo(recorder, r)
+	case r.Method == "GET" && path == "getUserGroups":
+		h.handleGetUserGroups(recorder, r)
+	case r.Method == "GET" && path == "getGroupRooms":
+		h.handleGetGroupRooms(recorder, r)
+	case r.Method == "GET" && path == "getGroupLeaderboard":
+		h.handleGetGroupLeaderboard(recorder, r)
+	case r.Method == "GET" && path == "getGroupSuggestedMembers":
+		h.handleGetGroupSuggestedMembers(recorder, r)
+	case r.Method == "GET" && strings.HasPrefix(path, "files/"):
+		route = "files"
+		h.handleFile(recorder, r)
+	case r.Method == "POST" && path == "admin/rebuildRoom":
+		h.withAdmin(h.handleRebuildRoom)(recorder, r)
+	case r.Method == "POST" && path == "admin/mergeUsers":
+		h.withAdmin(h.handleMergeUsers)(recorder, r)
+	case r.Method == "GET" && path == "admin/checkConsistency":
+		h.withAdmin(h.handleCheckConsistency)(recorder, r)
+	case r.Method == "GET" && path == "admin/logLevel":
+		h.withAdmin(h.handleGetLogLevel)(recorder, r)
+	case r.Method == "POST" && path == "admin/logLevel":
+		h.withAdmin(h.handleSetLogLevel)(recorder, r)
 	default:
+		route = "not_found"
 		http.NotFound(recorder, r)
 	}
 	
 	// 记录请求日志
 	h.logRequest(r, recorder, startTime)
+	observeRequest(route, r.Method, recorder.statusCode, startTime)
+
+	// 状态码和5xx错误状态由otelhttp记录，这里补充路由
+	span.SetName("HTTP " + r.Method + " " + route)
+	span.SetAttributes(semconv.HTTPRoute(route))
+}
+
+// observeRequest 记录请求数和耗时指标
+func observeRequest(route, method string, statusCode int, startTime time.Time) {
+	httpRequestsTotal.Inc(route, method, strconv.Itoa(statusCode))
+	httpRequestDuration.Observe(time.Since(startTime).Seconds(), route)
 }
 
 // logRequest 记录HTTP请求日志
@@ -164,34 +338,32 @@ func (h *HTTPHandler) logRequest(r *http.Request, recorder *ResponseRecorder, st
 		bodyBytes, _ := io.ReadAll(r.Body)
 		r.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
 		if len(bodyBytes) > 0 {
-			requestBody = string(bodyBytes)
+			requestBody = logger.RedactJSON(bodyBytes)
 		}
 	}
 	
 	// 获取响应体
-	responseBody := recorder.body.String()
+	responseBody := recorder.body.Bytes()
 	
 	// 构建日志字段
-	logFields := map[string]interface{}{
-		"method":        r.Method,
-		"path":          r.URL.Path,
-		"query":         r.URL.RawQuery,
-		"client_ip":     clientIP,
-		"user_agent":    r.Header.Get("User-Agent"),
-		"status_code":   recorder.statusCode,
-		"duration_ms":   duration.Milliseconds(),
-		"response_size": len(responseBody),
+	logFields := []interface{}{
+		"method", r.Method,
+		"path", r.URL.Path,
+		"query", logger.RedactQuery(r.URL.RawQuery),
+		"client_ip", clientIP,
+		"user_agent", r.Header.Get("User-Agent"),
+		"status_code", recorder.statusCode,
+		"duration_ms", duration.Milliseconds(),
+		"response_size", len(responseBody),
 	}
 	
-	// 对于非200状态码，记录详细信息
-	if recorder.statusCode != http.StatusOK {
-		logFields["request_body"] = requestBody
-		logFields["response_body"] = responseBody
-		
-		logger.Error("HTTP请求异常", logFields)
+	// 对于非200状态码，记录详细信息（304为缓存命中，不算异常）
+	if recorder.statusCode != http.StatusOK && recorder.statusCode != http.StatusNotModified {
+		logFields = append(logFields, "request_body", requestBody, "response_body", logger.RedactJSON(responseBody))
+		logger.ErrorContext(r.Context(), "HTTP请求异常", logFields...)
 	} else {
 		// 对于200状态码，只记录基本信息
-		logger.Info("HTTP请求", logFields)
+		logger.InfoContext(r.Context(), "HTTP请求", logFields...)
 	}
 }
 
@@ -202,24 +374,24 @@ func (h *HTTPHandler) handleAutoLogin(w *ResponseRecorder, r *http.Request) {
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		logger.Error("自动登录请求解析失败", "error", err.Error())
+		logger.ErrorContext(r.Context(), "自动登录请求解析失败", "error", err.Error())
 		h.writeError(w, 400, "Invalid request body")
 		return
 	}
 
-	logger.Info("处理自动登录请求", "code_length", len(req.Code))
+	logger.InfoContext(r.Context(), "处理自动登录请求", "code_length", len(req.Code))
 
 	response, err := h.service.AutoLogin(r.Context(), &service.AutoLoginRequest{
 		Code: req.Code,
 	})
 	
 	if err != nil {
-		logger.Error("自动登录服务调用失败", "error", err.Error())
+		logger.ErrorContext(r.Context(), "自动登录服务调用失败", "error", err.Error())
 		h.writeError(w, 500, "Internal server error")
 		return
 	}
 
-	logger.Info("自动登录成功", "response_code", response.Code)
+	logger.InfoContext(r.Context(), "自动登录成功", "response_code", response.Code)
 	h.writeResponse(w, response)
 }
 
@@ -277,6 +449,9 @@ func (h *HTTPHandler) handleCreateRoom(w *ResponseRecorder, r *http.Request) {
 	var req struct {
 		CreatorId int64  `json:"creator_id"`
 		RoomName  string `json:"room_name"`
+		GroupId   int64  `json:"group_id"`
+		SessionID string `json:"session_id"`
+		service.ShareGroup
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -285,8 +460,11 @@ func (h *HTTPHandler) handleCreateRoom(w *ResponseRecorder, r *http.Request) {
 	}
 
 	response, err := h.service.CreateRoom(r.Context(), &service.CreateRoomRequest{
-		CreatorId: req.CreatorId,
-		RoomName:  req.RoomName,
+		CreatorId:  req.CreatorId,
+		RoomName:   req.RoomName,
+		SessionID:  req.SessionID,
+		ShareGroup: req.ShareGroup,
+		GroupId:    req.GroupId,
 	})
 	
 	if err != nil {
@@ -300,8 +478,10 @@ func (h *HTTPHandler) handleCreateRoom(w *ResponseRecorder, r *http.Request) {
 // 加入房间
 func (h *HTTPHandler) handleJoinRoom(w *ResponseRecorder, r *http.Request) {
 	var req struct {
-		UserId int64 `json:"user_id"`
-		RoomId int64 `json:"room_id"`
+		UserId    int64  `json:"user_id"`
+		RoomId    int64  `json:"room_id"`
+		SessionID string `json:"session_id"`
+		service.ShareGroup
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -310,8 +490,10 @@ func (h *HTTPHandler) handleJoinRoom(w *ResponseRecorder, r *http.Request) {
 	}
 
 	response, err := h.service.JoinRoom(r.Context(), &service.JoinRoomRequest{
-		UserId: req.UserId,
-		RoomId: req.RoomId,
+		UserId:     req.UserId,
+		RoomId:     req.RoomId,
+		SessionID:  req.SessionID,
+		ShareGroup: req.ShareGroup,
 	})
 	
 	if err != nil {
@@ -371,6 +553,22 @@ func (h *HTTPHandler) handleGetRoomPlayers(w *ResponseRecorder, r *http.Request)
 	h.writeResponse(w, response)
 }
 
+// 获取房间在线用户快照
+func (h *HTTPHandler) handleGetRoomPresence(w *ResponseRecorder, r *http.Request) {
+	roomIdStr := r.URL.Query().Get("room_id")
+	roomId, err := strconv.ParseInt(roomIdStr, 10, 64)
+	if err != nil {
+		h.writeError(w, 400, "Invalid room_id")
+		return
+	}
+
+	presenceData, _ := json.Marshal(map[string]interface{}{
+		"room_id":      roomId,
+		"online_users": h.hub.PresenceSnapshot(roomId),
+	})
+	h.writeResponse(w, &service.Response{Code: 200, Message: "获取成功", Data: string(presenceData)})
+}
+
 // 获取房间转移记录
 func (h *HTTPHandler) handleGetRoomTransfers(w *ResponseRecorder, r *http.Request) {
 	roomIdStr := r.URL.Query().Get("room_id")
@@ -404,6 +602,73 @@ func (h *HTTPHandler) handleGetRoomTransfers(w *ResponseRecorder, r *http.Reques
 	h.writeResponse(w, response)
 }
 
+// 获取房间分数曲线
+func (h *HTTPHandler) handleGetRoomScoreSeries(w *ResponseRecorder, r *http.Request) {
+	roomIdStr := r.URL.Query().Get("room_id")
+	roomId, err := strconv.ParseInt(roomIdStr, 10, 64)
+	if err != nil {
+		h.writeError(w, 400, "Invalid room_id")
+		return
+	}
+
+	// 获取lastTransferId参数，用于增量更新
+	lastTransferIdStr := r.URL.Query().Get("last_transfer_id")
+	var lastTransferId int64 = 0
+	if lastTransferIdStr != "" {
+		lastTransferId, err = strconv.ParseInt(lastTransferIdStr, 10, 64)
+		if err != nil {
+			h.writeError(w, 400, "Invalid last_transfer_id")
+			return
+		}
+	}
+
+	// 获取bucket_minutes参数，按N分钟聚合
+	bucketMinutesStr := r.URL.Query().Get("bucket_minutes")
+	var bucketMinutes int64 = 0
+	if bucketMinutesStr != "" {
+		bucketMinutes, err = strconv.ParseInt(bucketMinutesStr, 10, 32)
+		if err != nil {
+			h.writeError(w, 400, "Invalid bucket_minutes")
+			return
+		}
+	}
+
+	// 获取by_round参数，按局聚合
+	byRound := false
+	if byRoundStr := r.URL.Query().Get("by_round"); byRoundStr != "" {
+		byRound, err = strconv.ParseBool(byRoundStr)
+		if err != nil {
+			h.writeError(w, 400, "Invalid by_round")
+			return
+		}
+	}
+
+	// 获取voided_count参数，上次响应中的撤销数
+	var voidedCount int64 = 0
+	if voidedCountStr := r.URL.Query().Get("voided_count"); voidedCountStr != "" {
+		voidedCount, err = strconv.ParseInt(voidedCountStr, 10, 32)
+		if err != nil {
+			h.writeError(w, 400, "Invalid voided_count")
+			return
+		}
+	}
+
+	response, err := h.service.GetRoomScoreSeries(r.Context(), &service.GetRoomScoreSeriesRequest{
+		RoomId:         roomId,
+		LastTransferId: lastTransferId,
+		BucketMinutes:  int32(bucketMinutes),
+		ByRound:        byRound,
+		VoidedCount:    int32(voidedCount),
+	})
+
+	if err != nil {
+		h.writeError(w, 500, "Internal server error")
+		return
+	}
+
+	h.writeResponse(w, response)
+}
+
 // 转移分数
 func (h *HTTPHandler) handleTransferScore(w *ResponseRecorder, r *http.Request) {
 	var req struct {
@@ -433,6 +698,33 @@ func (h *HTTPHandler) handleTransferScore(w *ResponseRecorder, r *http.Request)
 	h.writeResponse(w, response)
 }
 
+// 撤销分数转移
+func (h *HTTPHandler) handleVoidTransfer(w *ResponseRecorder, r *http.Request) {
+	var req struct {
+		RoomId     int64 `json:"room_id"`
+		TransferId int64 `json:"transfer_id"`
+		UserId     int64 `json:"user_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, 400, "Invalid request body")
+		return
+	}
+
+	response, err := h.service.VoidTransfer(r.Context(), &service.VoidTransferRequest{
+		RoomId:     req.RoomId,
+		TransferId: req.TransferId,
+		UserId:     req.UserId,
+	})
+
+	if err != nil {
+		h.writeError(w, 500, "Internal server error")
+		return
+	}
+
+	h.writeResponse(w, response)
+}
+
 // 结算房间
 func (h *HTTPHandler) handleSettleRoom(w *ResponseRecorder, r *http.Request) {
 	var req struct {
@@ -572,23 +864,6 @@ func (h *HTTPHandler) writeError(w *ResponseRecorder, code int, message string)
 	})
 }
 
-// 健康检查
-func (h *HTTPHandler) handleHealth(w *ResponseRecorder, r *http.Request) {
-	response := map[string]interface{}{
-		"code":    200,
-		"message": "服务运行正常",
-		"data": map[string]interface{}{
-			"service":    "麻将记分小程序后端服务",
-			"version":    "1.0.0",
-			"status":     "healthy",
-			"server_ip":  "124.156.196.117",
-			"timestamp":  "2025-09-05T01:25:00Z",
-		},
-	}
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
-}
-
 // 验证登录态
 func (h *HTTPHandler) handleValidateSession(w *ResponseRecorder, r *http.Request) {
 	var req service.ValidateSessionRequest
@@ -625,4 +900,418 @@ func (h *HTTPHandler) handleGenerateQRCode(w *ResponseRecorder, r *http.Request)
 	json.NewEncoder(w).Encode(response)
 }
 
+// roomQRCodeMaxAge 小程序码在房间结算前不会变化，允许客户端和CDN缓存
+const roomQRCodeMaxAge = 24 * time.Hour
+
+// 房间小程序码图片，可直接作为<image>的src
+func (h *HTTPHandler) handleRoomQRCode(w *ResponseRecorder, r *http.Request) {
+	roomIdStr := r.URL.Query().Get("room_id")
+	roomId, err := strconv.ParseInt(roomIdStr, 10, 64)
+	if err != nil {
+		h.writeError(w, 400, "Invalid room_id")
+		return
+	}
+
+	object, err := h.qrcodes.Get(r.Context(), roomId, r.URL.Query().Get("env_version"))
+	switch {
+	case errors.Is(err, service.ErrInvalidEnvVersion):
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, service.ErrRoomNotFound):
+		h.writeError(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, service.ErrRoomSettled):
+		h.writeError(w, http.StatusGone, err.Error())
+		return
+	case err != nil:
+		logger.ErrorContext(r.Context(), "获取小程序码失败", "room_id", roomId, "error", err.Error())
+		h.writeError(w, http.StatusInternalServerError, "生成二维码失败")
+		return
+	}
+
+	sum := sha256.Sum256(object.Data)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	w.Header().Set("Content-Type", object.ContentType)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(roomQRCodeMaxAge.Seconds())))
+	w.Header().Set("ETag", etag)
+	if !object.ModTime.IsZero() {
+		w.Header().Set("Last-Modified", object.ModTime.UTC().Format(http.TimeFormat))
+	}
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(object.Data)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(object.Data)
+}
+
+// avatarUploadOverhead multipart表单中文件以外部分的大小上限
+const avatarUploadOverhead = 64 << 10
+
+// 上传头像，multipart表单的file字段为图片，登录态取X-Session-Id请求头或session_id字段
+func (h *HTTPHandler) handleUploadAvatar(w *ResponseRecorder, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, service.MaxAvatarSize+avatarUploadOverhead)
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			h.writeError(w, http.StatusRequestEntityTooLarge, service.ErrAvatarTooLarge.Error())
+			return
+		}
+		h.writeError(w, http.StatusBadRequest, "Invalid file")
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, service.MaxAvatarSize+1))
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid file")
+		return
+	}
+
+	sessionID := r.Header.Get("X-Session-Id")
+	if sessionID == "" {
+		sessionID = r.FormValue("session_id")
+	}
+	response, err := h.service.UploadAvatar(r.Context(), sessionID, data)
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.writeResponse(w, response)
+}
+
+// 获取头像的预签名上传地址，仅COS存储支持
+func (h *HTTPHandler) handleGetAvatarUploadURL(w *ResponseRecorder, r *http.Request) {
+	var req service.AvatarUploadURLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.service.GetAvatarUploadURL(r.Context(), &req)
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.writeResponse(w, response)
+}
+
+// 确认通过预签名地址上传的头像
+func (h *HTTPHandler) handleConfirmAvatarUpload(w *ResponseRecorder, r *http.Request) {
+	var req service.ConfirmAvatarUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.service.ConfirmAvatarUpload(r.Context(), &req)
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.writeResponse(w, response)
+}
 
+// 获取订阅消息模板
+func (h *HTTPHandler) handleGetSubscribeTemplates(w *ResponseRecorder, r *http.Request) {
+	response, err := h.service.GetSubscribeTemplates(r.Context())
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.writeResponse(w, response)
+}
+
+// 记录订阅消息授权
+func (h *HTTPHandler) handleSubscribeConsent(w *ResponseRecorder, r *http.Request) {
+	var req service.SubscribeConsentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.service.SubscribeConsent(r.Context(), &req)
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.writeResponse(w, response)
+}
+
+// 提醒付款
+func (h *HTTPHandler) handleRemindPayment(w *ResponseRecorder, r *http.Request) {
+	var req service.RemindPaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.service.RemindPayment(r.Context(), &req)
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.writeResponse(w, response)
+}
+
+// 解密手机号
+func (h *HTTPHandler) handleDecryptPhoneNumber(w *ResponseRecorder, r *http.Request) {
+	var req service.DecryptDataRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.service.DecryptPhoneNumber(r.Context(), &req)
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.writeResponse(w, response)
+}
+
+// 解密群信息
+func (h *HTTPHandler) handleDecryptShareInfo(w *ResponseRecorder, r *http.Request) {
+	var req service.DecryptDataRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.service.DecryptShareInfo(r.Context(), &req)
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.writeResponse(w, response)
+}
+
+// querySessionID GET请求的登录态，优先取X-Session-Id请求头
+func querySessionID(r *http.Request) string {
+	if sessionID := r.Header.Get("X-Session-Id"); sessionID != "" {
+		return sessionID
+	}
+	return r.URL.Query().Get("session_id")
+}
+
+// 获取用户所在的群
+func (h *HTTPHandler) handleGetUserGroups(w *ResponseRecorder, r *http.Request) {
+	response, err := h.service.GetUserGroups(r.Context(), &service.GetUserGroupsRequest{
+		SessionID: querySessionID(r),
+	})
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.writeResponse(w, response)
+}
+
+// 获取群的房间记录
+func (h *HTTPHandler) handleGetGroupRooms(w *ResponseRecorder, r *http.Request) {
+	groupId, err := strconv.ParseInt(r.URL.Query().Get("group_id"), 10, 64)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid group_id")
+		return
+	}
+	page, _ := strconv.ParseInt(r.URL.Query().Get("page"), 10, 32)
+	pageSize, _ := strconv.ParseInt(r.URL.Query().Get("page_size"), 10, 32)
+
+	response, err := h.service.GetGroupRooms(r.Context(), &service.GetGroupRoomsRequest{
+		SessionID: querySessionID(r),
+		GroupId:   groupId,
+		Page:      int32(page),
+		PageSize:  int32(pageSize),
+	})
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.writeResponse(w, response)
+}
+
+// 获取群排行榜
+func (h *HTTPHandler) handleGetGroupLeaderboard(w *ResponseRecorder, r *http.Request) {
+	groupId, err := strconv.ParseInt(r.URL.Query().Get("group_id"), 10, 64)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid group_id")
+		return
+	}
+	days, _ := strconv.ParseInt(r.URL.Query().Get("days"), 10, 32)
+
+	response, err := h.service.GetGroupLeaderboard(r.Context(), &service.GetGroupLeaderboardRequest{
+		SessionID: querySessionID(r),
+		GroupId:   groupId,
+		Days:      int32(days),
+	})
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.writeResponse(w, response)
+}
+
+// 获取建房时推荐的群成员
+func (h *HTTPHandler) handleGetGroupSuggestedMembers(w *ResponseRecorder, r *http.Request) {
+	groupId, err := strconv.ParseInt(r.URL.Query().Get("group_id"), 10, 64)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid group_id")
+		return
+	}
+	limit, _ := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 32)
+
+	response, err := h.service.GetGroupSuggestedMembers(r.Context(), &service.GetGroupSuggestedMembersRequest{
+		SessionID: querySessionID(r),
+		GroupId:   groupId,
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.writeResponse(w, response)
+}
+
+// fileMaxAge 头像key包含内容哈希，内容不会变化
+const fileMaxAge = 365 * 24 * time.Hour
+
+// 公开文件，本地存储时头像地址指向这里；只开放avatars/，客户端上传的原图不对外提供
+func (h *HTTPHandler) handleFile(w *ResponseRecorder, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/api/v1/files/")
+	if !strings.HasPrefix(key, "avatars/") || strings.Contains(key, "..") {
+		http.NotFound(w, r)
+		return
+	}
+
+	object, err := h.storage.Get(r.Context(), key)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		logger.ErrorContext(r.Context(), "读取文件失败", "key", key, "error", err.Error())
+		h.writeError(w, http.StatusInternalServerError, "读取文件失败")
+		return
+	}
+
+	w.Header().Set("Content-Type", object.ContentType)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d, immutable", int(fileMaxAge.Seconds())))
+	w.Header().Set("Content-Length", strconv.Itoa(len(object.Data)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(object.Data)
+}
+
+// withAdmin 校验管理接口令牌，未配置令牌时管理接口不可用
+func (h *HTTPHandler) withAdmin(next func(w *ResponseRecorder, r *http.Request)) func(w *ResponseRecorder, r *http.Request) {
+	return func(w *ResponseRecorder, r *http.Request) {
+		if h.adminToken == "" {
+			http.NotFound(w, r)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Admin-Token")), []byte(h.adminToken)) != 1 {
+			h.writeError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// 由事件回放重建房间分数
+func (h *HTTPHandler) handleRebuildRoom(w *ResponseRecorder, r *http.Request) {
+	var req service.RebuildRoomRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.service.RebuildRoom(r.Context(), &req)
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.writeResponse(w, response)
+}
+
+// 合并同一个人的两个用户
+func (h *HTTPHandler) handleMergeUsers(w *ResponseRecorder, r *http.Request) {
+	var req service.MergeUsersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.service.MergeUsers(r.Context(), &req)
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.writeResponse(w, response)
+}
+
+// 检查房间分数与事件回放是否一致
+func (h *HTTPHandler) handleCheckConsistency(w *ResponseRecorder, r *http.Request) {
+	var roomId int64
+	if roomIdStr := r.URL.Query().Get("room_id"); roomIdStr != "" {
+		var err error
+		roomId, err = strconv.ParseInt(roomIdStr, 10, 64)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, "Invalid room_id")
+			return
+		}
+	}
+
+	response, err := h.service.CheckConsistency(r.Context(), &service.CheckConsistencyRequest{
+		RoomId: roomId,
+	})
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.writeResponse(w, response)
+}
+
+// 查询当前日志级别
+func (h *HTTPHandler) handleGetLogLevel(w *ResponseRecorder, r *http.Request) {
+	h.writeLogLevel(w, logger.GetLevel())
+}
+
+// 运行时修改日志级别，重启后恢复为LOG_LEVEL配置
+func (h *HTTPHandler) handleSetLogLevel(w *ResponseRecorder, r *http.Request) {
+	var req struct {
+		Level string `json:"level"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	level, err := logger.ParseLevel(req.Level)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid level, expected DEBUG, INFO, WARN, ERROR or FATAL")
+		return
+	}
+
+	previous := logger.GetLevel()
+	logger.SetLevel(level)
+	logger.WarnContext(r.Context(), "日志级别已修改", "from", previous.String(), "to", level.String())
+	h.writeLogLevel(w, level)
+}
+
+func (h *HTTPHandler) writeLogLevel(w *ResponseRecorder, level logger.LogLevel) {
+	data, _ := json.Marshal(map[string]string{"level": level.String()})
+	h.writeResponse(w, &service.Response{Code: 200, Message: "查询成功", Data: string(data)})
+}
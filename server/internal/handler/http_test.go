@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRouteTimeout(t *testing.T) {
+	h := &HTTPHandler{requestTimeout: 10 * time.Second}
+	h.SetRouteTimeout("admin/checkConsistency", 2*time.Minute)
+	h.SetRouteTimeout("uploadAvatar", 0)
+
+	tests := []struct {
+		path string
+		want time.Duration
+	}{
+		{"getRoom", 10 * time.Second},
+		{"admin/checkConsistency", 2 * time.Minute},
+		{"uploadAvatar", 0}, // 0表示不限制
+	}
+	for _, tt := range tests {
+		if got := h.routeTimeout(httptest.NewRecorder(), tt.path); got != tt.want {
+			t.Errorf("%s 的超时 = %v，期望 %v", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,433 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"mahjong-server/internal/logger"
+)
+
+func TestMain(m *testing.M) {
+	// 数千个模拟客户端的注册日志没有意义
+	logger.SetLevel(logger.ERROR)
+	os.Exit(m.Run())
+}
+
+func newTestHub(t *testing.T) *Hub {
+	t.Helper()
+	hub, err := NewHub(NewMemoryBroadcaster())
+	if err != nil {
+		t.Fatalf("创建Hub失败: %v", err)
+	}
+	go hub.Run()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		hub.Shutdown(ctx, 0)
+	})
+	return hub
+}
+
+// drainedClient 模拟writePump：持续读取发送队列直到关闭，记录收到的分数转移消息
+type drainedClient struct {
+	*Client
+	transfers  atomic.Int64
+	outOfOrder atomic.Int64
+	restarting atomic.Bool // 收到了retry_after为3秒的server_restarting
+	done       chan struct{}
+}
+
+func startDrainedClient(hub *Hub, roomID, userID int64) *drainedClient {
+	c := &drainedClient{Client: newTestClient(hub, roomID, userID), done: make(chan struct{})}
+	return c
+}
+
+// drain 在Register成功后启动，与writePump一样在退出时通知Hub
+func (c *drainedClient) drain() {
+	defer close(c.done)
+	defer c.hub.writers.Done()
+	var lastSeq int64
+	for data := range c.send {
+		var message WebSocketMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			continue
+		}
+		switch message.Type {
+		case EventScoreTransfer:
+			c.transfers.Add(1)
+			if message.Seq <= lastSeq {
+				c.outOfOrder.Add(1)
+			}
+			lastSeq = message.Seq
+		case EventServerRestarting:
+			data, _ := message.Data.(map[string]interface{})
+			c.restarting.Store(data["retry_after"] == float64(3))
+		}
+	}
+}
+
+// forEach 用固定数量的协程并发执行，控制-race下的协程数
+func forEach(n int, fn func(i int)) {
+	const workers = 32
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
+
+func TestHubThousandsOfClients(t *testing.T) {
+	const (
+		rooms          = 40
+		clientsPerRoom = 50
+		broadcasts     = 20
+	)
+	hub := newTestHub(t)
+
+	clients := make([]*drainedClient, rooms*clientsPerRoom)
+	forEach(len(clients), func(i int) {
+		c := startDrainedClient(hub, int64(i%rooms+1), int64(i+1))
+		if !hub.Register(c.Client) {
+			t.Errorf("客户端%d注册失败", i)
+			return
+		}
+		clients[i] = c
+		go c.drain()
+	})
+	if got := hub.Stats().Connections; got != int64(len(clients)) {
+		t.Fatalf("连接数 = %d，期望 %d", got, len(clients))
+	}
+	if got := hub.Stats().Rooms; got != rooms {
+		t.Fatalf("房间数 = %d，期望 %d", got, rooms)
+	}
+
+	// 各房间并发广播
+	ctx := context.Background()
+	forEach(rooms*broadcasts, func(i int) {
+		hub.BroadcastToRoom(ctx, int64(i%rooms+1), EventScoreTransfer, map[string]interface{}{"n": i})
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for _, c := range clients {
+		for c.transfers.Load() < broadcasts {
+			if time.Now().After(deadline) {
+				t.Fatalf("客户端%d只收到%d条消息", c.userID, c.transfers.Load())
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	for _, c := range clients {
+		if c.transfers.Load() != broadcasts || c.outOfOrder.Load() != 0 {
+			t.Fatalf("客户端%d收到%d条消息，乱序%d条", c.userID, c.transfers.Load(), c.outOfOrder.Load())
+		}
+	}
+	if stats := hub.Stats(); stats.MessagesDropped != 0 || stats.ClientsEvicted != 0 {
+		t.Fatalf("及时读取的客户端不应丢消息: %+v", stats)
+	}
+
+	// 一半客户端断开的同时继续广播，注销与投递并发
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		forEach(len(clients)/2, func(i int) {
+			hub.Unregister(clients[i*2].Client)
+		})
+	}()
+	go func() {
+		defer wg.Done()
+		forEach(rooms*broadcasts, func(i int) {
+			hub.BroadcastToRoom(ctx, int64(i%rooms+1), EventScoreTransfer, nil)
+		})
+	}()
+	wg.Wait()
+
+	for i := 0; i < len(clients); i += 2 {
+		select {
+		case <-clients[i].done:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("已注销客户端%d的发送队列未关闭", i)
+		}
+	}
+	if got := hub.Stats().Connections; got != int64(len(clients)/2) {
+		t.Fatalf("注销一半后连接数 = %d", got)
+	}
+	// 重复注销不影响计数
+	hub.Unregister(clients[0].Client)
+	if got := hub.Stats().Connections; got != int64(len(clients)/2) {
+		t.Fatalf("重复注销后连接数 = %d", got)
+	}
+}
+
+func TestHubEvictsSlowClient(t *testing.T) {
+	hub := newTestHub(t)
+	const roomID = 1
+
+	fast := startDrainedClient(hub, roomID, 1)
+	hub.Register(fast.Client)
+	go fast.drain()
+
+	// 不读取发送队列的慢客户端
+	slow := newTestClient(hub, roomID, 2)
+	hub.Register(slow)
+
+	// 广播写满慢客户端队列的同时，其连接也在断开，驱逐与注销不能重复处理
+	// 分批广播，每批等及时读取的客户端读完，保证它的队列不会写满
+	ctx := context.Background()
+	const batch = 64
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for sent := 0; sent < clientSendBuffer*2; sent += batch {
+			for i := 0; i < batch; i++ {
+				hub.BroadcastToRoom(ctx, roomID, EventScoreTransfer, nil)
+			}
+			for fast.transfers.Load() < int64(sent+batch) {
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		time.Sleep(time.Millisecond)
+		hub.Unregister(slow)
+	}()
+	wg.Wait()
+
+	slow.mu.Lock()
+	closed := slow.closed
+	slow.mu.Unlock()
+	if !closed {
+		t.Fatal("慢客户端的发送队列应已关闭")
+	}
+	hub.writers.Done() // 慢客户端没有writePump
+
+	stats := hub.Stats()
+	if stats.Connections != 1 {
+		t.Fatalf("只应剩下及时读取的客户端，连接数 = %d", stats.Connections)
+	}
+	if stats.ClientsEvicted > 1 {
+		t.Fatalf("同一客户端被驱逐了%d次", stats.ClientsEvicted)
+	}
+
+	// 及时读取的客户端不受影响，收到全部消息
+	deadline := time.Now().Add(2 * time.Second)
+	for fast.transfers.Load() < clientSendBuffer*2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("及时读取的客户端只收到%d条消息", fast.transfers.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHubEvictionCountsDrops(t *testing.T) {
+	hub := newTestHub(t)
+	slow := newTestClient(hub, 1, 1)
+	hub.Register(slow)
+	defer hub.writers.Done()
+
+	// 注册时收到connected，再加上clientSendBuffer条广播，必有一条写不进去
+	for i := 0; i < clientSendBuffer; i++ {
+		hub.BroadcastToRoom(context.Background(), 1, EventScoreTransfer, nil)
+	}
+	stats := hub.Stats()
+	if stats.ClientsEvicted != 1 || stats.MessagesDropped < 1 {
+		t.Fatalf("慢客户端应被驱逐并计入丢弃: %+v", stats)
+	}
+	if stats.Connections != 0 || stats.Rooms != 0 {
+		t.Fatalf("驱逐后连接数和房间数应为0: %+v", stats)
+	}
+}
+
+func TestHubShutdown(t *testing.T) {
+	hub, err := NewHub(NewMemoryBroadcaster())
+	if err != nil {
+		t.Fatalf("创建Hub失败: %v", err)
+	}
+	runDone := make(chan struct{})
+	go func() {
+		hub.Run()
+		close(runDone)
+	}()
+
+	clients := make([]*drainedClient, 1000)
+	forEach(len(clients), func(i int) {
+		c := startDrainedClient(hub, int64(i%10+1), int64(i+1))
+		hub.Register(c.Client)
+		clients[i] = c
+		go c.drain()
+	})
+
+	// 关闭与广播、注册并发
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() {
+		for i := 0; i < 100; i++ {
+			hub.BroadcastToRoom(context.Background(), int64(i%10+1), EventScoreTransfer, nil)
+		}
+	}()
+	if err := hub.Shutdown(ctx, 3*time.Second); err != nil {
+		t.Fatalf("Shutdown失败: %v", err)
+	}
+
+	for _, c := range clients {
+		<-c.done
+		if !c.restarting.Load() {
+			t.Fatalf("客户端%d未收到带retry_after的server_restarting", c.userID)
+		}
+		if c.closeCode != websocket.CloseServiceRestart {
+			t.Fatalf("客户端%d的关闭码 = %d", c.userID, c.closeCode)
+		}
+	}
+	select {
+	case <-runDone:
+	case <-time.After(time.Second):
+		t.Fatal("Shutdown后Run未退出")
+	}
+	if stats := hub.Stats(); stats.Connections != 0 || stats.Rooms != 0 {
+		t.Fatalf("关闭后仍有连接: %+v", stats)
+	}
+
+	// 关闭后拒绝新连接，重复关闭直接返回
+	if hub.Register(newTestClient(hub, 1, 9999)) {
+		t.Fatal("关闭后不应接受新连接")
+	}
+	if err := hub.Shutdown(ctx, 3*time.Second); err != nil {
+		t.Fatalf("重复Shutdown返回错误: %v", err)
+	}
+}
+
+func TestHubShutdownTimeout(t *testing.T) {
+	hub, err := NewHub(NewMemoryBroadcaster())
+	if err != nil {
+		t.Fatalf("创建Hub失败: %v", err)
+	}
+	go hub.Run()
+
+	// 没有writePump的客户端永远不会发完，Shutdown在ctx到期后返回错误
+	stuck := newTestClient(hub, 1, 1)
+	hub.Register(stuck)
+	defer hub.writers.Done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := hub.Shutdown(ctx, 0); err == nil {
+		t.Fatal("连接未关闭完时Shutdown应返回超时错误")
+	}
+}
+
+// queuedMessages 读出客户端发送队列中已有的消息，跳过上下线消息
+func queuedMessages(t *testing.T, client *Client) []*WebSocketMessage {
+	t.Helper()
+	var messages []*WebSocketMessage
+	for {
+		select {
+		case data := <-client.send:
+			var message WebSocketMessage
+			if err := json.Unmarshal(data, &message); err != nil {
+				t.Fatalf("解析消息失败: %v", err)
+			}
+			if message.Type != EventPlayerOnline && message.Type != EventPlayerOffline {
+				messages = append(messages, &message)
+			}
+		default:
+			return messages
+		}
+	}
+}
+
+func TestHubReplaysMissedMessages(t *testing.T) {
+	hub := newTestHub(t)
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		hub.BroadcastToRoom(ctx, 1, EventScoreTransfer, map[string]interface{}{"amount": i})
+		hub.BroadcastToRoom(ctx, 1, EventChat, map[string]interface{}{"text": "hi"})
+		hub.BroadcastToRoom(ctx, 1, EventPlayerReady, nil)
+	}
+
+	// last_seq仍在历史中，只补发之后的状态变更，不补发聊天和准备
+	resumed := newTestClient(hub, 1, 1)
+	resumed.lastSeq = 1
+	hub.Register(resumed)
+	defer hub.writers.Done() // 测试客户端没有writePump
+	replayed := queuedMessages(t, resumed)
+	if len(replayed) != 2 {
+		t.Fatalf("应补发2条消息，实际 %d 条", len(replayed))
+	}
+	for i, message := range replayed {
+		if message.Type != EventScoreTransfer || message.Seq != int64(i+2) {
+			t.Fatalf("第%d条补发的消息 = %s seq %d", i+1, message.Type, message.Seq)
+		}
+	}
+
+	// 即时事件照常发给在线的客户端，不带序号
+	hub.BroadcastToRoom(ctx, 1, EventChat, map[string]interface{}{"text": "hello"})
+	if chat := nextMessage(t, resumed, EventChat); chat.Seq != 0 {
+		t.Fatalf("聊天消息不应带序号，seq = %d", chat.Seq)
+	}
+}
+
+func TestHubEphemeralEventsKeepHistory(t *testing.T) {
+	hub := newTestHub(t)
+	ctx := context.Background()
+	hub.BroadcastToRoom(ctx, 1, EventScoreTransfer, nil)
+	// 大量聊天不会把分数变更挤出历史缓冲
+	for i := 0; i < roomHistorySize*2; i++ {
+		hub.BroadcastToRoom(ctx, 1, EventChat, map[string]interface{}{"text": "hi"})
+	}
+
+	resumed := newTestClient(hub, 1, 1)
+	resumed.lastSeq = 0
+	hub.Register(resumed)
+	defer hub.writers.Done()
+	replayed := queuedMessages(t, resumed)
+	if len(replayed) != 1 || replayed[0].Type != EventScoreTransfer || replayed[0].Seq != 1 {
+		t.Fatalf("应补发seq为1的分数变更，实际 %+v", replayed)
+	}
+}
+
+func TestHubResyncWhenLastSeqEvicted(t *testing.T) {
+	hub := newTestHub(t)
+	ctx := context.Background()
+	const total = roomHistorySize + 5
+	for i := 0; i < total; i++ {
+		hub.BroadcastToRoom(ctx, 1, EventScoreTransfer, nil)
+	}
+
+	// 序号2之后的消息已有部分被淘汰，只能全量同步
+	resumed := newTestClient(hub, 1, 1)
+	resumed.lastSeq = 2
+	hub.Register(resumed)
+	defer hub.writers.Done()
+	messages := queuedMessages(t, resumed)
+	if len(messages) != 1 || messages[0].Type != EventResyncRequired || messages[0].Seq != total {
+		t.Fatalf("应只收到seq为%d的resync_required，实际 %+v", total, messages)
+	}
+
+	// 最早仍在缓冲中的序号之前一条可以补发
+	edge := newTestClient(hub, 1, 2)
+	edge.lastSeq = total - roomHistorySize
+	hub.Register(edge)
+	defer hub.writers.Done()
+	if replayed := queuedMessages(t, edge); len(replayed) != roomHistorySize {
+		t.Fatalf("应补发%d条消息，实际 %d 条", roomHistorySize, len(replayed))
+	}
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"mahjong-server/internal/logger"
+)
+
+// offlineDebounce 用户最后一个连接断开后，等待该时长仍未重连才广播离线
+const offlineDebounce = 10 * time.Second
+
+// presenceKey 标识房间内的一个用户
+type presenceKey struct {
+	roomID int64
+	userID int64
+}
+
+// pendingOffline 等待确认的离线，定时器触发后交给Run协程处理
+type pendingOffline struct {
+	key   presenceKey
+	timer *time.Timer
+}
+
+// presenceTracker 记录每个房间用户的连接数与在线状态，同一用户可有多个设备同时连接
+type presenceTracker struct {
+	mu          sync.RWMutex
+	connections map[presenceKey]int
+	online      map[int64]map[int64]bool // roomID -> userID -> 在线
+	pending     map[presenceKey]*pendingOffline
+}
+
+func newPresenceTracker() *presenceTracker {
+	return &presenceTracker{
+		connections: make(map[presenceKey]int),
+		online:      make(map[int64]map[int64]bool),
+		pending:     make(map[presenceKey]*pendingOffline),
+	}
+}
+
+// PresenceUser 在线快照中的一个用户
+type PresenceUser struct {
+	UserID  int64 `json:"user_id"`
+	Devices int   `json:"devices"`
+}
+
+// markConnected 用户新增一个连接；首个连接上线时广播player_online，防抖期内重连则不广播
+// 在分片锁之外调用，发布异步进行，不阻塞连接建立
+func (h *Hub) markConnected(roomID, userID int64) {
+	key := presenceKey{roomID: roomID, userID: userID}
+	p := h.presence
+
+	p.mu.Lock()
+	p.connections[key]++
+	devices := p.connections[key]
+	if entry, ok := p.pending[key]; ok {
+		entry.timer.Stop()
+		delete(p.pending, key)
+	}
+	wasOnline := p.online[roomID][userID]
+	if !wasOnline {
+		if p.online[roomID] == nil {
+			p.online[roomID] = make(map[int64]bool)
+		}
+		p.online[roomID][userID] = true
+	}
+	p.mu.Unlock()
+
+	if !wasOnline {
+		go h.publish(context.Background(), &WebSocketMessage{
+			Type:      EventPlayerOnline,
+			RoomID:    roomID,
+			Data:      map[string]interface{}{"user_id": userID, "devices": devices},
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// markDisconnected 用户断开一个连接；最后一个连接断开后延迟确认离线
+func (h *Hub) markDisconnected(roomID, userID int64) {
+	key := presenceKey{roomID: roomID, userID: userID}
+	p := h.presence
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.connections[key]--
+	if p.connections[key] > 0 {
+		return
+	}
+	delete(p.connections, key)
+
+	if _, ok := p.pending[key]; !ok {
+		entry := &pendingOffline{key: key}
+		entry.timer = time.AfterFunc(offlineDebounce, func() {
+			select {
+			case h.offline <- entry:
+			case <-h.done:
+				// Hub已停止，不再广播离线
+			}
+		})
+		p.pending[key] = entry
+	}
+}
+
+// confirmOffline 防抖期结束后仍无连接，广播player_offline
+func (h *Hub) confirmOffline(entry *pendingOffline) {
+	key := entry.key
+	p := h.presence
+
+	p.mu.Lock()
+	// 定时器触发后用户可能已重连又断开，此时以新的等待项为准
+	if p.pending[key] != entry {
+		p.mu.Unlock()
+		return
+	}
+	delete(p.pending, key)
+	stillOffline := p.connections[key] == 0 && p.online[key.roomID][key.userID]
+	if stillOffline {
+		delete(p.online[key.roomID], key.userID)
+		if len(p.online[key.roomID]) == 0 {
+			delete(p.online, key.roomID)
+		}
+	}
+	p.mu.Unlock()
+
+	if stillOffline {
+		logger.Info("用户已离线", "room_id", key.roomID, "user_id", key.userID)
+		go h.publish(context.Background(), &WebSocketMessage{
+			Type:      EventPlayerOffline,
+			RoomID:    key.roomID,
+			Data:      map[string]interface{}{"user_id": key.userID},
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// IsOnline 返回用户在房间内是否在线（防抖期内视为在线）
+func (h *Hub) IsOnline(roomID, userID int64) bool {
+	h.presence.mu.RLock()
+	defer h.presence.mu.RUnlock()
+	return h.presence.online[roomID][userID]
+}
+
+// PresenceSnapshot 返回房间内在线用户及其连接设备数，按用户ID排序
+func (h *Hub) PresenceSnapshot(roomID int64) []*PresenceUser {
+	p := h.presence
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	users := []*PresenceUser{}
+	for userID := range p.online[roomID] {
+		users = append(users, &PresenceUser{
+			UserID:  userID,
+			Devices: p.connections[presenceKey{roomID: roomID, userID: userID}],
+		})
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].UserID < users[j].UserID })
+	return users
+}
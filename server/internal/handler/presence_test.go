@@ -0,0 +1,138 @@
+package handler
+
+import "testing"
+
+// nextPresence 读取指定用户的下一条上下线消息，跳过其他消息
+func nextPresence(t *testing.T, client *Client, eventType string, userID int64) *WebSocketMessage {
+	t.Helper()
+	for {
+		message := nextMessage(t, client, eventType)
+		data, _ := message.Data.(map[string]interface{})
+		if data["user_id"] == float64(userID) {
+			return message
+		}
+	}
+}
+
+// takePending 取出用户的离线等待项并停止其定时器，由测试代替定时器触发
+func takePending(t *testing.T, hub *Hub, roomID, userID int64) *pendingOffline {
+	t.Helper()
+	hub.presence.mu.Lock()
+	defer hub.presence.mu.Unlock()
+	entry := hub.presence.pending[presenceKey{roomID: roomID, userID: userID}]
+	if entry == nil {
+		t.Fatalf("用户%d应在等待确认离线", userID)
+	}
+	entry.timer.Stop()
+	return entry
+}
+
+func hasPending(hub *Hub, roomID, userID int64) bool {
+	hub.presence.mu.RLock()
+	defer hub.presence.mu.RUnlock()
+	return hub.presence.pending[presenceKey{roomID: roomID, userID: userID}] != nil
+}
+
+func TestPresenceMultipleDevices(t *testing.T) {
+	hub := newTestHub(t)
+	observer := newTestClient(hub, 1, 99)
+	hub.Register(observer)
+	defer hub.writers.Done()
+
+	phone := newTestClient(hub, 1, 7)
+	hub.Register(phone)
+	defer hub.writers.Done()
+	online := nextPresence(t, observer, EventPlayerOnline, 7)
+	if data := online.Data.(map[string]interface{}); data["devices"] != float64(1) {
+		t.Fatalf("首个连接的设备数 = %v，期望 1", data["devices"])
+	}
+
+	// 第二台设备连接时已在线，不再广播上线
+	pad := newTestClient(hub, 1, 7)
+	hub.Register(pad)
+	defer hub.writers.Done()
+	snapshot := hub.PresenceSnapshot(1)
+	if len(snapshot) != 2 || snapshot[0].UserID != 7 || snapshot[0].Devices != 2 || snapshot[1].UserID != 99 {
+		t.Fatalf("在线快照 = %+v", snapshot)
+	}
+
+	// 还有一台设备连接着，断开另一台不进入离线等待
+	hub.Unregister(phone)
+	if !hub.IsOnline(1, 7) || hasPending(hub, 1, 7) {
+		t.Fatal("仍有设备连接时应保持在线")
+	}
+	if snapshot := hub.PresenceSnapshot(1); snapshot[0].Devices != 1 {
+		t.Fatalf("断开一台后设备数 = %d，期望 1", snapshot[0].Devices)
+	}
+
+	// 最后一台断开后在防抖期内仍视为在线
+	hub.Unregister(pad)
+	if !hub.IsOnline(1, 7) {
+		t.Fatal("防抖期内应视为在线")
+	}
+	entry := takePending(t, hub, 1, 7)
+
+	// 防抖期结束，由Run协程确认离线并广播
+	hub.offline <- entry
+	nextPresence(t, observer, EventPlayerOffline, 7)
+	if hub.IsOnline(1, 7) {
+		t.Fatal("确认离线后不应在线")
+	}
+	if snapshot := hub.PresenceSnapshot(1); len(snapshot) != 1 || snapshot[0].UserID != 99 {
+		t.Fatalf("离线后的在线快照 = %+v", snapshot)
+	}
+}
+
+func TestPresenceReconnectWithinDebounce(t *testing.T) {
+	hub := newTestHub(t)
+
+	first := newTestClient(hub, 1, 7)
+	hub.Register(first)
+	defer hub.writers.Done()
+	hub.Unregister(first)
+	stale := takePending(t, hub, 1, 7)
+
+	// 防抖期内重连，取消离线等待
+	second := newTestClient(hub, 1, 7)
+	hub.Register(second)
+	defer hub.writers.Done()
+	if hasPending(hub, 1, 7) {
+		t.Fatal("重连后应取消离线等待")
+	}
+
+	// 再次断开产生新的等待项，之前的定时器触发时不应确认离线
+	hub.Unregister(second)
+	current := takePending(t, hub, 1, 7)
+	hub.confirmOffline(stale)
+	if !hub.IsOnline(1, 7) || !hasPending(hub, 1, 7) {
+		t.Fatal("过期的等待项不应确认离线")
+	}
+
+	hub.confirmOffline(current)
+	if hub.IsOnline(1, 7) || hasPending(hub, 1, 7) {
+		t.Fatal("当前的等待项到期后应确认离线")
+	}
+}
+
+func TestPresenceRoomsAreIndependent(t *testing.T) {
+	hub := newTestHub(t)
+	inRoom1 := newTestClient(hub, 1, 7)
+	hub.Register(inRoom1)
+	defer hub.writers.Done()
+	inRoom2 := newTestClient(hub, 2, 7)
+	hub.Register(inRoom2)
+	defer hub.writers.Done()
+
+	// 离开一个房间不影响同一用户在另一个房间的在线状态
+	hub.Unregister(inRoom1)
+	hub.confirmOffline(takePending(t, hub, 1, 7))
+	if hub.IsOnline(1, 7) {
+		t.Fatal("房间1应已离线")
+	}
+	if !hub.IsOnline(2, 7) || hasPending(hub, 2, 7) {
+		t.Fatal("房间2应仍在线")
+	}
+	if snapshot := hub.PresenceSnapshot(1); len(snapshot) != 0 {
+		t.Fatalf("房间1的在线快照应为空: %+v", snapshot)
+	}
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"mahjong-server/internal/logger"
+)
+
+// requestIDHeader 请求ID头，Nginx等上游已生成时沿用，响应中回传便于排查
+const requestIDHeader = "X-Request-Id"
+
+// maxRequestIDLength 沿用上游请求ID的最大长度
+const maxRequestIDLength = 64
+
+// WithRequestID 为每个请求分配请求ID并放入ctx，之后以r.Context()记录的日志都带有request_id
+func WithRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(requestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(logger.WithRequestID(r.Context(), requestID)))
+	})
+}
+
+// newRequestID 生成16位十六进制的请求ID
+func newRequestID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// validRequestID 上游请求ID只接受字母、数字和-_.，避免日志注入
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, ch := range requestID {
+		switch {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9', ch == '-', ch == '_', ch == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -1,32 +1,61 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"mahjong-server/internal/events"
 	"mahjong-server/internal/logger"
+	"mahjong-server/internal/service"
 )
 
 // WebSocketMessage 定义WebSocket消息结构
 type WebSocketMessage struct {
 	Type      string      `json:"type"`
 	RoomID    int64       `json:"room_id"`
+	Seq       int64       `json:"seq,omitempty"` // 房间内单调递增的序号，用于断线重连补发；命令应答不带序号
+	RequestID string      `json:"request_id,omitempty"` // 命令应答对应的请求ID
 	Data      interface{} `json:"data"`
 	Timestamp int64       `json:"timestamp"`
 }
 
 // RoomEvent 房间事件类型
 const (
-	EventPlayerJoined   = "player_joined"
-	EventPlayerLeft     = "player_left"
-	EventScoreTransfer  = "score_transfer"
-	EventRoomSettled    = "room_settled"
-	EventPlayerUpdated  = "player_updated"
-	EventRoomUpdated    = "room_updated"
+	EventPlayerJoined     = "player_joined"
+	EventPlayerLeft       = "player_left"
+	EventPlayerOnline     = "player_online"
+	EventPlayerOffline    = "player_offline"
+	EventScoreTransfer    = "score_transfer"
+	EventTransferVoided   = "transfer_voided"
+	EventRoomSettled      = "room_settled"
+	EventPlayerUpdated    = "player_updated"
+	EventRoomUpdated      = "room_updated"
+	EventConnected        = "connected"         // 连接建立，seq为房间当前序号
+	EventResyncRequired   = "resync_required"   // 缺失的消息已不在历史缓冲中，客户端需全量刷新
+	EventServerRestarting = "server_restarting" // 服务即将重启，客户端在retry_after秒后重连
+)
+
+// isEphemeral 是否为即时事件：聊天、准备和上下线只推送给当前在线的客户端，
+// 不占用房间序号也不写入历史，重连时不补发，不会把分数等状态变更挤出历史缓冲
+func isEphemeral(eventType string) bool {
+	switch eventType {
+	case EventChat, EventPlayerReady, EventPlayerOnline, EventPlayerOffline:
+		return true
+	}
+	return false
+}
+
+const (
+	// roomHistorySize 每个房间保留的历史消息数量，只计状态变更事件
+	roomHistorySize = 100
+	// roomHistoryTTL 房间无新消息超过该时长后清理其历史
+	roomHistoryTTL = 24 * time.Hour
 )
 
 // Client 表示一个WebSocket客户端连接
@@ -36,81 +65,491 @@ type Client struct {
 	userID   int64
 	send     chan []byte
 	hub      *Hub
+	service  *service.MahjongService
 	mu       sync.Mutex
+	closed   bool  // send是否已关闭，由mu保护
+	lastSeq  int64 // 重连时客户端已收到的最后序号，-1表示新连接
+
+	closeCode   int    // 关闭连接时发送的close code，0表示不带状态码
+	closeReason string
+
+	commandTimeout time.Duration // 单条命令的处理超时
+}
+
+// closeSend 关闭发送队列，可重复调用
+func (c *Client) closeSend() {
+	c.closeWith(0, "")
+}
+
+// closeWith 关闭发送队列，writePump发完队列中的消息后以指定close code关闭连接
+func (c *Client) closeWith(code int, reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed {
+		c.closed = true
+		c.closeCode = code
+		c.closeReason = reason
+		close(c.send)
+	}
+}
+
+// trySend 在分片锁之外向客户端发送消息（如命令应答），队列已满或已关闭时返回false
+func (c *Client) trySend(data []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return false
+	}
+	select {
+	case c.send <- data:
+		return true
+	default:
+		return false
+	}
+}
+
+// roomHistory 房间的序号和最近消息缓冲
+type roomHistory struct {
+	seq        int64
+	messages   []*WebSocketMessage
+	lastActive time.Time
+}
+
+const (
+	// hubShardCount 房间按ID分到若干分片，各分片独立加锁，不同房间的广播互不阻塞
+	hubShardCount = 64
+	// clientSendBuffer 每个客户端发送队列的长度，写满的客户端视为慢客户端并断开
+	clientSendBuffer = 256
+	// hubHeartbeatInterval Run循环更新心跳的间隔，健康检查据此判断Run是否仍在运行
+	hubHeartbeatInterval = 5 * time.Second
+)
+
+// roomState 房间在本实例上的连接和历史消息，由所在分片的锁保护
+type roomState struct {
+	clients map[*Client]bool
+	history roomHistory
+}
+
+// hubShard 一组房间及保护它们的锁
+type hubShard struct {
+	mu    sync.Mutex
+	rooms map[int64]*roomState
+}
+
+// room 返回房间状态，不存在时创建，调用方需持有分片锁
+func (s *hubShard) room(roomID int64) *roomState {
+	room, exists := s.rooms[roomID]
+	if !exists {
+		room = &roomState{clients: make(map[*Client]bool)}
+		s.rooms[roomID] = room
+	}
+	return room
+}
+
+// HubStats Hub的连接数和消息投递计数
+type HubStats struct {
+	Connections       int64 `json:"connections"`
+	Rooms             int64 `json:"rooms"`
+	MessagesDelivered int64 `json:"messages_delivered"`
+	MessagesDropped   int64 `json:"messages_dropped"`
+	ClientsEvicted    int64 `json:"clients_evicted"`
+	PublishErrors     int64 `json:"publish_errors"`
 }
 
 // Hub 维护所有活跃的客户端连接
+// 连接的注册、注销和消息投递都在房间所在分片的锁内完成，
+// 向客户端发送队列写入均为非阻塞，任何一个慢客户端都不会拖住广播方
 type Hub struct {
-	clients    map[*Client]bool
-	rooms      map[int64]map[*Client]bool // roomID -> clients
-	register   chan *Client
-	unregister chan *Client
-	broadcast  chan *WebSocketMessage
-	mu         sync.RWMutex
+	shards      [hubShardCount]hubShard
+	presence    *presenceTracker
+	offline     chan *pendingOffline
+	broadcaster Broadcaster
+
+	closing atomic.Bool    // 关闭中，不再接受新连接
+	done    chan struct{}  // 关闭后Run退出
+	writers sync.WaitGroup // 运行中的writePump
+
+	heartbeat atomic.Int64 // Run循环最近一次心跳的UnixNano，供健康检查判断Run是否卡住
+
+	connections       atomic.Int64
+	rooms             atomic.Int64 // 有本地连接的房间数
+	messagesDelivered atomic.Int64
+	messagesDropped   atomic.Int64
+	clientsEvicted    atomic.Int64
+	publishErrors     atomic.Int64
 }
 
-// NewHub 创建新的Hub实例
-func NewHub() *Hub {
-	return &Hub{
-		clients:    make(map[*Client]bool),
-		rooms:      make(map[int64]map[*Client]bool),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
-		broadcast:  make(chan *WebSocketMessage),
+// NewHub 创建新的Hub实例，并订阅broadcaster上的房间消息
+func NewHub(broadcaster Broadcaster) (*Hub, error) {
+	h := &Hub{
+		presence:    newPresenceTracker(),
+		offline:     make(chan *pendingOffline),
+		broadcaster: broadcaster,
+		done:        make(chan struct{}),
 	}
+	for i := range h.shards {
+		h.shards[i].rooms = make(map[int64]*roomState)
+	}
+
+	if err := broadcaster.Subscribe(h.deliver, h.resyncRooms); err != nil {
+		return nil, fmt.Errorf("订阅广播消息失败: %w", err)
+	}
+	return h, nil
 }
 
-// Run 启动Hub的消息处理循环
+// Run 处理离线确认和历史清理等后台任务，Shutdown后退出
 func (h *Hub) Run() {
+	cleanupTicker := time.NewTicker(10 * time.Minute)
+	defer cleanupTicker.Stop()
+	heartbeatTicker := time.NewTicker(hubHeartbeatInterval)
+	defer heartbeatTicker.Stop()
+	h.heartbeat.Store(time.Now().UnixNano())
+
 	for {
 		select {
-		case client := <-h.register:
-			h.mu.Lock()
-			h.clients[client] = true
-			if h.rooms[client.roomID] == nil {
-				h.rooms[client.roomID] = make(map[*Client]bool)
+		case <-h.done:
+			logger.Info("Hub已停止")
+			return
+
+		case <-heartbeatTicker.C:
+			h.heartbeat.Store(time.Now().UnixNano())
+
+		case entry := <-h.offline:
+			h.confirmOffline(entry)
+
+		case <-cleanupTicker.C:
+			h.cleanupHistory()
+		}
+	}
+}
+
+// shardFor 返回房间所在的分片
+func (h *Hub) shardFor(roomID int64) *hubShard {
+	return &h.shards[uint64(roomID)%hubShardCount]
+}
+
+// Register 注册客户端，并补发其断线期间错过的消息；Hub关闭中时返回false
+// 注册成功后调用方需启动writePump
+func (h *Hub) Register(client *Client) bool {
+	// 本实例可能还没收到过该房间的消息，以Broadcaster分配的序号为准
+	currentSeq, err := h.broadcaster.CurrentSeq(client.roomID)
+	if err != nil {
+		logger.Warn("读取房间序号失败", "room_id", client.roomID, "error", err.Error())
+	}
+
+	shard := h.shardFor(client.roomID)
+	shard.mu.Lock()
+	// 在分片锁内检查，Shutdown遍历过该分片后不会再有新连接加入
+	if h.closing.Load() {
+		shard.mu.Unlock()
+		return false
+	}
+	h.writers.Add(1)
+	room := shard.room(client.roomID)
+	if currentSeq > room.history.seq {
+		h.resetSeq(room, client.roomID, currentSeq)
+	}
+	room.clients[client] = true
+	if len(room.clients) == 1 {
+		h.rooms.Add(1)
+	}
+	// 在分片锁内补发，保证与后续广播之间不丢不重
+	h.replayMissed(client, &room.history)
+	shard.mu.Unlock()
+
+	total := h.connections.Add(1)
+	logger.Info("客户端已注册", "room_id", client.roomID, "user_id", client.userID, "total_clients", total)
+	h.markConnected(client.roomID, client.userID)
+	return true
+}
+
+// Unregister 注销客户端，客户端已因发送队列写满被断开时不重复处理
+func (h *Hub) Unregister(client *Client) {
+	shard := h.shardFor(client.roomID)
+	shard.mu.Lock()
+	removed := h.removeClient(shard.rooms[client.roomID], client)
+	shard.mu.Unlock()
+
+	if removed {
+		h.markDisconnected(client.roomID, client.userID)
+		logger.Info("客户端已注销", "room_id", client.roomID, "user_id", client.userID, "total_clients", h.connections.Load())
+	}
+}
+
+// removeClient 将客户端移出房间并关闭其发送队列，客户端不在房间中时返回false
+// 调用方需持有分片锁，发送队列只在锁内关闭，因此不会与投递并发写入已关闭的队列
+func (h *Hub) removeClient(room *roomState, client *Client) bool {
+	if room == nil || !room.clients[client] {
+		return false
+	}
+	delete(room.clients, client)
+	if len(room.clients) == 0 {
+		h.rooms.Add(-1)
+	}
+	client.closeSend()
+	h.connections.Add(-1)
+	return true
+}
+
+// deliver 记录消息并发送给房间内所有本地客户端，发送队列已满的客户端会被断开
+// 即时事件不记录，直接发送
+func (h *Hub) deliver(message *WebSocketMessage) {
+	shard := h.shardFor(message.RoomID)
+	var evicted []*Client
+
+	shard.mu.Lock()
+	room := shard.room(message.RoomID)
+	if isEphemeral(message.Type) {
+		message.Seq = 0
+		evicted = h.sendToRoom(room, message)
+		shard.mu.Unlock()
+		h.evict(evicted, message)
+		return
+	}
+	// Broadcaster分配的序号不连续说明本实例漏收了消息，序号变小说明Redis中的序号键已重建，
+	// 两种情况历史都不再可信，房间内的客户端全量同步，这条消息的内容包含在同步结果中
+	if message.Seq > 0 && message.Seq != room.history.seq+1 {
+		h.resetSeq(room, message.RoomID, message.Seq)
+		shard.mu.Unlock()
+		return
+	}
+	room.history.record(message)
+	evicted = h.sendToRoom(room, message)
+	shard.mu.Unlock()
+	h.evict(evicted, message)
+}
+
+// sendToRoom 将消息发给房间内所有本地客户端，发送队列已满的客户端移出房间并返回
+// 调用方需持有分片锁
+func (h *Hub) sendToRoom(room *roomState, message *WebSocketMessage) []*Client {
+	var evicted []*Client
+	data := h.marshalMessage(message)
+	for client := range room.clients {
+		select {
+		case client.send <- data:
+			h.messagesDelivered.Add(1)
+		default:
+			evicted = append(evicted, client)
+		}
+	}
+	for _, client := range evicted {
+		h.removeClient(room, client)
+	}
+	return evicted
+}
+
+// evict 记录因发送队列已满被断开的客户端并标记其离线，在分片锁外调用
+func (h *Hub) evict(evicted []*Client, message *WebSocketMessage) {
+	for _, client := range evicted {
+		h.messagesDropped.Add(1)
+		h.clientsEvicted.Add(1)
+		logger.Warn("客户端发送队列已满，断开连接", "room_id", client.roomID, "user_id", client.userID, "seq", message.Seq)
+		h.markDisconnected(client.roomID, client.userID)
+	}
+}
+
+// resetSeq 将房间序号设为seq并清空历史，已连接的客户端收到resync_required后全量同步
+// 调用方需持有分片锁
+func (h *Hub) resetSeq(room *roomState, roomID, seq int64) {
+	// 本实例第一次收到该房间的消息时没有需要同步的内容
+	if room.history.seq > 0 || len(room.clients) > 0 {
+		logger.Warn("房间消息序号不连续，通知客户端全量同步", "room_id", roomID,
+			"local_seq", room.history.seq, "seq", seq, "clients", len(room.clients))
+	}
+	room.history.seq = seq
+	room.history.messages = nil
+	room.history.lastActive = time.Now()
+	for client := range room.clients {
+		h.sendTo(client, &WebSocketMessage{
+			Type:      EventResyncRequired,
+			RoomID:    roomID,
+			Seq:       seq,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// resyncRooms 在Broadcaster重新订阅后调用，断开期间的消息已丢失：
+// 没有本地连接的房间直接丢弃历史，有连接的房间按Broadcaster的当前序号通知客户端全量同步
+func (h *Hub) resyncRooms() {
+	for i := range h.shards {
+		shard := &h.shards[i]
+		var roomIDs []int64
+		shard.mu.Lock()
+		for roomID, room := range shard.rooms {
+			if len(room.clients) == 0 {
+				delete(shard.rooms, roomID)
+				continue
 			}
-			h.rooms[client.roomID][client] = true
-			h.mu.Unlock()
-			
-			logger.Info("客户端已注册", "room_id", client.roomID, "user_id", client.userID, "total_clients", len(h.clients))
-
-		case client := <-h.unregister:
-			h.mu.Lock()
-			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				if roomClients, exists := h.rooms[client.roomID]; exists {
-					delete(roomClients, client)
-					if len(roomClients) == 0 {
-						delete(h.rooms, client.roomID)
-					}
-				}
-				close(client.send)
+			roomIDs = append(roomIDs, roomID)
+		}
+		shard.mu.Unlock()
+
+		for _, roomID := range roomIDs {
+			currentSeq, err := h.broadcaster.CurrentSeq(roomID)
+			if err != nil {
+				logger.Warn("读取房间序号失败，按本地序号通知全量同步", "room_id", roomID, "error", err.Error())
 			}
-			h.mu.Unlock()
-			
-			logger.Info("客户端已注销", "room_id", client.roomID, "user_id", client.userID, "total_clients", len(h.clients))
-
-		case message := <-h.broadcast:
-			h.mu.RLock()
-			if roomClients, exists := h.rooms[message.RoomID]; exists {
-				for client := range roomClients {
-					select {
-					case client.send <- h.marshalMessage(message):
-					default:
-						close(client.send)
-						delete(h.clients, client)
-						delete(roomClients, client)
-					}
-				}
+			shard.mu.Lock()
+			// 重连后已收到的新消息会推进本地序号，此时已经按不连续处理过
+			if room, ok := shard.rooms[roomID]; ok && (err != nil || currentSeq > room.history.seq) {
+				h.resetSeq(room, roomID, max(currentSeq, room.history.seq))
+			}
+			shard.mu.Unlock()
+		}
+	}
+}
+
+// record 为消息分配房间序号并写入历史缓冲
+func (history *roomHistory) record(message *WebSocketMessage) {
+	// 多实例部署时序号已由Broadcaster统一分配
+	if message.Seq == 0 {
+		message.Seq = history.seq + 1
+	}
+	if message.Seq > history.seq {
+		history.seq = message.Seq
+	}
+	history.lastActive = time.Now()
+
+	history.messages = append(history.messages, message)
+	if len(history.messages) > roomHistorySize {
+		history.messages = history.messages[len(history.messages)-roomHistorySize:]
+	}
+}
+
+// replayMissed 向新注册的客户端发送当前序号，并补发其断线期间错过的消息，调用方需持有分片锁
+func (h *Hub) replayMissed(client *Client, history *roomHistory) {
+	currentSeq := history.seq
+	messages := history.messages
+
+	if client.lastSeq >= 0 && client.lastSeq != currentSeq {
+		// 序号比当前还大说明服务端重启过；比缓冲中最早的还旧说明已被淘汰
+		var missed []*WebSocketMessage
+		for _, message := range messages {
+			if message.Seq > client.lastSeq {
+				missed = append(missed, message)
+			}
+		}
+		if client.lastSeq > currentSeq || len(missed) == 0 || missed[0].Seq != client.lastSeq+1 {
+			logger.Info("客户端需要全量同步", "room_id", client.roomID, "user_id", client.userID,
+				"last_seq", client.lastSeq, "current_seq", currentSeq)
+			h.sendTo(client, &WebSocketMessage{
+				Type:      EventResyncRequired,
+				RoomID:    client.roomID,
+				Seq:       currentSeq,
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		for _, message := range missed {
+			h.sendTo(client, message)
+		}
+		logger.Info("已补发错过的消息", "room_id", client.roomID, "user_id", client.userID,
+			"last_seq", client.lastSeq, "count", len(missed))
+		return
+	}
+
+	h.sendTo(client, &WebSocketMessage{
+		Type:      EventConnected,
+		RoomID:    client.roomID,
+		Seq:       currentSeq,
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// sendTo 向单个客户端发送消息，发送队列满时丢弃，调用方需持有分片锁
+func (h *Hub) sendTo(client *Client, message *WebSocketMessage) {
+	select {
+	case client.send <- h.marshalMessage(message):
+		h.messagesDelivered.Add(1)
+	default:
+		h.messagesDropped.Add(1)
+		logger.Warn("客户端发送队列已满，消息被丢弃", "room_id", client.roomID, "user_id", client.userID, "seq", message.Seq)
+	}
+}
+
+// cleanupHistory 清理没有本地连接且长时间没有消息的房间
+func (h *Hub) cleanupHistory() {
+	for i := range h.shards {
+		shard := &h.shards[i]
+		shard.mu.Lock()
+		for roomID, room := range shard.rooms {
+			if len(room.clients) == 0 && time.Since(room.history.lastActive) > roomHistoryTTL {
+				delete(shard.rooms, roomID)
 			}
-			h.mu.RUnlock()
 		}
+		shard.mu.Unlock()
+	}
+}
+
+// Shutdown 通知所有客户端服务即将重启并以1012关闭连接，停止Run，等待各连接发完剩余消息
+// retryAfter为建议客户端重连的等待时间
+func (h *Hub) Shutdown(ctx context.Context, retryAfter time.Duration) error {
+	if !h.closing.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	retrySeconds := int64(retryAfter / time.Second)
+	var closed int
+	for i := range h.shards {
+		shard := &h.shards[i]
+		shard.mu.Lock()
+		for roomID, room := range shard.rooms {
+			for client := range room.clients {
+				h.sendTo(client, &WebSocketMessage{
+					Type:      EventServerRestarting,
+					RoomID:    roomID,
+					Data:      map[string]interface{}{"retry_after": retrySeconds},
+					Timestamp: time.Now().Unix(),
+				})
+				client.closeWith(websocket.CloseServiceRestart, "server restarting")
+				h.removeClient(room, client)
+				closed++
+			}
+		}
+		shard.mu.Unlock()
+	}
+	close(h.done)
+	logger.Info("已通知客户端服务重启", "clients", closed, "retry_after", retrySeconds)
+
+	flushed := make(chan struct{})
+	go func() {
+		h.writers.Wait()
+		close(flushed)
+	}()
+
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("等待WebSocket连接关闭超时: %w", ctx.Err())
+	}
+}
+
+// LastHeartbeat 返回Run循环最近一次心跳的时间，Run未启动时返回零值
+func (h *Hub) LastHeartbeat() time.Time {
+	nanos := h.heartbeat.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// Stats 返回连接数和消息投递计数
+func (h *Hub) Stats() HubStats {
+	return HubStats{
+		Connections:       h.connections.Load(),
+		Rooms:             h.rooms.Load(),
+		MessagesDelivered: h.messagesDelivered.Load(),
+		MessagesDropped:   h.messagesDropped.Load(),
+		ClientsEvicted:    h.clientsEvicted.Load(),
+		PublishErrors:     h.publishErrors.Load(),
 	}
 }
 
 // BroadcastToRoom 向指定房间广播消息
-func (h *Hub) BroadcastToRoom(roomID int64, eventType string, data interface{}) {
+func (h *Hub) BroadcastToRoom(ctx context.Context, roomID int64, eventType string, data interface{}) {
 	message := &WebSocketMessage{
 		Type:      eventType,
 		RoomID:    roomID,
@@ -118,12 +557,22 @@ func (h *Hub) BroadcastToRoom(roomID int64, eventType string, data interface{})
 		Timestamp: time.Now().Unix(),
 	}
 	
-	select {
-	case h.broadcast <- message:
-		logger.Info("消息已加入广播队列", "room_id", roomID, "event_type", eventType)
-	default:
-		logger.Warn("广播队列已满，消息被丢弃", "room_id", roomID, "event_type", eventType)
+	h.publish(ctx, message)
+}
+
+// HandleEvent 作为事件总线的订阅者，将业务事件推送给房间内的客户端
+func (h *Hub) HandleEvent(ctx context.Context, event events.Event) {
+	h.BroadcastToRoom(ctx, event.RoomID(), event.EventType(), event)
+}
+
+// publish 通过Broadcaster发布消息，各实例的Hub收到后再发给本地客户端
+func (h *Hub) publish(ctx context.Context, message *WebSocketMessage) {
+	if err := h.broadcaster.Publish(message); err != nil {
+		h.publishErrors.Add(1)
+		logger.ErrorContext(ctx, "发布广播消息失败", "room_id", message.RoomID, "event_type", message.Type, "error", err.Error())
+		return
 	}
+	logger.InfoContext(ctx, "消息已发布", "room_id", message.RoomID, "event_type", message.Type, "seq", message.Seq)
 }
 
 // marshalMessage 将消息序列化为JSON
@@ -139,13 +588,17 @@ func (h *Hub) marshalMessage(message *WebSocketMessage) []byte {
 // WebSocketHandler 处理WebSocket连接
 type WebSocketHandler struct {
 	hub *Hub
+	service *service.MahjongService
 	upgrader websocket.Upgrader
+	commandTimeout time.Duration
 }
 
 // NewWebSocketHandler 创建WebSocket处理器
-func NewWebSocketHandler(hub *Hub) *WebSocketHandler {
+func NewWebSocketHandler(hub *Hub, mahjongService *service.MahjongService, commandTimeout time.Duration) *WebSocketHandler {
 	return &WebSocketHandler{
 		hub: hub,
+		service: mahjongService,
+		commandTimeout: commandTimeout,
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
 				// 允许所有来源的连接（生产环境应该更严格）
@@ -157,68 +610,95 @@ func NewWebSocketHandler(hub *Hub) *WebSocketHandler {
 
 // HandleWebSocket 处理WebSocket连接请求
 func (h *WebSocketHandler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
-	logger.Info("WebSocket连接请求", "path", r.URL.Path, "query", r.URL.RawQuery)
-	logger.Info("WebSocket处理器被调用", "method", r.Method, "remote_addr", r.RemoteAddr)
+	logger.InfoContext(r.Context(), "WebSocket连接请求", "path", r.URL.Path, "query", logger.RedactQuery(r.URL.RawQuery))
+	logger.InfoContext(r.Context(), "WebSocket处理器被调用", "method", r.Method, "remote_addr", r.RemoteAddr)
 	
 	// 检查ResponseWriter类型
-	logger.Info("ResponseWriter类型", "type", fmt.Sprintf("%T", w))
+	logger.InfoContext(r.Context(), "ResponseWriter类型", "type", fmt.Sprintf("%T", w))
 	
-	// 从查询参数获取房间ID和用户ID
+	// 从查询参数获取房间ID和登录态，连接的用户只由session_id确定
 	roomIDStr := r.URL.Query().Get("room_id")
 	userIDStr := r.URL.Query().Get("user_id")
+	sessionID := r.URL.Query().Get("session_id")
+	lastSeqStr := r.URL.Query().Get("last_seq")
 	
-	if roomIDStr == "" || userIDStr == "" {
-		http.Error(w, "Missing room_id or user_id", http.StatusBadRequest)
+	if roomIDStr == "" || sessionID == "" {
+		http.Error(w, "Missing room_id or session_id", http.StatusBadRequest)
 		return
 	}
 	
-	// 解析房间ID和用户ID
-	var roomID, userID int64
+	// 解析房间ID
+	var roomID int64
 	if _, err := fmt.Sscanf(roomIDStr, "%d", &roomID); err != nil {
 		http.Error(w, "Invalid room_id", http.StatusBadRequest)
 		return
 	}
-	
-	if _, err := fmt.Sscanf(userIDStr, "%d", &userID); err != nil {
-		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+
+	userID, resp := h.service.RoomConnectionUser(r.Context(), sessionID, roomID)
+	if resp != nil {
+		http.Error(w, resp.Message, int(resp.Code))
+		return
+	}
+
+	// 兼容仍携带user_id的客户端，但必须与登录用户一致
+	if userIDStr != "" && userIDStr != fmt.Sprint(userID) {
+		http.Error(w, "user_id does not match session", http.StatusForbidden)
 		return
 	}
+
+	// 重连时携带last_seq，补发断线期间的消息
+	var lastSeq int64 = -1
+	if lastSeqStr != "" {
+		if _, err := fmt.Sscanf(lastSeqStr, "%d", &lastSeq); err != nil || lastSeq < 0 {
+			http.Error(w, "Invalid last_seq", http.StatusBadRequest)
+			return
+		}
+	}
 	
 	// 升级HTTP连接为WebSocket连接
-	logger.Info("开始WebSocket升级", "room_id", roomID, "user_id", userID)
+	logger.InfoContext(r.Context(), "开始WebSocket升级", "room_id", roomID, "user_id", userID, "last_seq", lastSeq)
 	conn, err := h.upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		logger.Error("WebSocket升级失败", "error", err.Error(), "room_id", roomID, "user_id", userID)
+		logger.ErrorContext(r.Context(), "WebSocket升级失败", "error", err.Error(), "room_id", roomID, "user_id", userID)
 		return
 	}
 	
 	// 创建客户端
 	client := &Client{
-		conn:   conn,
-		roomID: roomID,
-		userID: userID,
-		send:   make(chan []byte, 256),
-		hub:    h.hub,
+		conn:    conn,
+		roomID:  roomID,
+		userID:  userID,
+		send:    make(chan []byte, clientSendBuffer),
+		hub:     h.hub,
+		service: h.service,
+		lastSeq: lastSeq,
+		commandTimeout: h.commandTimeout,
 	}
 	
-	// 注册客户端
-	client.hub.register <- client
+	// 注册客户端，服务关闭中时直接告知客户端稍后重连
+	if !client.hub.Register(client) {
+		conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "server restarting"),
+			time.Now().Add(time.Second))
+		conn.Close()
+		return
+	}
 	
 	// 启动客户端处理协程
 	go client.writePump()
 	go client.readPump()
 	
-	logger.Info("WebSocket连接已建立", "room_id", roomID, "user_id", userID)
+	logger.InfoContext(r.Context(), "WebSocket连接已建立", "room_id", roomID, "user_id", userID)
 }
 
-// readPump 处理从客户端读取消息
+// readPump 读取客户端命令并依次执行
 func (c *Client) readPump() {
 	defer func() {
-		c.hub.unregister <- c
+		c.hub.Unregister(c)
 		c.conn.Close()
 	}()
 	
-	c.conn.SetReadLimit(512)
+	c.conn.SetReadLimit(maxCommandSize)
 	c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
 	c.conn.SetPongHandler(func(string) error {
 		c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
@@ -226,13 +706,17 @@ func (c *Client) readPump() {
 	})
 	
 	for {
-		_, _, err := c.conn.ReadMessage()
+		_, message, err := c.conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				logger.Error("WebSocket读取错误", "error", err.Error(), "room_id", c.roomID, "user_id", c.userID)
 			}
 			break
 		}
+
+		// 收到任何消息都说明连接存活
+		c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+		c.handleCommand(message)
 	}
 }
 
@@ -242,6 +726,7 @@ func (c *Client) writePump() {
 	defer func() {
 		ticker.Stop()
 		c.conn.Close()
+		c.hub.writers.Done()
 	}()
 	
 	for {
@@ -249,7 +734,12 @@ func (c *Client) writePump() {
 		case message, ok := <-c.send:
 			c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
 			if !ok {
-				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				// 关闭码在关闭send前写入，channel关闭后读取是安全的
+				closeMessage := []byte{}
+				if c.closeCode != 0 {
+					closeMessage = websocket.FormatCloseMessage(c.closeCode, c.closeReason)
+				}
+				c.conn.WriteMessage(websocket.CloseMessage, closeMessage)
 				return
 			}
 			
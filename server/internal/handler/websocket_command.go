@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"mahjong-server/internal/logger"
+	"mahjong-server/internal/service"
+)
+
+// tracer WebSocket命令的span由它创建，HTTP请求的span由otelhttp创建
+var tracer = otel.Tracer("mahjong-server/internal/handler")
+
+// 客户端命令类型
+const (
+	CommandTransfer = "transfer"
+	CommandUndo     = "undo"
+	CommandReady    = "ready"
+	CommandChat     = "chat"
+	CommandPing     = "ping"
+)
+
+// 命令应答类型
+const (
+	ReplyAck   = "ack"
+	ReplyError = "error"
+	ReplyPong  = "pong"
+)
+
+// 命令广播的房间事件
+const (
+	EventPlayerReady = "player_ready"
+	EventChat        = "chat"
+)
+
+const (
+	// maxCommandSize 单条命令的最大字节数
+	maxCommandSize = 4096
+	// maxChatLength 聊天消息的最大字符数
+	maxChatLength = 200
+)
+
+// WebSocketCommand 客户端发送的命令
+type WebSocketCommand struct {
+	Type      string          `json:"type"`
+	RequestID string          `json:"request_id"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// CommandError 命令失败时返回的错误
+type CommandError struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+// handleCommand 解析并执行一条客户端命令，结果只回复给发送者
+func (c *Client) handleCommand(raw []byte) {
+	var cmd WebSocketCommand
+	if err := json.Unmarshal(raw, &cmd); err != nil {
+		c.replyError("", 400, "Invalid command")
+		return
+	}
+
+	// 每条命令一个服务端请求ID，与客户端的request_id（用于匹配应答）分别记录
+	ctx, cancel := withRequestTimeout(logger.WithRequestID(context.Background(), newRequestID()), c.commandTimeout)
+	defer cancel()
+
+	logger.DebugContext(ctx, "收到WebSocket命令", "room_id", c.roomID, "user_id", c.userID, "type", cmd.Type, "command_request_id", cmd.RequestID)
+
+	// 心跳不记录追踪
+	if cmd.Type != CommandPing {
+		var span trace.Span
+		ctx, span = tracer.Start(ctx, "WebSocket "+cmd.Type, trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attribute.Int64("room_id", c.roomID), attribute.Int64("user_id", c.userID)))
+		defer span.End()
+	}
+
+	switch cmd.Type {
+	case CommandPing:
+		c.reply(&WebSocketMessage{Type: ReplyPong, RequestID: cmd.RequestID})
+	case CommandTransfer:
+		c.handleTransferCommand(ctx, &cmd)
+	case CommandUndo:
+		c.handleUndoCommand(ctx, &cmd)
+	case CommandReady:
+		c.handleReadyCommand(ctx, &cmd)
+	case CommandChat:
+		c.handleChatCommand(ctx, &cmd)
+	default:
+		c.replyError(cmd.RequestID, 400, "Unknown command type")
+	}
+}
+
+// 转移分数，转出方固定为当前连接的用户，连接的用户在升级时由session_id确定
+func (c *Client) handleTransferCommand(ctx context.Context, cmd *WebSocketCommand) {
+	var req struct {
+		ToUserId int64 `json:"to_user_id"`
+		Amount   int32 `json:"amount"`
+	}
+	if err := json.Unmarshal(cmd.Data, &req); err != nil {
+		c.replyError(cmd.RequestID, 400, "Invalid command data")
+		return
+	}
+	if req.Amount <= 0 || req.ToUserId == 0 || req.ToUserId == c.userID {
+		c.replyError(cmd.RequestID, 400, "Invalid transfer")
+		return
+	}
+
+	response, err := c.service.TransferScore(ctx, &service.TransferScoreRequest{
+		RoomId:     c.roomID,
+		FromUserId: c.userID,
+		ToUserId:   req.ToUserId,
+		Amount:     req.Amount,
+	})
+	c.replyResponse(ctx, cmd.RequestID, response, err)
+}
+
+// 撤销分数转移
+func (c *Client) handleUndoCommand(ctx context.Context, cmd *WebSocketCommand) {
+	var req struct {
+		TransferId int64 `json:"transfer_id"`
+	}
+	if err := json.Unmarshal(cmd.Data, &req); err != nil || req.TransferId <= 0 {
+		c.replyError(cmd.RequestID, 400, "Invalid command data")
+		return
+	}
+
+	response, err := c.service.VoidTransfer(ctx, &service.VoidTransferRequest{
+		RoomId:     c.roomID,
+		TransferId: req.TransferId,
+		UserId:     c.userID,
+	})
+	c.replyResponse(ctx, cmd.RequestID, response, err)
+}
+
+// 准备状态只在房间内广播，不落库
+func (c *Client) handleReadyCommand(ctx context.Context, cmd *WebSocketCommand) {
+	var req struct {
+		Ready bool `json:"ready"`
+	}
+	if err := json.Unmarshal(cmd.Data, &req); err != nil {
+		c.replyError(cmd.RequestID, 400, "Invalid command data")
+		return
+	}
+
+	c.hub.BroadcastToRoom(ctx, c.roomID, EventPlayerReady, map[string]interface{}{
+		"user_id": c.userID,
+		"ready":   req.Ready,
+	})
+	c.reply(&WebSocketMessage{Type: ReplyAck, RequestID: cmd.RequestID})
+}
+
+// 聊天消息只在房间内广播，不落库
+func (c *Client) handleChatCommand(ctx context.Context, cmd *WebSocketCommand) {
+	var req struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(cmd.Data, &req); err != nil {
+		c.replyError(cmd.RequestID, 400, "Invalid command data")
+		return
+	}
+	if req.Text == "" || utf8.RuneCountInString(req.Text) > maxChatLength {
+		c.replyError(cmd.RequestID, 400, "Invalid chat text")
+		return
+	}
+
+	c.hub.BroadcastToRoom(ctx, c.roomID, EventChat, map[string]interface{}{
+		"user_id": c.userID,
+		"text":    req.Text,
+	})
+	c.reply(&WebSocketMessage{Type: ReplyAck, RequestID: cmd.RequestID})
+}
+
+// replyResponse 将业务响应转换为ack或error
+func (c *Client) replyResponse(ctx context.Context, requestID string, response *service.Response, err error) {
+	if err != nil {
+		logger.ErrorContext(ctx, "WebSocket命令执行失败", "room_id", c.roomID, "user_id", c.userID, "command_request_id", requestID, "error", err.Error())
+		c.replyError(requestID, 500, "Internal server error")
+		return
+	}
+	if response.Code != 200 {
+		c.replyError(requestID, response.Code, response.Message)
+		return
+	}
+	c.reply(&WebSocketMessage{Type: ReplyAck, RequestID: requestID, Data: response})
+}
+
+func (c *Client) replyError(requestID string, code int32, message string) {
+	c.reply(&WebSocketMessage{
+		Type:      ReplyError,
+		RequestID: requestID,
+		Data:      &CommandError{Code: code, Message: message},
+	})
+}
+
+// reply 向当前客户端发送应答
+func (c *Client) reply(message *WebSocketMessage) {
+	message.RoomID = c.roomID
+	message.Timestamp = time.Now().Unix()
+	if !c.trySend(c.hub.marshalMessage(message)) {
+		logger.Warn("客户端发送队列已满或已关闭，应答被丢弃", "room_id", c.roomID, "user_id", c.userID, "type", message.Type)
+	}
+}
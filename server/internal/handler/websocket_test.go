@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gorilla/websocket"
+	"mahjong-server/internal/service"
+)
+
+// newTestWebSocketServer 使用sqlmock的WebSocket处理器
+func newTestWebSocketServer(t *testing.T) (*httptest.Server, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("创建sqlmock失败: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("SQL预期未满足: %v", err)
+		}
+		db.Close()
+	})
+	ws := NewWebSocketHandler(newTestHub(t), service.NewMahjongService(db, nil, nil), time.Second)
+	server := httptest.NewServer(http.HandlerFunc(ws.HandleWebSocket))
+	t.Cleanup(server.Close)
+	return server, mock
+}
+
+func expectSessionUser(mock sqlmock.Sqlmock, sessionID string, userID int64) {
+	mock.ExpectQuery("SELECT user_id FROM user_sessions").
+		WithArgs(sessionID).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(userID))
+}
+
+func expectRoomPlayer(mock sqlmock.Sqlmock, roomID, userID int64, count int) {
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM room_players").
+		WithArgs(roomID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+}
+
+func TestHandleWebSocketRejectsUnauthenticated(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		expect func(sqlmock.Sqlmock)
+		status int
+	}{
+		{"只有user_id", "room_id=3&user_id=7", func(sqlmock.Sqlmock) {}, http.StatusBadRequest},
+		{"登录态无效", "room_id=3&session_id=expired", func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery("SELECT user_id FROM user_sessions").
+				WithArgs("expired").
+				WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+		}, http.StatusUnauthorized},
+		{"未加入房间", "room_id=3&session_id=session", func(mock sqlmock.Sqlmock) {
+			expectSessionUser(mock, "session", 7)
+			expectRoomPlayer(mock, 3, 7, 0)
+		}, http.StatusForbidden},
+		{"user_id与登录用户不一致", "room_id=3&session_id=session&user_id=8", func(mock sqlmock.Sqlmock) {
+			expectSessionUser(mock, "session", 7)
+			expectRoomPlayer(mock, 3, 7, 1)
+		}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, mock := newTestWebSocketServer(t)
+			tt.expect(mock)
+
+			url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?" + tt.query
+			conn, resp, err := websocket.DefaultDialer.Dial(url, nil)
+			if err == nil {
+				conn.Close()
+				t.Fatal("未通过校验的连接不应升级")
+			}
+			if resp == nil || resp.StatusCode != tt.status {
+				t.Fatalf("响应 = %v，期望 %d", resp, tt.status)
+			}
+		})
+	}
+}
+
+func TestHandleWebSocketUsesSessionUser(t *testing.T) {
+	server, mock := newTestWebSocketServer(t)
+	expectSessionUser(mock, "session", 7)
+	expectRoomPlayer(mock, 3, 7, 1)
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?room_id=3&session_id=session"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("登录用户连接已加入的房间应成功: %v", err)
+	}
+	defer conn.Close()
+
+	// 连接的用户来自登录态，转给自己会被拒绝说明命令以用户7执行
+	if err := conn.WriteJSON(map[string]interface{}{
+		"type":       CommandTransfer,
+		"request_id": "1",
+		"data":       map[string]interface{}{"to_user_id": 7, "amount": 5},
+	}); err != nil {
+		t.Fatalf("发送命令失败: %v", err)
+	}
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		var message WebSocketMessage
+		if err := conn.ReadJSON(&message); err != nil {
+			t.Fatalf("读取应答失败: %v", err)
+		}
+		if message.RequestID != "1" {
+			continue
+		}
+		if message.Type != ReplyError {
+			t.Fatalf("转给自己应返回error，实际 %+v", message)
+		}
+		return
+	}
+}
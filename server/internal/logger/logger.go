@@ -1,14 +1,15 @@
 package logger
 
 import (
+	"context"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
-	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -23,22 +24,6 @@ const (
 	FATAL
 )
 
-// Logger 日志器结构
-type Logger struct {
-	debugLogger *log.Logger
-	infoLogger  *log.Logger
-	warnLogger  *log.Logger
-	errorLogger *log.Logger
-	fatalLogger *log.Logger
-	file        *os.File
-	level       LogLevel
-}
-
-var (
-	// 全局日志器实例
-	globalLogger *Logger
-)
-
 // 日志级别字符串映射
 var levelStrings = map[LogLevel]string{
 	DEBUG: "DEBUG",
@@ -48,117 +33,147 @@ var levelStrings = map[LogLevel]string{
 	FATAL: "FATAL",
 }
 
-// 日志级别颜色映射
-var levelColors = map[LogLevel]string{
-	DEBUG: "\033[36m", // 青色
-	INFO:  "\033[32m", // 绿色
-	WARN:  "\033[33m", // 黄色
-	ERROR: "\033[31m", // 红色
-	FATAL: "\033[35m", // 紫色
+func (l LogLevel) String() string {
+	if s, ok := levelStrings[l]; ok {
+		return s
+	}
+	return fmt.Sprintf("LEVEL(%d)", int(l))
 }
 
-const resetColor = "\033[0m"
-
-// cleanupOldLogs 清理旧日志文件，只保留最近3个
-func cleanupOldLogs(logDir string) error {
-	fmt.Printf("开始清理日志文件，目录: %s\n", logDir)
-	
-	// 读取日志目录中的所有文件
-	files, err := os.ReadDir(logDir)
-	if err != nil {
-		return err
+// ParseLevel 解析日志级别名称，不区分大小写
+func ParseLevel(name string) (LogLevel, error) {
+	for level, s := range levelStrings {
+		if strings.EqualFold(name, s) {
+			return level, nil
+		}
 	}
+	return INFO, fmt.Errorf("未知的日志级别: %s", name)
+}
 
-	fmt.Printf("找到 %d 个文件\n", len(files))
-
-	// 过滤出日志文件并按修改时间排序
-	var logFiles []os.FileInfo
-	for _, file := range files {
-		if !file.IsDir() && strings.HasPrefix(file.Name(), "log_") && strings.HasSuffix(file.Name(), ".log") {
-			info, err := file.Info()
-			if err != nil {
-				continue
-			}
-			logFiles = append(logFiles, info)
-		}
+// slogLevelFatal slog没有FATAL级别，取ERROR之上
+const slogLevelFatal = slog.LevelError + 4
+
+func (l LogLevel) slogLevel() slog.Level {
+	switch l {
+	case DEBUG:
+		return slog.LevelDebug
+	case WARN:
+		return slog.LevelWarn
+	case ERROR:
+		return slog.LevelError
+	case FATAL:
+		return slogLevelFatal
 	}
+	return slog.LevelInfo
+}
 
-	fmt.Printf("找到 %d 个日志文件\n", len(logFiles))
-
-	// 按修改时间排序（最新的在前）
-	sort.Slice(logFiles, func(i, j int) bool {
-		return logFiles[i].ModTime().After(logFiles[j].ModTime())
-	})
-
-	// 删除超过3个的旧日志文件
-	deletedCount := 0
-	for i := 3; i < len(logFiles); i++ {
-		oldLogPath := filepath.Join(logDir, logFiles[i].Name())
-		if err := os.Remove(oldLogPath); err != nil {
-			// 记录错误但不中断初始化
-			fmt.Printf("删除旧日志文件失败: %s, 错误: %v\n", oldLogPath, err)
-		} else {
-			fmt.Printf("已删除旧日志文件: %s\n", oldLogPath)
-			deletedCount++
-		}
+func fromSlogLevel(level slog.Level) LogLevel {
+	switch {
+	case level >= slogLevelFatal:
+		return FATAL
+	case level >= slog.LevelError:
+		return ERROR
+	case level >= slog.LevelWarn:
+		return WARN
+	case level >= slog.LevelInfo:
+		return INFO
 	}
+	return DEBUG
+}
 
-	fmt.Printf("清理完成，删除了 %d 个日志文件\n", deletedCount)
-	return nil
+// Format 日志输出格式
+type Format string
+
+const (
+	FormatText   Format = "text"   // 便于人工阅读的单行文本
+	FormatJSON   Format = "json"   // 每行一个JSON对象，便于日志系统采集
+	FormatLogfmt Format = "logfmt" // key=value形式
+)
+
+// sourceKey 调用位置字段名
+const sourceKey = slog.SourceKey
+
+// Logger 日志器，基于log/slog，级别可在运行时修改
+type Logger struct {
+	logger *slog.Logger
+	level  *slog.LevelVar
+	file   *RotatingFile
 }
 
-// InitLogger 初始化日志器
-func InitLogger(logDir string, level LogLevel) error {
+// 全局日志器实例
+var globalLogger atomic.Pointer[Logger]
+
+// logFileName 当前日志文件名，轮转后的文件见RotatingFile
+const logFileName = "server.log"
+
+// InitLogger 初始化日志器，日志同时写入logDir下的文件和标准输出，文件按rotate配置轮转
+func InitLogger(logDir string, level LogLevel, format Format, rotate RotateConfig) error {
 	// 创建日志目录
 	if err := os.MkdirAll(logDir, 0755); err != nil {
 		return fmt.Errorf("创建日志目录失败: %v", err)
 	}
 
-	// 清理旧日志文件，只保留最近3个
-	if err := cleanupOldLogs(logDir); err != nil {
-		return fmt.Errorf("清理旧日志文件失败: %v", err)
-	}
-
-	// 生成日志文件名
-	timestamp := time.Now().Format("20060102_150405")
-	logFileName := fmt.Sprintf("log_%s.log", timestamp)
 	logFilePath := filepath.Join(logDir, logFileName)
-
-	// 创建日志文件
-	file, err := os.OpenFile(logFilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	file, err := OpenRotatingFile(logFilePath, rotate)
 	if err != nil {
-		return fmt.Errorf("创建日志文件失败: %v", err)
+		return err
 	}
 
-	// 创建多输出写入器（同时输出到文件和控制台）
-	multiWriter := io.MultiWriter(file, os.Stdout)
-
-	// 创建日志器
-	logger := &Logger{
-		debugLogger: log.New(multiWriter, "", 0),
-		infoLogger:  log.New(multiWriter, "", 0),
-		warnLogger:  log.New(multiWriter, "", 0),
-		errorLogger: log.New(multiWriter, "", 0),
-		fatalLogger: log.New(multiWriter, "", 0),
-		file:        file,
-		level:       level,
+	// 同时输出到文件和控制台
+	logger, err := newLogger(io.MultiWriter(file, os.Stdout), level, format)
+	if err != nil {
+		file.Close()
+		return err
 	}
-
-	globalLogger = logger
+	logger.file = file
+	globalLogger.Store(logger)
 
 	// 记录日志器初始化信息
-	logger.Info("日志系统初始化完成", "log_file", logFilePath, "level", levelStrings[level])
+	logger.Info("日志系统初始化完成", "log_file", logFilePath, "level", level.String(), "format", string(format))
 
 	return nil
 }
 
-// GetLogger 获取全局日志器实例
+// newLogger 创建写入w的日志器
+func newLogger(w io.Writer, level LogLevel, format Format) (*Logger, error) {
+	levelVar := new(slog.LevelVar)
+	levelVar.Set(level.slogLevel())
+	options := &slog.HandlerOptions{Level: levelVar, ReplaceAttr: replaceLevel}
+
+	var handler slog.Handler
+	switch format {
+	case "", FormatText:
+		handler = newTextHandler(w, levelVar)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, options)
+	case FormatLogfmt:
+		handler = slog.NewTextHandler(w, options)
+	default:
+		return nil, fmt.Errorf("未知的日志格式: %s", format)
+	}
+	return &Logger{logger: slog.New(contextHandler{handler}), level: levelVar}, nil
+}
+
+// replaceLevel 级别输出为DEBUG/INFO/WARN/ERROR/FATAL，与文本格式一致
+func replaceLevel(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) == 0 && a.Key == slog.LevelKey {
+		if level, ok := a.Value.Any().(slog.Level); ok {
+			return slog.String(slog.LevelKey, fromSlogLevel(level).String())
+		}
+	}
+	return a
+}
+
+// GetLogger 获取全局日志器实例，未初始化时只输出到标准输出
 func GetLogger() *Logger {
-	if globalLogger == nil {
-		// 如果没有初始化，使用默认配置
-		InitLogger("./logs", INFO)
+	if logger := globalLogger.Load(); logger != nil {
+		return logger
+	}
+	logger, _ := newLogger(os.Stdout, INFO, FormatText)
+	if globalLogger.CompareAndSwap(nil, logger) {
+		return logger
 	}
-	return globalLogger
+	return globalLogger.Load()
 }
 
 // Close 关闭日志器
@@ -170,135 +185,133 @@ func (l *Logger) Close() error {
 	return nil
 }
 
-// getCallerInfo 获取调用者信息，跳过logger.go中的函数调用
-func (l *Logger) getCallerInfo() (pc uintptr, file string, line int, ok bool) {
-	// 从调用栈中查找第一个不在logger.go中的调用者
-	// 调用栈：实际调用 -> Debug/Info/Error等 -> log -> formatMessage -> getCallerInfo
-	// 所以我们需要跳过前4层
-	for i := 4; i < 10; i++ {
-		pc, file, line, ok = runtime.Caller(i)
-		if !ok {
-			break
-		}
-		// 检查文件名是否包含logger.go
-		if !strings.Contains(file, "logger.go") {
-			return pc, file, line, ok
-		}
+// Reopen 重新打开日志文件，用于外部工具移走文件后（SIGHUP）
+func (l *Logger) Reopen() error {
+	if l.file == nil {
+		return nil
 	}
-	return 0, "", 0, false
+	return l.file.Reopen()
 }
 
-// formatMessage 格式化日志消息
-func (l *Logger) formatMessage(level LogLevel, message string, fields ...interface{}) string {
-	// 获取调用者信息，跳过logger.go中的函数调用
-	_, file, line, ok := l.getCallerInfo()
-	if !ok {
-		file = "unknown"
-		line = 0
-	} else {
-		// 只保留文件名，不包含路径
-		file = filepath.Base(file)
-	}
+// SetLevel 修改日志级别，立即生效
+func (l *Logger) SetLevel(level LogLevel) {
+	l.level.Set(level.slogLevel())
+}
+
+// Level 返回当前日志级别
+func (l *Logger) Level() LogLevel {
+	return fromSlogLevel(l.level.Level())
+}
 
-	// 构建时间戳
-	timestamp := time.Now().Format("2006-01-02 15:04:05.000")
-
-	// 构建基础消息
-	baseMsg := fmt.Sprintf("[%s] [%s] [%s:%d] %s",
-		timestamp,
-		levelStrings[level],
-		file,
-		line,
-		message)
-
-	// 添加字段信息
-	if len(fields) > 0 {
-		var fieldStrs []string
-		for i := 0; i < len(fields); i += 2 {
-			if i+1 < len(fields) {
-				fieldStrs = append(fieldStrs, fmt.Sprintf("%v=%v", fields[i], fields[i+1]))
-			}
+// callerSource 返回logger包之外的第一个调用位置（文件名:行号）
+func callerSource() string {
+	var pcs [10]uintptr
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasSuffix(frame.File, "/logger/logger.go") {
+			return fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.Line)
 		}
-		if len(fieldStrs) > 0 {
-			baseMsg += " | " + strings.Join(fieldStrs, " ")
+		if !more {
+			return "unknown:0"
 		}
 	}
-
-	return baseMsg
 }
 
-// log 通用日志方法
-func (l *Logger) log(level LogLevel, message string, fields ...interface{}) {
-	if level < l.level {
+// log 通用日志方法，fields为交替的键和值，按字段名脱敏后输出
+func (l *Logger) log(ctx context.Context, level LogLevel, message string, fields ...interface{}) {
+	slogLevel := level.slogLevel()
+	if !l.logger.Enabled(ctx, slogLevel) {
 		return
 	}
-
-	formattedMsg := l.formatMessage(level, message, fields...)
-
-	// 根据级别选择对应的日志器
-	switch level {
-	case DEBUG:
-		l.debugLogger.Println(formattedMsg)
-	case INFO:
-		l.infoLogger.Println(formattedMsg)
-	case WARN:
-		l.warnLogger.Println(formattedMsg)
-	case ERROR:
-		l.errorLogger.Println(formattedMsg)
-	case FATAL:
-		l.fatalLogger.Println(formattedMsg)
-	}
+	record := slog.NewRecord(time.Now(), slogLevel, message, 0)
+	record.AddAttrs(slog.String(sourceKey, callerSource()))
+	record.Add(RedactFields(fields)...)
+	l.logger.Handler().Handle(ctx, record)
 }
 
 // Debug 调试日志
 func (l *Logger) Debug(message string, fields ...interface{}) {
-	l.log(DEBUG, message, fields...)
+	l.log(context.Background(), DEBUG, message, fields...)
 }
 
 // Info 信息日志
 func (l *Logger) Info(message string, fields ...interface{}) {
-	l.log(INFO, message, fields...)
+	l.log(context.Background(), INFO, message, fields...)
 }
 
 // Warn 警告日志
 func (l *Logger) Warn(message string, fields ...interface{}) {
-	l.log(WARN, message, fields...)
+	l.log(context.Background(), WARN, message, fields...)
 }
 
 // Error 错误日志
 func (l *Logger) Error(message string, fields ...interface{}) {
-	l.log(ERROR, message, fields...)
+	l.log(context.Background(), ERROR, message, fields...)
 }
 
 // Fatal 致命错误日志
 func (l *Logger) Fatal(message string, fields ...interface{}) {
-	l.log(FATAL, message, fields...)
+	l.log(context.Background(), FATAL, message, fields...)
 	os.Exit(1)
 }
 
 // 全局日志函数
 func Debug(message string, fields ...interface{}) {
-	GetLogger().Debug(message, fields...)
+	GetLogger().log(context.Background(), DEBUG, message, fields...)
 }
 
 func Info(message string, fields ...interface{}) {
-	GetLogger().Info(message, fields...)
+	GetLogger().log(context.Background(), INFO, message, fields...)
 }
 
 func Warn(message string, fields ...interface{}) {
-	GetLogger().Warn(message, fields...)
+	GetLogger().log(context.Background(), WARN, message, fields...)
 }
 
 func Error(message string, fields ...interface{}) {
-	GetLogger().Error(message, fields...)
+	GetLogger().log(context.Background(), ERROR, message, fields...)
 }
 
 func Fatal(message string, fields ...interface{}) {
 	GetLogger().Fatal(message, fields...)
 }
 
+// 带ctx的全局日志函数，ctx中的请求ID会附加到日志中
+func DebugContext(ctx context.Context, message string, fields ...interface{}) {
+	GetLogger().log(ctx, DEBUG, message, fields...)
+}
+
+func InfoContext(ctx context.Context, message string, fields ...interface{}) {
+	GetLogger().log(ctx, INFO, message, fields...)
+}
+
+func WarnContext(ctx context.Context, message string, fields ...interface{}) {
+	GetLogger().log(ctx, WARN, message, fields...)
+}
+
+func ErrorContext(ctx context.Context, message string, fields ...interface{}) {
+	GetLogger().log(ctx, ERROR, message, fields...)
+}
+
+// SetLevel 修改全局日志级别
+func SetLevel(level LogLevel) {
+	GetLogger().SetLevel(level)
+}
+
+// Reopen 重新打开全局日志器的文件
+func Reopen() error {
+	return GetLogger().Reopen()
+}
+
+// GetLevel 返回全局日志级别
+func GetLevel() LogLevel {
+	return GetLogger().Level()
+}
+
 // LogHTTPRequest 记录HTTP请求日志
-func LogHTTPRequest(method, path, remoteAddr string, statusCode int, duration time.Duration, fields ...interface{}) {
+func LogHTTPRequest(ctx context.Context, method, path, remoteAddr string, statusCode int, duration time.Duration, fields ...interface{}) {
 	allFields := []interface{}{
 		"method", method,
 		"path", path,
@@ -307,37 +320,37 @@ func LogHTTPRequest(method, path, remoteAddr string, statusCode int, duration ti
 		"duration_ms", duration.Milliseconds(),
 	}
 	allFields = append(allFields, fields...)
-	
+
 	if statusCode >= 400 {
-		GetLogger().Error("HTTP请求错误", allFields...)
+		GetLogger().log(ctx, ERROR, "HTTP请求错误", allFields...)
 	} else {
-		GetLogger().Info("HTTP请求", allFields...)
+		GetLogger().log(ctx, INFO, "HTTP请求", allFields...)
 	}
 }
 
 // LogDatabase 记录数据库操作日志
-func LogDatabase(operation, table string, duration time.Duration, err error, fields ...interface{}) {
+func LogDatabase(ctx context.Context, operation, table string, duration time.Duration, err error, fields ...interface{}) {
 	allFields := []interface{}{
 		"operation", operation,
 		"table", table,
 		"duration_ms", duration.Milliseconds(),
 	}
 	allFields = append(allFields, fields...)
-	
+
 	if err != nil {
 		allFields = append(allFields, "error", err.Error())
-		GetLogger().Error("数据库操作失败", allFields...)
+		GetLogger().log(ctx, ERROR, "数据库操作失败", allFields...)
 	} else {
-		GetLogger().Debug("数据库操作成功", allFields...)
+		GetLogger().log(ctx, DEBUG, "数据库操作成功", allFields...)
 	}
 }
 
 // LogBusiness 记录业务逻辑日志
-func LogBusiness(operation string, userID interface{}, fields ...interface{}) {
+func LogBusiness(ctx context.Context, operation string, userID interface{}, fields ...interface{}) {
 	allFields := []interface{}{
 		"operation", operation,
 		"user_id", userID,
 	}
 	allFields = append(allFields, fields...)
-	GetLogger().Info("业务操作", allFields...)
+	GetLogger().log(ctx, INFO, "业务操作", allFields...)
 }
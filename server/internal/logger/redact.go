@@ -0,0 +1,218 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// redactAction 敏感字段的处理方式
+type redactAction int
+
+const (
+	redactMask     redactAction = iota + 1 // 保留前4个字符，如 ab12****
+	redactHash                             // 替换为SHA-256前16位，同一用户的日志仍可关联
+	redactDrop                             // 整个字段不输出
+	redactMaskName                         // 保留首字符，如 张***
+)
+
+// redactRules 按字段名（忽略大小写、下划线和连字符）匹配的脱敏规则，只作用于字符串值，
+// 因此响应中数字类型的code等字段不受影响
+var redactRules = map[string]redactAction{
+	"sessionid":     redactMask,
+	"sessionkey":    redactDrop,
+	"accesstoken":   redactDrop,
+	"appsecret":     redactDrop,
+	"secret":        redactDrop,
+	"password":      redactDrop,
+	"token":         redactMask,
+	"appid":         redactMask,
+	"openid":        redactHash,
+	"unionid":       redactHash,
+	"code":          redactDrop, // wx.login的code
+	"jscode":        redactDrop,
+	"encrypteddata": redactDrop,
+	"iv":            redactDrop,
+	"nickname":      redactMaskName,
+	"avatarurl":     redactDrop,
+	"phone":         redactMask,
+	"phonenumber":   redactMask,
+}
+
+func normalizeFieldName(key string) string {
+	key = strings.ToLower(key)
+	return strings.NewReplacer("_", "", "-", "").Replace(key)
+}
+
+// redactString 按字段名处理字符串值，keep为false表示该字段应丢弃
+func redactString(key, value string) (redacted string, keep bool) {
+	action, ok := redactRules[normalizeFieldName(key)]
+	if !ok || value == "" {
+		return value, true
+	}
+	switch action {
+	case redactMask:
+		// 按字符截取，避免截断多字节字符产生无效的UTF-8
+		runes := []rune(value)
+		if len(runes) <= 8 {
+			return "****", true
+		}
+		return string(runes[:4]) + "****", true
+	case redactHash:
+		sum := sha256.Sum256([]byte(value))
+		return "sha256:" + hex.EncodeToString(sum[:8]), true
+	case redactMaskName:
+		first, _ := utf8.DecodeRuneInString(value)
+		return string(first) + "***", true
+	case redactDrop:
+		return "", false
+	}
+	return value, true
+}
+
+// RedactFields 对交替的键值对脱敏，返回新的切片，不修改fields
+func RedactFields(fields []interface{}) []interface{} {
+	var result []interface{}
+	for i := 0; i+1 < len(fields); i += 2 {
+		key, isKey := fields[i].(string)
+		value, isString := fields[i+1].(string)
+		if !isKey || !isString {
+			if result != nil {
+				result = append(result, fields[i], fields[i+1])
+			}
+			continue
+		}
+		redacted, keep := redactString(key, value)
+		if result == nil && (!keep || redacted != value) {
+			// 第一次需要修改时才复制，大多数日志没有敏感字段
+			result = append(make([]interface{}, 0, len(fields)), fields[:i]...)
+		}
+		if result != nil && keep {
+			result = append(result, key, redacted)
+		}
+	}
+	if result == nil {
+		return fields
+	}
+	if len(fields)%2 == 1 {
+		result = append(result, fields[len(fields)-1])
+	}
+	return result
+}
+
+// RedactJSON 对JSON请求体或响应体脱敏，字符串形式的嵌套JSON（如响应的data字段）同样处理；
+// 非JSON内容只记录长度
+func RedactJSON(body []byte) string {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return ""
+	}
+	if !json.Valid(trimmed) {
+		return fmt.Sprintf("[非JSON内容 %d 字节]", len(body))
+	}
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Sprintf("[非JSON内容 %d 字节]", len(body))
+	}
+	data, err := marshalJSON(redactJSONValue(value))
+	if err != nil {
+		return fmt.Sprintf("[非JSON内容 %d 字节]", len(body))
+	}
+	return data
+}
+
+func redactJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if s, ok := item.(string); ok {
+				if nested, ok := redactNestedJSON(s); ok {
+					v[key] = nested
+					continue
+				}
+				redacted, keep := redactString(key, s)
+				if !keep {
+					delete(v, key)
+					continue
+				}
+				v[key] = redacted
+				continue
+			}
+			v[key] = redactJSONValue(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactJSONValue(item)
+		}
+	}
+	return value
+}
+
+// redactNestedJSON 字符串本身是JSON对象或数组时脱敏后重新编码
+func redactNestedJSON(s string) (string, bool) {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" || (trimmed[0] != '{' && trimmed[0] != '[') || !json.Valid([]byte(trimmed)) {
+		return "", false
+	}
+	decoder := json.NewDecoder(strings.NewReader(trimmed))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return "", false
+	}
+	data, err := marshalJSON(redactJSONValue(value))
+	if err != nil {
+		return "", false
+	}
+	return data, true
+}
+
+// marshalJSON 编码为JSON，不转义<>&以便阅读
+func marshalJSON(value interface{}) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// RedactQuery 对URL查询参数脱敏
+func RedactQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return fmt.Sprintf("[无法解析的查询参数 %d 字节]", len(rawQuery))
+	}
+	changed := false
+	for key, items := range values {
+		var kept []string
+		for _, item := range items {
+			redacted, keep := redactString(key, item)
+			if keep {
+				kept = append(kept, redacted)
+			}
+			changed = changed || !keep || redacted != item
+		}
+		if len(kept) == 0 {
+			delete(values, key)
+		} else {
+			values[key] = kept
+		}
+	}
+	if !changed {
+		return rawQuery
+	}
+	// 掩码中的*不转义，便于阅读
+	return strings.ReplaceAll(values.Encode(), "%2A", "*")
+}
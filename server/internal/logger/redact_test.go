@@ -0,0 +1,178 @@
+package logger
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+
+func TestRedactString(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		want  string
+		keep  bool
+	}{
+		{"无规则字段原样保留", "room_id", "123456", "123456", true},
+		{"空值原样保留", "session_id", "", "", true},
+		{"短值整体掩码", "session_id", "abcd1234", "****", true},
+		{"长值保留前4个字符", "session_id", "abcd1234efgh", "abcd****", true},
+		{"字段名忽略大小写和分隔符", "Session-Id", "abcd1234efgh", "abcd****", true},
+		{"手机号", "phoneNumber", "13800138000", "1380****", true},
+		{"多字节字符按字符截取", "token", "令牌密钥一二三四五", "令牌密钥****", true},
+		{"多字节短值整体掩码", "token", "令牌密钥一二三四", "****", true},
+		{"昵称保留首字", "nickName", "张三丰", "张***", true},
+		{"昵称首字为emoji", "nickname", "🀄麻将", "🀄***", true},
+		{"丢弃session_key", "session_key", "xyz", "", false},
+		{"丢弃wx.login的code", "code", "081abc", "", false},
+		{"丢弃头像地址", "avatarUrl", "https://example.com/a.png", "", false},
+	}
+	for _, tt := range tests {
+		got, keep := redactString(tt.key, tt.value)
+		if got != tt.want || keep != tt.keep {
+			t.Errorf("%s: redactString(%q, %q) = %q, %v，期望 %q, %v", tt.name, tt.key, tt.value, got, keep, tt.want, tt.keep)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("%s: 脱敏结果不是有效的UTF-8: %q", tt.name, got)
+		}
+	}
+}
+
+func TestRedactMaskNeverSplitsRunes(t *testing.T) {
+	// 前4个字节落在多字节字符中间的各种组合
+	for _, value := range []string{"a中文字符测试数据", "ab中文字符测试数据", "abc中文字符测试数据", "é́é́é́é́é́", "😀😀😀😀😀😀😀😀😀"} {
+		got, _ := redactString("session_id", value)
+		if !utf8.ValidString(got) {
+			t.Errorf("redactMask(%q) = %q，截断了多字节字符", value, got)
+		}
+		if !strings.HasPrefix(value, strings.TrimSuffix(got, "****")) {
+			t.Errorf("redactMask(%q) = %q，前缀与原值不符", value, got)
+		}
+	}
+}
+
+func TestRedactHashIsStable(t *testing.T) {
+	first, _ := redactString("openid", "oABC123")
+	second, _ := redactString("open_id", "oABC123")
+	other, _ := redactString("openid", "oXYZ789")
+	if !strings.HasPrefix(first, "sha256:") || len(first) != len("sha256:")+16 {
+		t.Fatalf("openid应替换为SHA-256前缀，实际 %q", first)
+	}
+	if first != second || first == other {
+		t.Fatalf("同一openid的哈希应一致、不同openid应不同: %q %q %q", first, second, other)
+	}
+}
+
+func TestRedactFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []interface{}
+		want   []interface{}
+	}{
+		{
+			name:   "没有敏感字段时原样返回",
+			fields: []interface{}{"room_id", int64(1), "path", "/api/v1/getRoom"},
+			want:   []interface{}{"room_id", int64(1), "path", "/api/v1/getRoom"},
+		},
+		{
+			name:   "掩码并丢弃字段",
+			fields: []interface{}{"user_id", int64(9), "session_id", "abcd1234efgh", "session_key", "secret", "nickname", "李四"},
+			want:   []interface{}{"user_id", int64(9), "session_id", "abcd****", "nickname", "李***"},
+		},
+		{
+			name:   "非字符串值不处理",
+			fields: []interface{}{"code", 200, "phone", 13800138000},
+			want:   []interface{}{"code", 200, "phone", 13800138000},
+		},
+		{
+			name:   "奇数个参数保留末尾的值",
+			fields: []interface{}{"token", "abcd1234efgh", "dangling"},
+			want:   []interface{}{"token", "abcd****", "dangling"},
+		},
+		{
+			name:   "敏感字段之后的字段照常复制",
+			fields: []interface{}{"code", "081abc", "count", 3, "phone", "13800138000"},
+			want:   []interface{}{"count", 3, "phone", "1380****"},
+		},
+	}
+	for _, tt := range tests {
+		original := append([]interface{}(nil), tt.fields...)
+		got := RedactFields(tt.fields)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: RedactFields = %v，期望 %v", tt.name, got, tt.want)
+		}
+		if !reflect.DeepEqual(tt.fields, original) {
+			t.Errorf("%s: RedactFields修改了传入的切片", tt.name)
+		}
+	}
+}
+
+func TestRedactJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"空内容", "  ", ""},
+		{"非JSON只记录长度", "code=abc", "[非JSON内容 8 字节]"},
+		{
+			"请求体中的code和session",
+			`{"code":"081abc","session_id":"abcd1234efgh","room_id":12}`,
+			`{"room_id":12,"session_id":"abcd****"}`,
+		},
+		{
+			"数字类型的code不受影响",
+			`{"code":200,"message":"成功"}`,
+			`{"code":200,"message":"成功"}`,
+		},
+		{
+			"字符串形式的嵌套data",
+			`{"code":200,"data":"{\"session_id\":\"abcd1234efgh\",\"user\":{\"openid\":\"oABC123\",\"nickname\":\"王五\",\"avatar_url\":\"https://x/a.png\"}}"}`,
+			`{"code":200,"data":"{\"session_id\":\"abcd****\",\"user\":{\"nickname\":\"王***\",\"openid\":\"` + hashOf("oABC123") + `\"}}"}`,
+		},
+		{
+			"数组中的对象",
+			`[{"token":"abcd1234efgh"},{"phone":"13800138000"}]`,
+			`[{"token":"abcd****"},{"phone":"1380****"}]`,
+		},
+		{
+			"大整数不丢精度，<>&不转义",
+			`{"transfer_id":9007199254740993,"note":"<a&b>"}`,
+			`{"note":"<a&b>","transfer_id":9007199254740993}`,
+		},
+	}
+	for _, tt := range tests {
+		if got := RedactJSON([]byte(tt.body)); got != tt.want {
+			t.Errorf("%s:\nRedactJSON = %s\n期望        %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRedactQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"空查询", "", ""},
+		{"没有敏感参数时原样返回", "room_id=1&b=2", "room_id=1&b=2"},
+		{"掩码session_id", "session_id=abcd1234efgh&room_id=1", "room_id=1&session_id=abcd****"},
+		{"丢弃code", "code=081abc&room_id=1", "room_id=1"},
+		{"重复参数逐个处理", "token=abcd1234efgh&token=short", "token=abcd****&token=****"},
+		{"中文值按字符掩码", "token=" + "%E4%BB%A4%E7%89%8C%E5%AF%86%E9%92%A5%E4%B8%80%E4%BA%8C%E4%B8%89%E5%9B%9B%E4%BA%94", "token=%E4%BB%A4%E7%89%8C%E5%AF%86%E9%92%A5****"},
+		{"无法解析", "a=%zz", "[无法解析的查询参数 5 字节]"},
+	}
+	for _, tt := range tests {
+		if got := RedactQuery(tt.query); got != tt.want {
+			t.Errorf("%s: RedactQuery(%q) = %q，期望 %q", tt.name, tt.query, got, tt.want)
+		}
+	}
+}
+
+func hashOf(value string) string {
+	redacted, _ := redactString("openid", value)
+	return redacted
+}
@@ -0,0 +1,316 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotateConfig 日志轮转配置
+type RotateConfig struct {
+	MaxSize    int64         // 单个文件的最大字节数，超过后轮转，0表示不按大小轮转
+	MaxAge     time.Duration // 轮转文件的保留时长，0表示不按时长清理
+	MaxBackups int           // 最多保留的轮转文件数，0表示不限
+	Compress   bool          // 轮转后gzip压缩
+}
+
+// backupTimeFormat 轮转文件名中的时间，精确到毫秒以免同一秒内多次按大小轮转时重名
+const backupTimeFormat = "20060102-150405.000"
+
+// RotatingFile 按大小和日期轮转的日志文件
+// 当前文件名固定（如server.log），轮转时重命名为 server-20060102-150405.000.log，
+// 再在后台压缩并按保留策略清理
+type RotatingFile struct {
+	mu     sync.Mutex
+	path   string
+	config RotateConfig
+	file   *os.File
+	size   int64
+	day    string // 当前文件开始写入的日期，日期变化时轮转
+	closed bool
+
+	mill     chan struct{} // 通知后台协程压缩和清理
+	millDone chan struct{}
+}
+
+// OpenRotatingFile 打开（或创建）日志文件，已存在时追加写入
+func OpenRotatingFile(path string, config RotateConfig) (*RotatingFile, error) {
+	f := &RotatingFile{
+		path:     path,
+		config:   config,
+		mill:     make(chan struct{}, 1),
+		millDone: make(chan struct{}),
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	go f.runMill()
+	// 启动时清理一次上次运行留下的轮转文件
+	f.mill <- struct{}{}
+	return f, nil
+}
+
+// open 打开当前路径的文件，调用方持有锁或尚未共享
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("打开日志文件失败: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("读取日志文件信息失败: %v", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	// 沿用已有文件时以其最后修改日期为准，跨天重启后第一次写入即轮转
+	f.day = info.ModTime().Format("20060102")
+	if info.Size() == 0 {
+		f.day = time.Now().Format("20060102")
+	}
+	return nil
+}
+
+// Write 写入日志，超过大小或日期变化时先轮转
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	// 上次轮转或重新打开失败时再试一次
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	now := time.Now()
+	if f.size > 0 && (now.Format("20060102") != f.day ||
+		(f.config.MaxSize > 0 && f.size+int64(len(p)) > f.config.MaxSize)) {
+		if err := f.rotate(now); err != nil {
+			// 轮转失败时继续写入原文件，不丢日志
+			fmt.Fprintf(os.Stderr, "日志轮转失败: %v\n", err)
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate 立即轮转
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return os.ErrClosed
+	}
+	if f.file == nil {
+		return f.open()
+	}
+	return f.rotate(time.Now())
+}
+
+// rotate 将当前文件重命名为带时间的轮转文件并打开新文件，调用方持有锁
+func (f *RotatingFile) rotate(now time.Time) error {
+	backup := f.backupPath(now)
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+	renameErr := os.Rename(f.path, backup)
+	// 重命名失败也要重新打开，保证后续日志可写
+	if err := f.open(); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		return renameErr
+	}
+
+	select {
+	case f.mill <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Reopen 关闭并重新打开当前路径的文件，用于外部工具（如logrotate）移走文件后收到SIGHUP时
+func (f *RotatingFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return os.ErrClosed
+	}
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+	return f.open()
+}
+
+// Close 关闭文件并停止后台协程
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	close(f.mill)
+	<-f.millDone
+	return err
+}
+
+// backupPath 生成轮转文件名，重名时追加序号
+func (f *RotatingFile) backupPath(now time.Time) string {
+	prefix, ext := f.nameParts()
+	base := filepath.Join(filepath.Dir(f.path), prefix+now.Format(backupTimeFormat))
+	candidate := base + ext
+	for i := 1; fileExists(candidate) || fileExists(candidate+".gz"); i++ {
+		candidate = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+	return candidate
+}
+
+// nameParts 返回轮转文件名的前缀和扩展名，如 server.log 对应 "server-" 和 ".log"
+func (f *RotatingFile) nameParts() (string, string) {
+	name := filepath.Base(f.path)
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "-", ext
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// runMill 后台压缩轮转文件并按保留策略清理，避免在写日志时阻塞
+func (f *RotatingFile) runMill() {
+	defer close(f.millDone)
+	for range f.mill {
+		if err := f.millOnce(); err != nil {
+			fmt.Fprintf(os.Stderr, "日志清理失败: %v\n", err)
+		}
+	}
+}
+
+type backupFile struct {
+	path    string
+	modTime time.Time
+}
+
+func (f *RotatingFile) millOnce() error {
+	backups, err := f.listBackups()
+	if err != nil {
+		return err
+	}
+
+	// 按修改时间从新到旧，超出数量或时长的删除
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].modTime.After(backups[j].modTime)
+	})
+	var keep []backupFile
+	for i, backup := range backups {
+		expired := f.config.MaxAge > 0 && time.Since(backup.modTime) > f.config.MaxAge
+		if expired || (f.config.MaxBackups > 0 && i >= f.config.MaxBackups) {
+			if err := os.Remove(backup.path); err != nil && !os.IsNotExist(err) {
+				fmt.Fprintf(os.Stderr, "删除旧日志文件失败: %s, 错误: %v\n", backup.path, err)
+			}
+			continue
+		}
+		keep = append(keep, backup)
+	}
+
+	if !f.config.Compress {
+		return nil
+	}
+	for _, backup := range keep {
+		if strings.HasSuffix(backup.path, ".gz") {
+			continue
+		}
+		if err := compressFile(backup.path, backup.modTime); err != nil {
+			fmt.Fprintf(os.Stderr, "压缩日志文件失败: %s, 错误: %v\n", backup.path, err)
+		}
+	}
+	return nil
+}
+
+// listBackups 列出轮转文件，也包括旧版本按启动时间命名的 log_*.log
+func (f *RotatingFile) listBackups() ([]backupFile, error) {
+	dir := filepath.Dir(f.path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, ext := f.nameParts()
+	var backups []backupFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == filepath.Base(f.path) {
+			continue
+		}
+		isBackup := strings.HasPrefix(name, prefix) &&
+			(strings.HasSuffix(name, ext) || strings.HasSuffix(name, ext+".gz"))
+		isLegacy := strings.HasPrefix(name, "log_") && (strings.HasSuffix(name, ".log") || strings.HasSuffix(name, ".log.gz"))
+		if !isBackup && !isLegacy {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupFile{path: filepath.Join(dir, name), modTime: info.ModTime()})
+	}
+	return backups, nil
+}
+
+// compressFile 将文件压缩为.gz并删除原文件，保留原修改时间以便按时间清理
+func compressFile(path string, modTime time.Time) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmpPath := path + ".gz.tmp"
+	dst, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(tmpPath)
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		dst.Close()
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpPath, path+".gz"); err != nil {
+		return err
+	}
+	os.Chtimes(path+".gz", modTime, modTime)
+	return os.Remove(path)
+}
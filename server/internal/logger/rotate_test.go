@@ -0,0 +1,224 @@
+package logger
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// backupNames 目录中除当前文件外的文件名，按名称排序
+func backupNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("读取目录失败: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.Name() != "server.log" {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("读取%s失败: %v", path, err)
+	}
+	return string(data)
+}
+
+// writeBackup 创建指定修改时间的文件
+func writeBackup(t *testing.T, path, content string, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("创建%s失败: %v", path, err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("修改%s时间失败: %v", path, err)
+	}
+}
+
+func mustWrite(t *testing.T, f *RotatingFile, text string) {
+	t.Helper()
+	if _, err := f.Write([]byte(text)); err != nil {
+		t.Fatalf("写入日志失败: %v", err)
+	}
+}
+
+func TestRotatingFileRotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "server.log")
+	f, err := OpenRotatingFile(path, RotateConfig{MaxSize: 10})
+	if err != nil {
+		t.Fatalf("打开日志文件失败: %v", err)
+	}
+	mustWrite(t, f, "first\n")
+	// 6+7超过10字节，写入前先轮转
+	mustWrite(t, f, "second\n")
+	// 超过大小的单条日志写入空文件时不轮转
+	mustWrite(t, f, "0123456789abcdef\n")
+	f.Close()
+
+	names := backupNames(t, dir)
+	if len(names) != 2 {
+		t.Fatalf("应有2个轮转文件，实际 %v", names)
+	}
+	for _, name := range names {
+		if !strings.HasPrefix(name, "server-") || !strings.HasSuffix(name, ".log") {
+			t.Errorf("轮转文件名 %s 不符合 server-时间.log", name)
+		}
+	}
+	// 同一毫秒内的两次轮转以序号区分，文件名顺序不代表先后
+	contents := []string{readFile(t, filepath.Join(dir, names[0])), readFile(t, filepath.Join(dir, names[1]))}
+	sort.Strings(contents)
+	if strings.Join(contents, "") != "first\nsecond\n" {
+		t.Errorf("轮转文件内容 = %q", contents)
+	}
+	if got := readFile(t, path); got != "0123456789abcdef\n" {
+		t.Errorf("当前文件内容 = %q", got)
+	}
+}
+
+func TestRotatingFileRotatesByDay(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "server.log")
+	// 上次运行在昨天写入的文件
+	writeBackup(t, path, "yesterday\n", time.Now().AddDate(0, 0, -1))
+
+	f, err := OpenRotatingFile(path, RotateConfig{})
+	if err != nil {
+		t.Fatalf("打开日志文件失败: %v", err)
+	}
+	mustWrite(t, f, "today\n")
+	mustWrite(t, f, "again\n")
+	f.Close()
+
+	names := backupNames(t, dir)
+	if len(names) != 1 {
+		t.Fatalf("跨天后应轮转一次，实际 %v", names)
+	}
+	if got := readFile(t, filepath.Join(dir, names[0])); got != "yesterday\n" {
+		t.Errorf("轮转文件内容 = %q", got)
+	}
+	if got := readFile(t, path); got != "today\nagain\n" {
+		t.Errorf("当前文件内容 = %q", got)
+	}
+}
+
+func TestRotatingFileCompresses(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "server.log")
+	f, err := OpenRotatingFile(path, RotateConfig{Compress: true})
+	if err != nil {
+		t.Fatalf("打开日志文件失败: %v", err)
+	}
+	mustWrite(t, f, "compressed\n")
+	if err := f.Rotate(); err != nil {
+		t.Fatalf("轮转失败: %v", err)
+	}
+	// Close等待后台压缩完成
+	f.Close()
+
+	names := backupNames(t, dir)
+	if len(names) != 1 || !strings.HasSuffix(names[0], ".log.gz") {
+		t.Fatalf("应只留下压缩后的轮转文件，实际 %v", names)
+	}
+	file, err := os.Open(filepath.Join(dir, names[0]))
+	if err != nil {
+		t.Fatalf("打开压缩文件失败: %v", err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("解压失败: %v", err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil || string(data) != "compressed\n" {
+		t.Fatalf("解压内容 = %q, %v", data, err)
+	}
+}
+
+func TestRotatingFilePrunesBackups(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	// 按修改时间从新到旧
+	writeBackup(t, filepath.Join(dir, "server-20240105-000000.000.log"), "1", now.Add(-1*time.Hour))
+	writeBackup(t, filepath.Join(dir, "server-20240104-000000.000.log.gz"), "2", now.Add(-2*time.Hour))
+	writeBackup(t, filepath.Join(dir, "log_20240103.log"), "3", now.Add(-3*time.Hour))
+	writeBackup(t, filepath.Join(dir, "server-20240102-000000.000.log"), "4", now.Add(-4*time.Hour))
+	// 超过保留时长
+	writeBackup(t, filepath.Join(dir, "server-20231201-000000.000.log"), "5", now.Add(-60*24*time.Hour))
+	// 不是轮转文件，不清理
+	writeBackup(t, filepath.Join(dir, "other.txt"), "6", now.Add(-60*24*time.Hour))
+
+	// 启动时清理上次运行留下的轮转文件
+	f, err := OpenRotatingFile(filepath.Join(dir, "server.log"), RotateConfig{MaxAge: 30 * 24 * time.Hour, MaxBackups: 3})
+	if err != nil {
+		t.Fatalf("打开日志文件失败: %v", err)
+	}
+	f.Close()
+
+	want := []string{"log_20240103.log", "other.txt", "server-20240104-000000.000.log.gz", "server-20240105-000000.000.log"}
+	if got := backupNames(t, dir); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("清理后的文件 = %v，期望 %v", got, want)
+	}
+}
+
+func TestRotatingFileReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "server.log")
+	f, err := OpenRotatingFile(path, RotateConfig{})
+	if err != nil {
+		t.Fatalf("打开日志文件失败: %v", err)
+	}
+	defer f.Close()
+	mustWrite(t, f, "before\n")
+
+	// 外部工具移走文件后，重新打开前的日志仍写入被移走的文件
+	moved := filepath.Join(dir, "server.log.1")
+	if err := os.Rename(path, moved); err != nil {
+		t.Fatalf("移动日志文件失败: %v", err)
+	}
+	mustWrite(t, f, "moved\n")
+	if err := f.Reopen(); err != nil {
+		t.Fatalf("重新打开失败: %v", err)
+	}
+	mustWrite(t, f, "after\n")
+
+	if got := readFile(t, moved); got != "before\nmoved\n" {
+		t.Errorf("被移走的文件内容 = %q", got)
+	}
+	if got := readFile(t, path); got != "after\n" {
+		t.Errorf("重新打开后的文件内容 = %q", got)
+	}
+}
+
+func TestRotatingFileClosed(t *testing.T) {
+	f, err := OpenRotatingFile(filepath.Join(t.TempDir(), "server.log"), RotateConfig{})
+	if err != nil {
+		t.Fatalf("打开日志文件失败: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("关闭失败: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Errorf("重复关闭应返回nil: %v", err)
+	}
+	if _, err := f.Write([]byte("x")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("关闭后写入应返回os.ErrClosed，实际 %v", err)
+	}
+	if err := f.Reopen(); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("关闭后重新打开应返回os.ErrClosed，实际 %v", err)
+	}
+}
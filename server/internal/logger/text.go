@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// textHandler 输出便于人工阅读的单行文本：
+// [2006-01-02 15:04:05.000] [INFO] [file.go:123] 消息 | key=value key=value
+type textHandler struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	level  slog.Leveler
+	fields []string // WithAttrs添加的字段，已格式化
+	group  string
+}
+
+func newTextHandler(w io.Writer, level slog.Leveler) *textHandler {
+	return &textHandler{mu: &sync.Mutex{}, w: w, level: level}
+}
+
+func (h *textHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *textHandler) Handle(_ context.Context, record slog.Record) error {
+	source := "unknown:0"
+	fields := append([]string(nil), h.fields...)
+	record.Attrs(func(attr slog.Attr) bool {
+		if attr.Key == sourceKey {
+			source = attr.Value.String()
+			return true
+		}
+		fields = appendField(fields, h.group, attr)
+		return true
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] [%s] [%s] %s",
+		record.Time.Format("2006-01-02 15:04:05.000"),
+		fromSlogLevel(record.Level),
+		source,
+		record.Message)
+	if len(fields) > 0 {
+		b.WriteString(" | ")
+		b.WriteString(strings.Join(fields, " "))
+	}
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.fields = append([]string(nil), h.fields...)
+	for _, attr := range attrs {
+		clone.fields = appendField(clone.fields, h.group, attr)
+	}
+	return &clone
+}
+
+func (h *textHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.group = joinKey(h.group, name)
+	return &clone
+}
+
+// appendField 将属性格式化为key=value，分组属性展开为group.key=value
+func appendField(fields []string, group string, attr slog.Attr) []string {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+	key := joinKey(group, attr.Key)
+	if attr.Value.Kind() == slog.KindGroup {
+		for _, member := range attr.Value.Group() {
+			fields = appendField(fields, key, member)
+		}
+		return fields
+	}
+	return append(fields, fmt.Sprintf("%s=%v", key, attr.Value.Any()))
+}
+
+func joinKey(group, key string) string {
+	if group == "" {
+		return key
+	}
+	if key == "" {
+		return group
+	}
+	return group + "." + key
+}
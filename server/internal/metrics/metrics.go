@@ -0,0 +1,88 @@
+// Package metrics 基于prometheus/client_golang的指标注册，
+// 保留按标签值调用的简化接口，业务代码不直接依赖Prometheus的类型
+package metrics
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DefaultBuckets 默认的耗时直方图分桶（秒）
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Default 默认注册表，New*函数创建的指标都注册在这里，另含Go运行时和进程指标
+// 不使用prometheus.DefaultRegisterer，避免依赖库注册的指标混入
+var Default = newRegistry()
+
+func newRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return registry
+}
+
+// Handler 返回默认注册表的HTTP处理器
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Default, promhttp.HandlerOpts{})
+}
+
+// CounterVec 带标签的计数器
+type CounterVec struct {
+	vec *prometheus.CounterVec
+}
+
+// NewCounterVec 创建并注册计数器，名称重复时panic
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	vec := prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, labels)
+	Default.MustRegister(vec)
+	return &CounterVec{vec: vec}
+}
+
+// Inc 计数加一
+func (c *CounterVec) Inc(values ...string) {
+	c.vec.WithLabelValues(values...).Inc()
+}
+
+// Add 计数增加delta，delta不能为负
+func (c *CounterVec) Add(delta float64, values ...string) {
+	c.vec.WithLabelValues(values...).Add(delta)
+}
+
+// HistogramVec 带标签的直方图
+type HistogramVec struct {
+	vec *prometheus.HistogramVec
+}
+
+// NewHistogramVec 创建并注册直方图，buckets为空时使用DefaultBuckets
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	// client_golang要求分桶升序，这里排序后再传入
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	vec := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: name, Help: help, Buckets: sorted}, labels)
+	Default.MustRegister(vec)
+	return &HistogramVec{vec: vec}
+}
+
+// Observe 记录一次观测值
+func (h *HistogramVec) Observe(value float64, values ...string) {
+	h.vec.WithLabelValues(values...).Observe(value)
+}
+
+// NewGaugeFunc 注册一个在输出时取值的gauge
+func NewGaugeFunc(name, help string, fn func() float64) {
+	Default.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: name, Help: help}, fn))
+}
+
+// NewCounterFunc 注册一个在输出时取值的counter，fn返回的值应单调递增
+func NewCounterFunc(name, help string, fn func() float64) {
+	Default.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{Name: name, Help: help}, fn))
+}
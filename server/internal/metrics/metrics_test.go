@@ -0,0 +1,160 @@
+package metrics
+
+import (
+	"math"
+	"net/http/httptest"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// scrape 请求Handler并解析文本格式的输出
+func scrape(t *testing.T) map[string]*dto.MetricFamily {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if recorder.Code != 200 {
+		t.Fatalf("/metrics 返回 %d", recorder.Code)
+	}
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(recorder.Body)
+	if err != nil {
+		t.Fatalf("解析指标输出失败: %v\n%s", err, recorder.Body.String())
+	}
+	return families
+}
+
+func labelValue(metric *dto.Metric, name string) string {
+	for _, label := range metric.GetLabel() {
+		if label.GetName() == name {
+			return label.GetValue()
+		}
+	}
+	return ""
+}
+
+func TestCounterVecLabelEscaping(t *testing.T) {
+	counter := NewCounterVec("test_escaped_total", "帮助文本\\含反斜杠\n和换行", "path")
+	values := []string{`/api/"quoted"`, "a\\b", "line\nbreak", "中文"}
+	for i, value := range values {
+		counter.Add(float64(i+1), value)
+	}
+
+	family := scrape(t)["test_escaped_total"]
+	if family == nil {
+		t.Fatal("未输出test_escaped_total")
+	}
+	if family.GetType() != dto.MetricType_COUNTER {
+		t.Fatalf("类型 = %v", family.GetType())
+	}
+	if family.GetHelp() != "帮助文本\\含反斜杠\n和换行" {
+		t.Fatalf("帮助文本 = %q", family.GetHelp())
+	}
+	got := make(map[string]float64)
+	for _, metric := range family.GetMetric() {
+		got[labelValue(metric, "path")] = metric.GetCounter().GetValue()
+	}
+	for i, value := range values {
+		if got[value] != float64(i+1) {
+			t.Errorf("标签 %q 的值 = %v，期望 %d", value, got[value], i+1)
+		}
+	}
+}
+
+func TestHistogramVecBuckets(t *testing.T) {
+	histogram := NewHistogramVec("test_duration_seconds", "耗时", []float64{1, 0.1, 0.5}, "route")
+	for _, value := range []float64{0.05, 0.1, 0.3, 0.7, 2} {
+		histogram.Observe(value, "a")
+	}
+	histogram.Observe(0.2, "b")
+
+	family := scrape(t)["test_duration_seconds"]
+	if family == nil || family.GetType() != dto.MetricType_HISTOGRAM {
+		t.Fatalf("未输出直方图: %v", family)
+	}
+	var series *dto.Histogram
+	for _, metric := range family.GetMetric() {
+		if labelValue(metric, "route") == "a" {
+			series = metric.GetHistogram()
+		}
+	}
+	if series == nil {
+		t.Fatal("缺少route=a的序列")
+	}
+	if series.GetSampleCount() != 5 || series.GetSampleSum() != 3.15 {
+		t.Fatalf("count = %d, sum = %v", series.GetSampleCount(), series.GetSampleSum())
+	}
+
+	// 分桶按上界排序并累计计数，边界值计入le等于它的桶，+Inf桶等于总数
+	want := []struct {
+		le    float64
+		count uint64
+	}{{0.1, 2}, {0.5, 3}, {1, 4}, {math.Inf(1), 5}}
+	buckets := series.GetBucket()
+	if len(buckets) != len(want) {
+		t.Fatalf("分桶数 = %d", len(buckets))
+	}
+	for i, bucket := range buckets {
+		if bucket.GetUpperBound() != want[i].le || bucket.GetCumulativeCount() != want[i].count {
+			t.Errorf("le=%v 的累计计数 = %d，期望 le=%v %d", bucket.GetUpperBound(), bucket.GetCumulativeCount(), want[i].le, want[i].count)
+		}
+	}
+}
+
+func TestHistogramVecDefaultBuckets(t *testing.T) {
+	histogram := NewHistogramVec("test_default_buckets_seconds", "耗时", nil)
+	histogram.Observe(0.003)
+
+	family := scrape(t)["test_default_buckets_seconds"]
+	buckets := family.GetMetric()[0].GetHistogram().GetBucket()
+	want := append(append([]float64(nil), DefaultBuckets...), math.Inf(1))
+	if len(buckets) != len(want) {
+		t.Fatalf("分桶数 = %d，期望 %d", len(buckets), len(want))
+	}
+	for i, bucket := range buckets {
+		if bucket.GetUpperBound() != want[i] || bucket.GetCumulativeCount() != 1 {
+			t.Errorf("分桶 %d = le %v 计数 %d", i, bucket.GetUpperBound(), bucket.GetCumulativeCount())
+		}
+	}
+}
+
+func TestFuncMetrics(t *testing.T) {
+	value := 1.0
+	NewGaugeFunc("test_gauge", "gauge", func() float64 { return value })
+	NewCounterFunc("test_counter_total", "counter", func() float64 { return value * 10 })
+
+	value = 3
+	families := scrape(t)
+	if got := families["test_gauge"].GetMetric()[0].GetGauge().GetValue(); got != 3 {
+		t.Errorf("gauge = %v，应在输出时取值", got)
+	}
+	if families["test_gauge"].GetType() != dto.MetricType_GAUGE {
+		t.Errorf("test_gauge类型 = %v", families["test_gauge"].GetType())
+	}
+	if got := families["test_counter_total"].GetMetric()[0].GetCounter().GetValue(); got != 30 {
+		t.Errorf("counter = %v", got)
+	}
+	if families["go_goroutines"] == nil {
+		t.Error("默认注册表应包含Go运行时指标")
+	}
+}
+
+func TestRegisterPanics(t *testing.T) {
+	NewCounterVec("test_duplicate_total", "重复", "a")
+	assertPanics(t, "重复注册", func() { NewCounterVec("test_duplicate_total", "重复", "a") })
+
+	counter := NewCounterVec("test_label_count_total", "标签数量", "a", "b")
+	assertPanics(t, "标签值数量不符", func() { counter.Inc("only-one") })
+	assertPanics(t, "计数减少", func() { counter.Add(-1, "x", "y") })
+}
+
+func assertPanics(t *testing.T, name string, fn func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("%s时应panic", name)
+		}
+	}()
+	fn()
+}
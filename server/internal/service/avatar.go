@@ -0,0 +1,341 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // 注册GIF解码
+	"image/jpeg"
+	_ "image/png" // 注册PNG解码
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"mahjong-server/internal/logger"
+	"mahjong-server/internal/storage"
+)
+
+const (
+	// MaxAvatarSize 上传头像的最大字节数
+	MaxAvatarSize = 2 << 20
+	// maxAvatarSourceSide 原图宽高上限，避免解码超大图片占用过多内存
+	maxAvatarSourceSide = 2048
+	// avatarSide 保存的头像边长，原图居中裁剪为正方形后缩小到该尺寸
+	avatarSide = 256
+	// avatarJPEGQuality 保存头像的JPEG质量
+	avatarJPEGQuality = 85
+	// avatarUploadURLExpiry 预签名上传地址的有效期
+	avatarUploadURLExpiry = 10 * time.Minute
+	// avatarUploadRetention 上传后未确认的原图保留时间，超过后由定期清理删除
+	avatarUploadRetention = time.Hour
+	// avatarUploadCleanupInterval 清理未确认原图的间隔
+	avatarUploadCleanupInterval = time.Hour
+)
+
+// 允许上传的头像格式
+var avatarContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+var (
+	ErrSessionInvalid     = errors.New("登录态无效或已过期")
+	ErrAvatarTooLarge     = fmt.Errorf("头像不能超过%dMB", MaxAvatarSize>>20)
+	ErrAvatarFormat       = errors.New("头像只支持JPEG、PNG、GIF格式")
+	ErrAvatarDimensions   = fmt.Errorf("头像宽高不能超过%d像素", maxAvatarSourceSide)
+	ErrPresignUnsupported = errors.New("当前存储不支持预签名上传，请使用uploadAvatar接口")
+)
+
+// SetStorage 设置头像等文件的存储
+func (s *MahjongService) SetStorage(store storage.Storage) {
+	s.storage = store
+}
+
+// userIDBySession 由登录时下发的session_id查询用户，过期或不存在时返回ErrSessionInvalid
+func (s *MahjongService) userIDBySession(ctx context.Context, sessionID string) (int64, error) {
+	if sessionID == "" {
+		return 0, ErrSessionInvalid
+	}
+	var userID int64
+	err := s.db.QueryRowContext(ctx, `
+		SELECT user_id FROM user_sessions WHERE session_id = ? AND expires_at > NOW()
+	`, sessionID).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return 0, ErrSessionInvalid
+	}
+	if err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
+
+// UploadAvatar 校验并处理上传的头像图片，保存后更新用户的avatar_url
+func (s *MahjongService) UploadAvatar(ctx context.Context, sessionID string, data []byte) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "MahjongService.UploadAvatar", trace.WithAttributes(attribute.Int("size", len(data))))
+	defer span.End()
+
+	userID, err := s.userIDBySession(ctx, sessionID)
+	if errors.Is(err, ErrSessionInvalid) {
+		return &Response{Code: 401, Message: err.Error()}, nil
+	}
+	if err != nil {
+		return failed(ctx, "查询登录态失败"), nil
+	}
+	return s.saveAvatar(ctx, userID, data)
+}
+
+// GetAvatarUploadURL 生成预签名上传地址，客户端PUT上传原图后调用ConfirmAvatarUpload
+func (s *MahjongService) GetAvatarUploadURL(ctx context.Context, req *AvatarUploadURLRequest) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "MahjongService.GetAvatarUploadURL")
+	defer span.End()
+
+	presigner, ok := s.storage.(storage.Presigner)
+	if !ok {
+		return &Response{Code: 400, Message: ErrPresignUnsupported.Error()}, nil
+	}
+	userID, err := s.userIDBySession(ctx, req.SessionID)
+	if errors.Is(err, ErrSessionInvalid) {
+		return &Response{Code: 401, Message: err.Error()}, nil
+	}
+	if err != nil {
+		return failed(ctx, "查询登录态失败"), nil
+	}
+
+	var b [8]byte
+	rand.Read(b[:])
+	key := avatarUploadPrefix(userID) + hex.EncodeToString(b[:])
+	uploadURL, err := presigner.PresignPut(key, avatarUploadURLExpiry)
+	if err != nil {
+		return failed(ctx, "生成上传地址失败: "+err.Error()), nil
+	}
+
+	data, _ := json.Marshal(AvatarUploadURLResponse{
+		UploadURL: uploadURL,
+		Method:    http.MethodPut,
+		Key:       key,
+		MaxSize:   MaxAvatarSize,
+		ExpiresAt: time.Now().Add(avatarUploadURLExpiry).Unix(),
+	})
+	return &Response{Code: 200, Message: "获取成功", Data: string(data)}, nil
+}
+
+// ConfirmAvatarUpload 读取客户端通过预签名地址上传的原图，按UploadAvatar的规则处理后删除原图
+func (s *MahjongService) ConfirmAvatarUpload(ctx context.Context, req *ConfirmAvatarUploadRequest) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "MahjongService.ConfirmAvatarUpload")
+	defer span.End()
+
+	userID, err := s.userIDBySession(ctx, req.SessionID)
+	if errors.Is(err, ErrSessionInvalid) {
+		return &Response{Code: 401, Message: err.Error()}, nil
+	}
+	if err != nil {
+		return failed(ctx, "查询登录态失败"), nil
+	}
+	// 只能确认自己的上传
+	if !strings.HasPrefix(req.Key, avatarUploadPrefix(userID)) {
+		return &Response{Code: 403, Message: "无权访问该文件"}, nil
+	}
+
+	// 预签名地址不限制上传大小，读取时按头像上限截断，不把大文件读入内存
+	object, err := s.storage.GetLimited(ctx, req.Key, MaxAvatarSize)
+	if errors.Is(err, storage.ErrNotFound) {
+		return &Response{Code: 404, Message: "上传的文件不存在"}, nil
+	}
+	if errors.Is(err, storage.ErrTooLarge) {
+		if err := s.storage.Delete(ctx, req.Key); err != nil {
+			logger.WarnContext(ctx, "删除头像原图失败", "key", req.Key, "error", err.Error())
+		}
+		return &Response{Code: 400, Message: ErrAvatarTooLarge.Error()}, nil
+	}
+	if err != nil {
+		return failed(ctx, "读取上传文件失败: "+err.Error()), nil
+	}
+	defer func() {
+		if err := s.storage.Delete(ctx, req.Key); err != nil {
+			logger.WarnContext(ctx, "删除头像原图失败", "key", req.Key, "error", err.Error())
+		}
+	}()
+	return s.saveAvatar(ctx, userID, object.Data)
+}
+
+// isStoredAvatarURL 地址是否为本服务保存的头像，未配置存储时不做限制
+func (s *MahjongService) isStoredAvatarURL(avatarURL string) bool {
+	if s.storage == nil {
+		return true
+	}
+	return strings.HasPrefix(avatarURL, s.storage.URL("avatars")+"/")
+}
+
+// avatarUploadRoot 预签名上传的原图目录
+const avatarUploadRoot = "uploads/avatars/"
+
+func avatarUploadPrefix(userID int64) string {
+	return fmt.Sprintf(avatarUploadRoot+"%d/", userID)
+}
+
+// CleanupAvatarUploads 删除修改时间早于olderThan之前、仍未确认的头像原图，返回删除的数量
+// 客户端上传后未调用confirmAvatarUpload时原图会一直留在存储中
+func CleanupAvatarUploads(ctx context.Context, store storage.Storage, olderThan time.Duration) (int, error) {
+	lister, ok := store.(storage.Lister)
+	if !ok {
+		return 0, nil
+	}
+	objects, err := lister.List(ctx, avatarUploadRoot)
+	if err != nil {
+		return 0, err
+	}
+	cutoff := time.Now().Add(-olderThan)
+	deleted := 0
+	for _, object := range objects {
+		if !object.ModTime.Before(cutoff) {
+			continue
+		}
+		if err := store.Delete(ctx, object.Key); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// StartAvatarUploadCleanup 启动时和之后每隔avatarUploadCleanupInterval清理一次未确认的原图，返回停止函数
+// 多实例同时清理时删除是幂等的
+func StartAvatarUploadCleanup(store storage.Storage) func() {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(avatarUploadCleanupInterval)
+		defer ticker.Stop()
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			deleted, err := CleanupAvatarUploads(ctx, store, avatarUploadRetention)
+			cancel()
+			if err != nil {
+				logger.Warn("清理未确认的头像原图失败", "deleted", deleted, "error", err.Error())
+			} else if deleted > 0 {
+				logger.Info("已清理未确认的头像原图", "deleted", deleted)
+			}
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(stop)
+			<-done
+		})
+	}
+}
+
+// saveAvatar 处理图片并保存，key包含内容哈希，头像变化时地址随之变化，便于客户端长期缓存
+func (s *MahjongService) saveAvatar(ctx context.Context, userID int64, data []byte) (*Response, error) {
+	avatar, err := processAvatar(data)
+	if err != nil {
+		return &Response{Code: 400, Message: err.Error()}, nil
+	}
+
+	sum := sha256.Sum256(avatar)
+	key := fmt.Sprintf("avatars/%d/%s.jpg", userID, hex.EncodeToString(sum[:8]))
+	if err := s.storage.Put(ctx, key, avatar, "image/jpeg"); err != nil {
+		return failed(ctx, "保存头像失败: "+err.Error()), nil
+	}
+
+	avatarURL := s.storage.URL(key)
+	_, err = s.db.ExecContext(ctx, `UPDATE users SET avatar_url = ?, updated_at = NOW() WHERE id = ?`, avatarURL, userID)
+	if err != nil {
+		return failed(ctx, "更新用户头像失败"), nil
+	}
+	logger.InfoContext(ctx, "头像已更新", "user_id", userID, "key", key, "size", len(avatar))
+
+	responseData, _ := json.Marshal(map[string]interface{}{"avatar_url": avatarURL})
+	return &Response{Code: 200, Message: "上传成功", Data: string(responseData)}, nil
+}
+
+// processAvatar 校验大小、格式和尺寸，居中裁剪为正方形并缩小到avatarSide，重新编码为JPEG
+// 重新编码同时去掉了EXIF等元数据
+func processAvatar(data []byte) ([]byte, error) {
+	if len(data) > MaxAvatarSize {
+		return nil, ErrAvatarTooLarge
+	}
+	if !avatarContentTypes[http.DetectContentType(data)] {
+		return nil, ErrAvatarFormat
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrAvatarFormat
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width > maxAvatarSourceSide || config.Height > maxAvatarSourceSide {
+		return nil, ErrAvatarDimensions
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrAvatarFormat
+	}
+
+	// 居中裁剪，透明部分铺白底
+	bounds := src.Bounds()
+	side := bounds.Dx()
+	if bounds.Dy() < side {
+		side = bounds.Dy()
+	}
+	origin := image.Pt(bounds.Min.X+(bounds.Dx()-side)/2, bounds.Min.Y+(bounds.Dy()-side)/2)
+	square := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(square, square.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(square, square.Bounds(), src, origin, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, shrink(square, avatarSide), &jpeg.Options{Quality: avatarJPEGQuality}); err != nil {
+		return nil, fmt.Errorf("头像编码失败: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// shrink 将正方形图片按区域平均缩小到size，原图不大于size时原样返回
+func shrink(src *image.RGBA, size int) *image.RGBA {
+	side := src.Bounds().Dx()
+	if side <= size {
+		return src
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	for dy := 0; dy < size; dy++ {
+		y0, y1 := dy*side/size, (dy+1)*side/size
+		for dx := 0; dx < size; dx++ {
+			x0, x1 := dx*side/size, (dx+1)*side/size
+			var r, g, b, a, n int
+			for y := y0; y < y1; y++ {
+				row := src.Pix[y*src.Stride+x0*4 : y*src.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					r += int(row[i])
+					g += int(row[i+1])
+					b += int(row[i+2])
+					a += int(row[i+3])
+					n++
+				}
+			}
+			i := dst.PixOffset(dx, dy)
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"mahjong-server/internal/storage"
+)
+
+func newTestStorage(t *testing.T) (*storage.Local, string) {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := storage.NewLocal(dir, "https://example.com/api/v1/files")
+	if err != nil {
+		t.Fatalf("创建本地存储失败: %v", err)
+	}
+	return store, dir
+}
+
+func TestConfirmAvatarUploadRejectsLargeObject(t *testing.T) {
+	service, mock := newMockService(t, nil)
+	store, _ := newTestStorage(t)
+	service.SetStorage(store)
+
+	ctx := context.Background()
+	key := avatarUploadPrefix(42) + "large"
+	if err := store.Put(ctx, key, bytes.Repeat([]byte{0xff}, MaxAvatarSize+1), ""); err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectQuery("SELECT user_id FROM user_sessions").
+		WithArgs("session").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(42))
+
+	resp, err := service.ConfirmAvatarUpload(ctx, &ConfirmAvatarUploadRequest{SessionID: "session", Key: key})
+	if err != nil {
+		t.Fatalf("ConfirmAvatarUpload返回错误: %v", err)
+	}
+	if resp.Code != 400 || resp.Message != ErrAvatarTooLarge.Error() {
+		t.Fatalf("超过大小上限应返回400，实际 %d %s", resp.Code, resp.Message)
+	}
+	if _, err := store.Get(ctx, key); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("超过上限的原图应被删除，Get返回 %v", err)
+	}
+}
+
+func TestCleanupAvatarUploads(t *testing.T) {
+	store, dir := newTestStorage(t)
+	ctx := context.Background()
+	keys := []string{avatarUploadPrefix(1) + "old", avatarUploadPrefix(1) + "new", "avatars/1/kept.jpg"}
+	for _, key := range keys {
+		if err := store.Put(ctx, key, []byte("data"), ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-2 * avatarUploadRetention)
+	for _, key := range []string{keys[0], keys[2]} {
+		if err := os.Chtimes(filepath.Join(dir, filepath.FromSlash(key)), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deleted, err := CleanupAvatarUploads(ctx, store, avatarUploadRetention)
+	if err != nil || deleted != 1 {
+		t.Fatalf("CleanupAvatarUploads = %d, %v，期望删除1个", deleted, err)
+	}
+	if _, err := store.Get(ctx, keys[0]); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("过期的原图未删除: %v", err)
+	}
+	// 未过期的原图和已保存的头像不受影响
+	for _, key := range keys[1:] {
+		if _, err := store.Get(ctx, key); err != nil {
+			t.Errorf("%s不应删除: %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"mahjong-server/internal/logger"
+)
+
+// textField 需要校验的用户输入，昵称和房间名对房间内所有人可见
+type textField struct {
+	name       string
+	maxRunes   int
+	required   bool
+	allowEmoji bool   // 不允许时去掉emoji而不是拒绝
+	fallback   string // 打码模式下整段违规时的替换内容
+}
+
+var (
+	fieldNickname = &textField{name: "昵称", maxRunes: 20, required: true, allowEmoji: true, fallback: "微信用户"}
+	// 房间名会出现在订阅消息的thing字段中，该字段不支持emoji，长度上限也是20
+	fieldRoomName = &textField{name: "房间名", maxRunes: 20, fallback: ""}
+)
+
+// TextError 用户输入不符合要求，Message可直接提示给用户
+type TextError struct {
+	Message string
+}
+
+func (e *TextError) Error() string {
+	return e.Message
+}
+
+// defaultSensitiveKeywords 内置的敏感词，与CONTENT_KEYWORDS_FILE中的词一起使用
+var defaultSensitiveKeywords = []string{
+	"赌博", "赌钱", "赌资", "押注", "下注", "代充", "返水", "博彩", "网赌",
+	"色情", "约炮", "裸聊", "毒品", "冰毒", "大麻", "枪支", "办证", "刷单", "套现",
+	"加微信", "加qq", "加vx", "薇信",
+}
+
+// ContentCheckerOptions 内容安全检测配置
+type ContentCheckerOptions struct {
+	Mask        bool     // 命中时打码，否则拒绝
+	WeChatCheck bool     // 调用微信msg_sec_check，失败时只使用本地词库
+	Keywords    []string // 追加的本地敏感词
+}
+
+// ContentChecker 昵称、房间名等用户输入的内容安全检测：先按本地词库匹配，再可选调用微信msg_sec_check
+type ContentChecker struct {
+	wechat   *WeChatService
+	opts     ContentCheckerOptions
+	keywords [][]rune // 归一化后的敏感词
+}
+
+// NewContentChecker 创建内容安全检测
+func NewContentChecker(wechat *WeChatService, opts ContentCheckerOptions) *ContentChecker {
+	c := &ContentChecker{wechat: wechat, opts: opts}
+	seen := make(map[string]bool)
+	for _, keyword := range append(append([]string(nil), defaultSensitiveKeywords...), opts.Keywords...) {
+		normalized, _ := normalizeForMatch([]rune(keyword))
+		if len(normalized) == 0 || seen[string(normalized)] {
+			continue
+		}
+		seen[string(normalized)] = true
+		c.keywords = append(c.keywords, normalized)
+	}
+	return c
+}
+
+// LoadKeywords 读取敏感词文件，每行一个词，空行和#开头的行忽略
+func LoadKeywords(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取敏感词文件失败: %v", err)
+	}
+	defer file.Close()
+
+	var keywords []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keywords = append(keywords, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取敏感词文件失败: %v", err)
+	}
+	return keywords, nil
+}
+
+// Check 校验并检测文本，返回清理（或打码）后的内容；不符合要求时返回*TextError
+// openID为空时不调用微信接口
+func (c *ContentChecker) Check(ctx context.Context, field *textField, text, openID string) (string, error) {
+	cleaned, err := normalizeText(field, text)
+	if err != nil || cleaned == "" {
+		return cleaned, err
+	}
+
+	if spans := c.matchKeywords(cleaned); len(spans) > 0 {
+		if !c.opts.Mask {
+			return "", &TextError{Message: field.name + "包含不允许的内容"}
+		}
+		cleaned = maskSpans(cleaned, spans)
+		logger.InfoContext(ctx, "输入命中本地敏感词，已打码", "field", field.name)
+	}
+
+	if !c.opts.WeChatCheck || openID == "" {
+		return cleaned, nil
+	}
+	result, err := c.wechat.MsgSecCheck(ctx, openID, cleaned, SecSceneProfile)
+	if err != nil {
+		// 微信接口不可用时不阻塞用户操作，本地词库已检测过
+		logger.WarnContext(ctx, "内容安全检测失败，仅使用本地词库", "field", field.name, "error", err.Error())
+		return cleaned, nil
+	}
+	if !result.Risky() {
+		return cleaned, nil
+	}
+	logger.InfoContext(ctx, "输入未通过内容安全检测", "field", field.name, "label", result.Label, "mask", c.opts.Mask)
+	if !c.opts.Mask {
+		return "", &TextError{Message: field.name + "包含不允许的内容"}
+	}
+	var spans [][2]int
+	for _, keyword := range result.Keywords {
+		spans = append(spans, findSpans([]rune(cleaned), keyword)...)
+	}
+	if len(spans) == 0 {
+		// 没有可定位的关键词，整段替换
+		return field.fallback, nil
+	}
+	return maskSpans(cleaned, spans), nil
+}
+
+// normalizeText 去掉首尾空白、合并连续空白，按字段规则处理emoji并检查字符和长度
+func normalizeText(field *textField, text string) (string, error) {
+	if !utf8.ValidString(text) {
+		return "", &TextError{Message: field.name + "包含无效字符"}
+	}
+	var b strings.Builder
+	space := false
+	for _, r := range strings.TrimSpace(text) {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if isEmojiRune(r) {
+			if !field.allowEmoji {
+				continue
+			}
+		} else if !isAllowedRune(r) {
+			return "", &TextError{Message: field.name + "包含不支持的字符"}
+		}
+		if space {
+			b.WriteRune(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	cleaned := strings.TrimSpace(b.String())
+
+	if cleaned == "" && field.required {
+		return "", &TextError{Message: field.name + "不能为空"}
+	}
+	if utf8.RuneCountInString(cleaned) > field.maxRunes {
+		return "", &TextError{Message: fmt.Sprintf("%s不能超过%d个字", field.name, field.maxRunes)}
+	}
+	return cleaned, nil
+}
+
+// isAllowedRune 文字、数字、标点、符号和组合附加符号，拒绝控制字符、格式字符（如零宽字符）和私用区字符
+func isAllowedRune(r rune) bool {
+	return unicode.In(r, unicode.L, unicode.M, unicode.N, unicode.P, unicode.S)
+}
+
+// isEmojiRune emoji及其组成部分（肤色、零宽连接符、变体选择符、键帽、旗帜标签）
+func isEmojiRune(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF: // 表情、符号、旗帜、肤色
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // 杂项符号和装饰符号
+		return true
+	case r >= 0x2B00 && r <= 0x2BFF: // ⭐等箭头和符号
+		return true
+	case r >= 0xE0020 && r <= 0xE007F: // 旗帜标签
+		return true
+	case r == 0x200D || r == 0xFE0F || r == 0x20E3:
+		return true
+	}
+	return false
+}
+
+// StripEmoji 去掉emoji，用于不支持emoji的场景（如订阅消息的thing字段）
+func StripEmoji(text string) string {
+	return strings.Map(func(r rune) rune {
+		if isEmojiRune(r) {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// normalizeForMatch 归一化用于匹配：全角转半角、转小写、去掉文字和数字以外的字符，
+// 返回归一化后的字符及其在原文中的位置，以便打码时映射回原文
+func normalizeForMatch(text []rune) ([]rune, []int) {
+	normalized := make([]rune, 0, len(text))
+	positions := make([]int, 0, len(text))
+	for i, r := range text {
+		if r >= 0xFF01 && r <= 0xFF5E {
+			r -= 0xFEE0
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			continue
+		}
+		normalized = append(normalized, unicode.ToLower(r))
+		positions = append(positions, i)
+	}
+	return normalized, positions
+}
+
+// matchKeywords 返回命中的本地敏感词在原文中的位置（按rune计，左闭右开）
+func (c *ContentChecker) matchKeywords(text string) [][2]int {
+	runes := []rune(text)
+	normalized, positions := normalizeForMatch(runes)
+	var spans [][2]int
+	for _, keyword := range c.keywords {
+		for _, span := range indexAll(normalized, keyword) {
+			spans = append(spans, [2]int{positions[span[0]], positions[span[1]-1] + 1})
+		}
+	}
+	return spans
+}
+
+// findSpans 查找微信返回的关键词在原文中的位置
+func findSpans(text []rune, keyword string) [][2]int {
+	normalized, positions := normalizeForMatch(text)
+	target, _ := normalizeForMatch([]rune(keyword))
+	var spans [][2]int
+	for _, span := range indexAll(normalized, target) {
+		spans = append(spans, [2]int{positions[span[0]], positions[span[1]-1] + 1})
+	}
+	return spans
+}
+
+// indexAll 返回target在text中所有出现的位置
+func indexAll(text, target []rune) [][2]int {
+	if len(target) == 0 {
+		return nil
+	}
+	var spans [][2]int
+	for i := 0; i+len(target) <= len(text); i++ {
+		if string(text[i:i+len(target)]) == string(target) {
+			spans = append(spans, [2]int{i, i + len(target)})
+		}
+	}
+	return spans
+}
+
+// maskSpans 将指定位置的字符替换为*
+func maskSpans(text string, spans [][2]int) string {
+	runes := []rune(text)
+	for _, span := range spans {
+		for i := span[0]; i < span[1]; i++ {
+			runes[i] = '*'
+		}
+	}
+	return string(runes)
+}
+
+// SetContentChecker 设置昵称、房间名的内容安全检测，未设置时只做格式校验
+func (s *MahjongService) SetContentChecker(checker *ContentChecker) {
+	s.content = checker
+}
+
+// checkText 校验用户输入，微信检测需要用户的openid
+func (s *MahjongService) checkText(ctx context.Context, field *textField, text string, userID int64) (string, error) {
+	if s.content == nil {
+		return normalizeText(field, text)
+	}
+	var openID string
+	if s.content.opts.WeChatCheck {
+		var err error
+		openID, err = s.currentOpenID(ctx, userID)
+		if err != nil {
+			logger.WarnContext(ctx, "查询openid失败，跳过微信内容安全检测", "user_id", userID, "error", err.Error())
+		}
+	}
+	return s.content.Check(ctx, field, text, openID)
+}
+
+// textErrorResponse checkText出错时的响应：*TextError直接提示给用户，其他错误按服务端错误处理
+func textErrorResponse(ctx context.Context, err error) *Response {
+	var textErr *TextError
+	if errors.As(err, &textErr) {
+		return &Response{Code: 400, Message: textErr.Message}
+	}
+	logger.ErrorContext(ctx, "检测用户输入失败", "error", err.Error())
+	return failed(ctx, "内容检测失败")
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"mahjong-server/internal/wechatfake"
+)
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		name  string
+		field *textField
+		text  string
+		want  string
+		err   string // 期望的TextError消息，为空表示成功
+	}{
+		{"去掉首尾空白并合并空白", fieldNickname, "  小\t 王 \n ", "小 王", ""},
+		{"昵称保留emoji", fieldNickname, "小王🀄️", "小王🀄️", ""},
+		{"房间名去掉emoji", fieldRoomName, "周五🀄麻将局🎉", "周五麻将局", ""},
+		{"房间名只有emoji时为空", fieldRoomName, "🀄🎉", "", ""},
+		{"昵称不能为空", fieldNickname, "   ", "", "昵称不能为空"},
+		{"零宽字符", fieldNickname, "小​王", "", "昵称包含不支持的字符"},
+		{"控制字符", fieldRoomName, "房间\x07", "", "房间名包含不支持的字符"},
+		{"无效UTF-8", fieldNickname, "小\xff王", "", "昵称包含无效字符"},
+		{"超过长度", fieldRoomName, "一二三四五六七八九十一二三四五六七八九十一", "", "房间名不能超过20个字"},
+		{"emoji不计入房间名长度", fieldRoomName, "一二三四五六七八九十一二三四五六七八九十🎉", "一二三四五六七八九十一二三四五六七八九十", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeText(tt.field, tt.text)
+			if tt.err != "" {
+				var textErr *TextError
+				if !errors.As(err, &textErr) || textErr.Message != tt.err {
+					t.Fatalf("错误 = %v，期望 %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("normalizeText = %q, %v，期望 %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestContentCheckerKeywords(t *testing.T) {
+	tests := []struct {
+		name string
+		mask bool
+		text string
+		want string
+		err  bool
+	}{
+		{"未命中", false, "周五麻将局", "周五麻将局", false},
+		{"命中时拒绝", false, "来网赌吧", "", true},
+		{"全角和大小写归一化后命中", false, "加ＶＸ聊", "", true},
+		{"插入符号后命中", false, "加.微.信", "", true},
+		{"打码", true, "来网赌吧", "来**吧", false},
+		{"打码覆盖插入的符号", true, "加 V X 聊", "***** 聊", false},
+		{"自定义敏感词", true, "三缺一", "***", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewContentChecker(nil, ContentCheckerOptions{Mask: tt.mask, Keywords: []string{"三缺一"}})
+			got, err := checker.Check(context.Background(), fieldRoomName, tt.text, "")
+			var textErr *TextError
+			if tt.err {
+				if !errors.As(err, &textErr) {
+					t.Fatalf("应返回TextError，实际 %q, %v", got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("Check = %q, %v，期望 %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestContentCheckerWeChat(t *testing.T) {
+	const openID = "o_test_user"
+	tests := []struct {
+		name   string
+		mask   bool
+		fail   bool // msg_sec_check返回系统错误
+		text   string
+		openID string
+		want   string
+		err    bool
+		calls  int
+	}{
+		{"通过", false, false, "周五麻将局", openID, "周五麻将局", false, 1},
+		{"违规时拒绝", false, false, "周五测试违规局", openID, "", true, 1},
+		{"违规时打码关键词", true, false, "周五测试违规局", openID, "周五****局", false, 1},
+		{"接口失败时放行", false, true, "周五测试违规局", openID, "周五测试违规局", false, 1},
+		{"没有openid时不调用", false, false, "周五测试违规局", "", "周五测试违规局", false, 0},
+		{"本地词库拒绝后不调用", false, false, "网赌局", openID, "", true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, fake := newFakeWeChat(t)
+			if tt.fail {
+				fake.FailNext(wechatfake.APIMsgSecCheck, -1, "system error")
+			}
+			checker := NewContentChecker(w, ContentCheckerOptions{Mask: tt.mask, WeChatCheck: true})
+			got, err := checker.Check(context.Background(), fieldRoomName, tt.text, tt.openID)
+			if tt.err {
+				var textErr *TextError
+				if !errors.As(err, &textErr) {
+					t.Fatalf("应返回TextError，实际 %q, %v", got, err)
+				}
+			} else if err != nil || got != tt.want {
+				t.Fatalf("Check = %q, %v，期望 %q", got, err, tt.want)
+			}
+			if calls := fake.Calls(wechatfake.APIMsgSecCheck); calls != tt.calls {
+				t.Fatalf("msg_sec_check调用了%d次，期望%d次", calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestContentCheckerWeChatMaskFallback(t *testing.T) {
+	w, fake := newFakeWeChat(t)
+	// 微信返回的关键词只有符号，无法在文本中定位，整段替换为默认内容
+	fake.SetRiskyWords("!!")
+	checker := NewContentChecker(w, ContentCheckerOptions{Mask: true, WeChatCheck: true})
+	got, err := checker.Check(context.Background(), fieldNickname, "小王!!", "o_test_user")
+	if err != nil || got != fieldNickname.fallback {
+		t.Fatalf("Check = %q, %v，期望 %q", got, err, fieldNickname.fallback)
+	}
+}
+
+func TestTextErrorResponse(t *testing.T) {
+	ctx := context.Background()
+	if resp := textErrorResponse(ctx, &TextError{Message: "昵称不能为空"}); resp.Code != 400 || resp.Message != "昵称不能为空" {
+		t.Fatalf("TextError的响应 = %+v", resp)
+	}
+	// 其他错误不能当作校验通过，也不能把内部错误提示给用户
+	if resp := textErrorResponse(ctx, errors.New("connection reset")); resp.Code != 500 || resp.Message == "connection reset" {
+		t.Fatalf("其他错误的响应 = %+v", resp)
+	}
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer 本包的span均由它创建，未启用追踪时为空操作
+var tracer = otel.Tracer("mahjong-server/internal/service")
+
+// maxStatementLength span中记录的SQL最大长度
+const maxStatementLength = 500
+
+// tracedDB 包装*sql.DB，为每条SQL创建span
+type tracedDB struct {
+	db *sql.DB
+}
+
+func (d *tracedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := startSQLSpan(ctx, "sql.QueryRow", query)
+	defer span.End()
+	row := d.db.QueryRowContext(ctx, query, args...)
+	recordSQLError(span, row.Err())
+	return row
+}
+
+func (d *tracedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := startSQLSpan(ctx, "sql.Query", query)
+	defer span.End()
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	recordSQLError(span, err)
+	return rows, err
+}
+
+func (d *tracedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := startSQLSpan(ctx, "sql.Exec", query)
+	defer span.End()
+	result, err := d.db.ExecContext(ctx, query, args...)
+	recordSQLError(span, err)
+	return result, err
+}
+
+// BeginTx 开始事务，事务span从开始持续到提交或回滚，事务内的SQL作为其子span
+func (d *tracedDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*tracedTx, error) {
+	spanCtx, span := tracer.Start(ctx, "sql.Tx", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.system", "mysql")))
+	tx, err := d.db.BeginTx(ctx, opts)
+	if err != nil {
+		recordError(span, err)
+		span.End()
+		return nil, err
+	}
+	return &tracedTx{tx: tx, ctx: spanCtx, span: span}, nil
+}
+
+// tracedTx 包装*sql.Tx
+type tracedTx struct {
+	tx   *sql.Tx
+	ctx  context.Context // 带事务span的ctx，作为事务内SQL span的父级
+	span trace.Span
+}
+
+func (t *tracedTx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	_, span := startSQLSpan(t.ctx, "sql.QueryRow", query)
+	defer span.End()
+	row := t.tx.QueryRowContext(ctx, query, args...)
+	recordSQLError(span, row.Err())
+	return row
+}
+
+func (t *tracedTx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	_, span := startSQLSpan(t.ctx, "sql.Query", query)
+	defer span.End()
+	rows, err := t.tx.QueryContext(ctx, query, args...)
+	recordSQLError(span, err)
+	return rows, err
+}
+
+func (t *tracedTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	_, span := startSQLSpan(t.ctx, "sql.Exec", query)
+	defer span.End()
+	result, err := t.tx.ExecContext(ctx, query, args...)
+	recordSQLError(span, err)
+	return result, err
+}
+
+func (t *tracedTx) Commit() error {
+	err := t.tx.Commit()
+	t.span.SetAttributes(attribute.String("db.tx.outcome", "commit"))
+	recordError(t.span, err)
+	t.span.End()
+	return err
+}
+
+// Rollback 回滚事务，提交后调用（defer tx.Rollback()）返回sql.ErrTxDone且不影响span
+func (t *tracedTx) Rollback() error {
+	err := t.tx.Rollback()
+	if err != sql.ErrTxDone {
+		t.span.SetAttributes(attribute.String("db.tx.outcome", "rollback"))
+		recordError(t.span, err)
+		t.span.End()
+	}
+	return err
+}
+
+func startSQLSpan(ctx context.Context, name, query string) (context.Context, trace.Span) {
+	statement := strings.Join(strings.Fields(query), " ")
+	if len(statement) > maxStatementLength {
+		statement = statement[:maxStatementLength]
+	}
+	return tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("db.system", "mysql"),
+		attribute.String("db.statement", statement)))
+}
+
+// recordSQLError 记录SQL错误，查无结果不视为错误
+func recordSQLError(span trace.Span, err error) {
+	if err != sql.ErrNoRows {
+		recordError(span, err)
+	}
+}
+
+// recordError 记录错误并将span状态置为Error，err为nil时不做处理
+func recordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// isDuplicateKey 是否为唯一索引冲突（MySQL错误1062）
+func isDuplicateKey(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+}
@@ -0,0 +1,353 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"mahjong-server/internal/logger"
+)
+
+const (
+	// maxGroupLeaderboardSize 群排行榜最多返回的玩家数
+	maxGroupLeaderboardSize = 50
+	// defaultSuggestedMembers 建房时默认推荐的群成员数
+	defaultSuggestedMembers = 10
+)
+
+var ErrNotGroupMember = errors.New("不是该群的成员，请从群聊中的分享卡片打开小程序")
+
+// joinGroup 记录openGId对应的群和成员关系，返回群ID
+func (s *MahjongService) joinGroup(ctx context.Context, openGID string, userID int64) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO wechat_groups (open_gid) VALUES (?)
+		ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id)
+	`, openGID)
+	if err != nil {
+		return 0, err
+	}
+	groupID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO group_members (group_id, user_id) VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE last_seen_at = NOW()
+	`, groupID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return groupID, nil
+}
+
+// shareGroupID 解密用户从群聊打开时的分享信息，返回群ID；没有分享信息时返回0
+func (s *MahjongService) shareGroupID(ctx context.Context, userID int64, share ShareGroup) (int64, error) {
+	if share.ShareEncryptedData == "" {
+		return 0, nil
+	}
+	sessionKey, err := s.sessionKeyByUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	openGID, err := s.decryptOpenGID(share.ShareEncryptedData, share.ShareIv, sessionKey)
+	if err != nil {
+		return 0, err
+	}
+	return s.joinGroup(ctx, openGID, userID)
+}
+
+// isGroupMember 用户是否从该群的分享卡片打开过小程序，成员关系只由解密的群分享信息写入
+func (s *MahjongService) isGroupMember(ctx context.Context, groupID, userID int64) (bool, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM group_members WHERE group_id = ? AND user_id = ?
+	`, groupID, userID).Scan(&count)
+	return count > 0, err
+}
+
+// linkRoomGroup 把房间关联到群，已关联其他群的房间保持不变，返回房间最终关联的群ID
+func (s *MahjongService) linkRoomGroup(ctx context.Context, roomID, groupID int64) int64 {
+	_, err := s.db.ExecContext(ctx, `UPDATE rooms SET group_id = ? WHERE id = ? AND group_id = 0`, groupID, roomID)
+	if err != nil {
+		logger.ErrorContext(ctx, "关联房间和群失败", "room_id", roomID, "group_id", groupID, "error", err.Error())
+	}
+	return s.roomGroupID(ctx, roomID)
+}
+
+// roomGroupID 房间关联的群ID，未关联时返回0
+// 加入群房间不会成为群成员：房间号可以猜到，只有从该群的分享卡片打开才能查看群的记录
+func (s *MahjongService) roomGroupID(ctx context.Context, roomID int64) int64 {
+	var groupID int64
+	if err := s.db.QueryRowContext(ctx, `SELECT group_id FROM rooms WHERE id = ?`, roomID).Scan(&groupID); err != nil {
+		return 0
+	}
+	return groupID
+}
+
+// attachShareGroup 从群聊打开时把房间关联到该群，返回房间关联的群ID；失败不影响创建或加入房间
+func (s *MahjongService) attachShareGroup(ctx context.Context, roomID, userID int64, share ShareGroup) int64 {
+	groupID, err := s.shareGroupID(ctx, userID, share)
+	if err != nil {
+		logger.WarnContext(ctx, "解密群信息失败，房间不关联群", "room_id", roomID, "user_id", userID, "error", err.Error())
+		return 0
+	}
+	if groupID == 0 {
+		return 0
+	}
+	return s.linkRoomGroup(ctx, roomID, groupID)
+}
+
+// checkRoomUser 创建或加入房间时要关联群，须校验登录态，请求中的用户ID必须是登录用户，失败时返回给客户端的响应
+func (s *MahjongService) checkRoomUser(ctx context.Context, sessionID string, userID int64) *Response {
+	sessionUserID, err := s.userIDBySession(ctx, sessionID)
+	if errors.Is(err, ErrSessionInvalid) {
+		return &Response{Code: 401, Message: err.Error()}
+	}
+	if err != nil {
+		return failed(ctx, "查询登录态失败")
+	}
+	if sessionUserID != userID {
+		return &Response{Code: 403, Message: "登录用户与请求的用户不一致"}
+	}
+	return nil
+}
+
+// groupMemberBySession 校验登录态和群成员身份，失败时返回给客户端的响应
+func (s *MahjongService) groupMemberBySession(ctx context.Context, sessionID string, groupID int64) (int64, *Response) {
+	userID, err := s.userIDBySession(ctx, sessionID)
+	if errors.Is(err, ErrSessionInvalid) {
+		return 0, &Response{Code: 401, Message: err.Error()}
+	}
+	if err != nil {
+		return 0, failed(ctx, "查询登录态失败")
+	}
+	if groupID == 0 {
+		return userID, nil
+	}
+	member, err := s.isGroupMember(ctx, groupID, userID)
+	if err != nil {
+		return 0, failed(ctx, "查询群成员失败")
+	}
+	if !member {
+		return 0, &Response{Code: 403, Message: ErrNotGroupMember.Error()}
+	}
+	return userID, nil
+}
+
+// GetUserGroups 用户所在的群，按最近活跃排序
+func (s *MahjongService) GetUserGroups(ctx context.Context, req *GetUserGroupsRequest) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "MahjongService.GetUserGroups")
+	defer span.End()
+
+	userID, resp := s.groupMemberBySession(ctx, req.SessionID, 0)
+	if resp != nil {
+		return resp, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT g.id, g.open_gid, gm.last_seen_at,
+		       (SELECT COUNT(*) FROM rooms WHERE group_id = g.id) AS room_count,
+		       (SELECT COUNT(*) FROM group_members WHERE group_id = g.id) AS member_count
+		FROM group_members gm
+		INNER JOIN wechat_groups g ON g.id = gm.group_id
+		WHERE gm.user_id = ?
+		ORDER BY gm.last_seen_at DESC
+		LIMIT 50
+	`, userID)
+	if err != nil {
+		return failed(ctx, "查询群列表失败"), nil
+	}
+	defer rows.Close()
+
+	groups := []map[string]interface{}{}
+	for rows.Next() {
+		var groupID int64
+		var openGID string
+		var lastSeenAt time.Time
+		var roomCount, memberCount int
+		if err := rows.Scan(&groupID, &openGID, &lastSeenAt, &roomCount, &memberCount); err != nil {
+			return failed(ctx, "查询群列表失败"), nil
+		}
+		groups = append(groups, map[string]interface{}{
+			"group_id":     groupID,
+			"open_gid":     openGID, // 客户端可用<open-data type="groupName">展示群名
+			"last_seen_at": lastSeenAt.Unix(),
+			"room_count":   roomCount,
+			"member_count": memberCount,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return failed(ctx, "查询群列表失败"), nil
+	}
+
+	data, _ := json.Marshal(groups)
+	return &Response{Code: 200, Message: "获取成功", Data: string(data)}, nil
+}
+
+// GetGroupRooms 群的房间记录，按创建时间倒序分页
+func (s *MahjongService) GetGroupRooms(ctx context.Context, req *GetGroupRoomsRequest) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "MahjongService.GetGroupRooms", trace.WithAttributes(attribute.Int64("group_id", req.GroupId)))
+	defer span.End()
+
+	if req.GroupId == 0 {
+		return &Response{Code: 400, Message: "缺少group_id"}, nil
+	}
+	if _, resp := s.groupMemberBySession(ctx, req.SessionID, req.GroupId); resp != nil {
+		return resp, nil
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+	// 限制最大返回100条记录
+	if req.PageSize > 100 {
+		req.PageSize = 100
+	}
+	offset := (req.Page - 1) * req.PageSize
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT r.id, r.room_code, r.room_name, r.status, r.created_at, r.settled_at,
+		       (SELECT COUNT(*) FROM room_players WHERE room_id = r.id) AS player_count
+		FROM rooms r
+		WHERE r.group_id = ?
+		ORDER BY r.created_at DESC
+		LIMIT ? OFFSET ?
+	`, req.GroupId, req.PageSize, offset)
+	if err != nil {
+		return failed(ctx, "查询群房间失败"), nil
+	}
+	defer rows.Close()
+
+	rooms := []map[string]interface{}{}
+	for rows.Next() {
+		var roomID int64
+		var roomCode, roomName string
+		var status, playerCount int
+		var createdAt time.Time
+		var settledAt sql.NullTime
+		if err := rows.Scan(&roomID, &roomCode, &roomName, &status, &createdAt, &settledAt, &playerCount); err != nil {
+			return failed(ctx, "查询群房间失败"), nil
+		}
+		rooms = append(rooms, map[string]interface{}{
+			"room_id":      roomID,
+			"room_code":    roomCode,
+			"room_name":    roomName,
+			"status":       status,
+			"created_at":   createdAt.Unix(),
+			"settled_at":   settledAt.Time.Unix(),
+			"player_count": playerCount,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return failed(ctx, "查询群房间失败"), nil
+	}
+
+	data, _ := json.Marshal(rooms)
+	return &Response{Code: 200, Message: "获取成功", Data: string(data)}, nil
+}
+
+// GetGroupLeaderboard 群排行榜，按已结算房间的最终得分累计
+func (s *MahjongService) GetGroupLeaderboard(ctx context.Context, req *GetGroupLeaderboardRequest) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "MahjongService.GetGroupLeaderboard", trace.WithAttributes(attribute.Int64("group_id", req.GroupId)))
+	defer span.End()
+
+	if req.GroupId == 0 {
+		return &Response{Code: 400, Message: "缺少group_id"}, nil
+	}
+	if _, resp := s.groupMemberBySession(ctx, req.SessionID, req.GroupId); resp != nil {
+		return resp, nil
+	}
+	if req.Days < 0 {
+		req.Days = 0
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT u.id, u.nickname, u.avatar_url,
+		       SUM(rp.final_score) AS total_score,
+		       COUNT(*) AS room_count,
+		       SUM(CASE WHEN rp.final_score > 0 THEN 1 ELSE 0 END) AS win_count
+		FROM rooms r
+		INNER JOIN room_players rp ON rp.room_id = r.id
+		INNER JOIN users u ON u.id = rp.user_id
+		WHERE r.group_id = ? AND r.status = 2
+		  AND (? = 0 OR r.settled_at >= NOW() - INTERVAL ? DAY)
+		GROUP BY u.id, u.nickname, u.avatar_url
+		ORDER BY total_score DESC, room_count DESC
+		LIMIT ?
+	`, req.GroupId, req.Days, req.Days, maxGroupLeaderboardSize)
+	if err != nil {
+		return failed(ctx, "查询群排行榜失败"), nil
+	}
+	defer rows.Close()
+
+	entries := []*GroupLeaderboardEntry{}
+	for rows.Next() {
+		entry := &GroupLeaderboardEntry{}
+		if err := rows.Scan(&entry.UserId, &entry.Nickname, &entry.AvatarUrl, &entry.TotalScore, &entry.RoomCount, &entry.WinCount); err != nil {
+			return failed(ctx, "查询群排行榜失败"), nil
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return failed(ctx, "查询群排行榜失败"), nil
+	}
+
+	data, _ := json.Marshal(entries)
+	return &Response{Code: 200, Message: "获取成功", Data: string(data)}, nil
+}
+
+// GetGroupSuggestedMembers 建房时推荐的群成员（不含自己），常在群房间中玩的排在前面
+func (s *MahjongService) GetGroupSuggestedMembers(ctx context.Context, req *GetGroupSuggestedMembersRequest) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "MahjongService.GetGroupSuggestedMembers", trace.WithAttributes(attribute.Int64("group_id", req.GroupId)))
+	defer span.End()
+
+	if req.GroupId == 0 {
+		return &Response{Code: 400, Message: "缺少group_id"}, nil
+	}
+	userID, resp := s.groupMemberBySession(ctx, req.SessionID, req.GroupId)
+	if resp != nil {
+		return resp, nil
+	}
+	if req.Limit <= 0 || req.Limit > 50 {
+		req.Limit = defaultSuggestedMembers
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT u.id, u.nickname, u.avatar_url, gm.last_seen_at,
+		       (SELECT COUNT(*) FROM room_players rp
+		        INNER JOIN rooms r ON r.id = rp.room_id
+		        WHERE r.group_id = gm.group_id AND rp.user_id = gm.user_id) AS room_count
+		FROM group_members gm
+		INNER JOIN users u ON u.id = gm.user_id
+		WHERE gm.group_id = ? AND gm.user_id <> ?
+		ORDER BY room_count DESC, gm.last_seen_at DESC
+		LIMIT ?
+	`, req.GroupId, userID, req.Limit)
+	if err != nil {
+		return failed(ctx, "查询群成员失败"), nil
+	}
+	defer rows.Close()
+
+	members := []*GroupMember{}
+	for rows.Next() {
+		member := &GroupMember{}
+		var lastSeenAt time.Time
+		if err := rows.Scan(&member.UserId, &member.Nickname, &member.AvatarUrl, &lastSeenAt, &member.RoomCount); err != nil {
+			return failed(ctx, "查询群成员失败"), nil
+		}
+		member.LastSeenAt = lastSeenAt.Unix()
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return failed(ctx, "查询群成员失败"), nil
+	}
+
+	data, _ := json.Marshal(members)
+	return &Response{Code: 200, Message: "获取成功", Data: string(data)}, nil
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestJoinRoomDoesNotGrantGroupMembership(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("创建sqlmock失败: %v", err)
+	}
+	defer db.Close()
+	service := NewMahjongService(db, nil, nil)
+
+	// 未预期的语句sqlmock只返回错误，JoinRoom不检查写群成员的结果，
+	// 这里不按顺序匹配，并预期一条不应执行的群成员写入
+	mock.MatchExpectationsInOrder(false)
+	mock.ExpectExec("group_members").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectQuery("SELECT id, status FROM rooms").
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(3, 1))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM room_players").
+		WithArgs(int64(3), int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectBegin()
+	expectLockRoom(mock, 3, 1)
+	mock.ExpectExec("INSERT INTO room_players").
+		WithArgs(int64(3), int64(7)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO room_events").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec("INSERT INTO user_recent_rooms").
+		WithArgs(int64(7), int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// 房间已关联群5
+	mock.ExpectQuery("SELECT group_id FROM rooms").
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"group_id"}).AddRow(5))
+	mock.ExpectQuery("SELECT room_code FROM rooms").
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"room_code"}).AddRow("123456"))
+	mock.ExpectQuery("SELECT u.id, u.nickname").
+		WithArgs(int64(3), int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "nickname", "avatar_url", "current_score", "final_score"}).
+			AddRow(7, "玩家7", "", 0, 0))
+
+	resp, err := service.JoinRoom(context.Background(), &JoinRoomRequest{UserId: 7, RoomId: 3})
+	if err != nil || resp.Code != 200 {
+		t.Fatalf("JoinRoom = %+v, %v", resp, err)
+	}
+	if !strings.Contains(resp.Data, `"group_id":5`) {
+		t.Errorf("响应应带房间关联的群: %s", resp.Data)
+	}
+	// 只剩群成员写入未执行
+	err = mock.ExpectationsWereMet()
+	if err == nil {
+		t.Fatal("加入群房间不应写入群成员")
+	}
+	if !strings.Contains(err.Error(), "group_members") {
+		t.Fatalf("SQL预期未满足: %v", err)
+	}
+}
+
+func TestJoinRoomShareRequiresSession(t *testing.T) {
+	service, mock := newMockService(t, nil)
+	mock.ExpectQuery("SELECT id, status FROM rooms").
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(3, 1))
+
+	resp, _ := service.JoinRoom(context.Background(), &JoinRoomRequest{
+		UserId:     7,
+		RoomId:     3,
+		ShareGroup: ShareGroup{ShareEncryptedData: "data", ShareIv: "iv"},
+	})
+	if resp.Code != 401 {
+		t.Fatalf("带群分享信息但没有登录态应返回401，实际 %d %s", resp.Code, resp.Message)
+	}
+}
+
+func TestCreateRoomGroupChecksSessionUser(t *testing.T) {
+	tests := []struct {
+		name        string
+		sessionUser driver.Value
+		code        int32
+	}{
+		{"登录用户与creator_id不一致", int64(8), 403},
+		{"登录用户不是群成员", int64(7), 403},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newMockService(t, nil)
+			mock.ExpectQuery("SELECT user_id FROM user_sessions").
+				WithArgs("session").
+				WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(tt.sessionUser))
+			if tt.sessionUser == int64(7) {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM group_members").
+					WithArgs(int64(5), int64(7)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			}
+
+			resp, _ := service.CreateRoom(context.Background(), &CreateRoomRequest{
+				CreatorId: 7,
+				RoomName:  "周末麻将",
+				SessionID: "session",
+				GroupId:   5,
+			})
+			if resp.Code != tt.code {
+				t.Fatalf("返回 %d %s，期望 %d", resp.Code, resp.Message, tt.code)
+			}
+		})
+	}
+
+	service, _ := newMockService(t, nil)
+	resp, _ := service.CreateRoom(context.Background(), &CreateRoomRequest{CreatorId: 7, RoomName: "周末麻将", GroupId: 5})
+	if resp.Code != 401 {
+		t.Fatalf("为群建房但没有登录态应返回401，实际 %d", resp.Code)
+	}
+}
@@ -0,0 +1,304 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"mahjong-server/internal/logger"
+)
+
+// maxMergeDepth 跟随merged_into的最大层数，合并时会把指向被合并用户的记录一并改写，正常只有一层
+const maxMergeDepth = 5
+
+// loginUser 登录时查到的用户
+type loginUser struct {
+	User
+	unionID    sql.NullString
+	mergedInto int64
+}
+
+// queryLoginUser 按条件查询一个用户，不存在时返回nil
+func (s *MahjongService) queryLoginUser(ctx context.Context, where string, args ...interface{}) (*loginUser, error) {
+	user := &loginUser{}
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, openid, unionid, nickname, avatar_url, merged_into, created_at, updated_at
+		FROM users WHERE `+where, args...).Scan(&user.Id, &user.Openid, &user.unionID, &user.Nickname, &user.AvatarUrl,
+		&user.mergedInto, &user.CreatedAt, &user.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// findLoginUser 按unionid、当前小程序的openid的顺序查找登录用户，已被合并的用户跳转到合并后的用户；不存在时返回nil
+// 同一个人在公众号、其他小程序中的openid不同，但同一开放平台账号下unionid相同
+func (s *MahjongService) findLoginUser(ctx context.Context, appID, openID, unionID string) (*loginUser, error) {
+	var user *loginUser
+	var err error
+	if unionID != "" {
+		if user, err = s.queryLoginUser(ctx, "unionid = ?", unionID); err != nil {
+			return nil, err
+		}
+	}
+
+	byOpenID, err := s.queryLoginUser(ctx, "id = (SELECT user_id FROM user_openids WHERE app_id = ? AND openid = ?)", appID, openID)
+	if err != nil {
+		return nil, err
+	}
+	if byOpenID == nil {
+		// 建立user_openids之前登录的用户只有users.openid
+		if byOpenID, err = s.queryLoginUser(ctx, "openid = ?", openID); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case user == nil:
+		user = byOpenID
+	case byOpenID != nil && byOpenID.Id != user.Id && byOpenID.mergedInto != user.Id:
+		// 在获得unionid之前已按openid建过用户，需要管理员确认后合并
+		logger.WarnContext(ctx, "发现unionid相同的重复用户，需合并", "user_id", user.Id, "duplicate_user_id", byOpenID.Id)
+	}
+	if user == nil {
+		return nil, nil
+	}
+
+	for depth := 0; user.mergedInto != 0; depth++ {
+		if depth >= maxMergeDepth {
+			return nil, fmt.Errorf("用户%d的合并关系过深", user.Id)
+		}
+		if user, err = s.queryLoginUser(ctx, "id = ?", user.mergedInto); err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, fmt.Errorf("合并后的用户不存在")
+		}
+	}
+
+	// 之前只有openid的用户，补上unionid
+	if unionID != "" && !user.unionID.Valid {
+		_, err := s.db.ExecContext(ctx, `UPDATE users SET unionid = ? WHERE id = ? AND unionid IS NULL`, unionID, user.Id)
+		if err != nil {
+			// unionid已属于其他用户时唯一索引冲突，同样需要合并
+			logger.WarnContext(ctx, "保存unionid失败", "user_id", user.Id, "error", err.Error())
+		} else {
+			user.unionID = sql.NullString{String: unionID, Valid: true}
+		}
+	}
+	return user, nil
+}
+
+// saveOpenID 记录用户在当前小程序的openid，合并后的旧记录改为指向登录到的用户
+func (s *MahjongService) saveOpenID(ctx context.Context, appID, openID string, userID int64) {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO user_openids (app_id, openid, user_id) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE user_id = VALUES(user_id)
+	`, appID, openID, userID)
+	if err != nil {
+		// 不影响登录，下次登录时重试；在此之前该用户收不到订阅消息
+		logger.ErrorContext(ctx, "保存openid失败", "user_id", userID, "error", err.Error())
+	}
+}
+
+// currentOpenID 用户在当前小程序的openid，没有时返回空串
+// 从其他小程序登录的用户users.openid不是当前小程序的，不能用于发送消息或内容安全检测
+func (s *MahjongService) currentOpenID(ctx context.Context, userID int64) (string, error) {
+	var openID string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT openid FROM user_openids WHERE user_id = ? AND app_id = ?
+	`, userID, s.appID()).Scan(&openID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return openID, err
+}
+
+// appID 当前小程序的AppID
+func (s *MahjongService) appID() string {
+	if s.wechatService == nil {
+		return ""
+	}
+	return s.wechatService.appID
+}
+
+// nullableUnionID unionid为空时存NULL，唯一索引允许多个NULL
+func nullableUnionID(unionID string) sql.NullString {
+	return sql.NullString{String: unionID, Valid: unionID != ""}
+}
+
+// MergeUsers 将MergeUserId的房间、分数转移、结算等记录合并到KeepUserId，被合并的用户保留并标记merged_into，
+// 之后用其openid登录时进入保留的用户。两个用户在同一房间中玩过时无法合并
+func (s *MahjongService) MergeUsers(ctx context.Context, req *MergeUsersRequest) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "MahjongService.MergeUsers",
+		trace.WithAttributes(attribute.Int64("keep_user_id", req.KeepUserId), attribute.Int64("merge_user_id", req.MergeUserId)))
+	defer span.End()
+
+	if req.KeepUserId == 0 || req.MergeUserId == 0 || req.KeepUserId == req.MergeUserId {
+		return &Response{Code: 400, Message: "keep_user_id和merge_user_id须为两个不同的用户"}, nil
+	}
+	if !s.beginWrite() {
+		return &Response{Code: 503, Message: "服务正在重启，请稍后重试"}, nil
+	}
+	defer s.endWrite()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return failed(ctx, "开始事务失败"), nil
+	}
+	defer tx.Rollback()
+
+	// 按ID顺序锁定两个用户，避免并发合并时死锁
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, unionid, phone_number, merged_into FROM users WHERE id IN (?, ?) ORDER BY id FOR UPDATE
+	`, req.KeepUserId, req.MergeUserId)
+	if err != nil {
+		return failed(ctx, "查询用户失败"), nil
+	}
+	type mergeUser struct {
+		unionID    sql.NullString
+		phone      string
+		mergedInto int64
+	}
+	users := make(map[int64]*mergeUser, 2)
+	for rows.Next() {
+		var id int64
+		user := &mergeUser{}
+		if err := rows.Scan(&id, &user.unionID, &user.phone, &user.mergedInto); err != nil {
+			rows.Close()
+			return failed(ctx, "查询用户失败"), nil
+		}
+		users[id] = user
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return failed(ctx, "查询用户失败"), nil
+	}
+	keep, merge := users[req.KeepUserId], users[req.MergeUserId]
+	if keep == nil || merge == nil {
+		return &Response{Code: 404, Message: "用户不存在"}, nil
+	}
+	if keep.mergedInto != 0 || merge.mergedInto != 0 {
+		return &Response{Code: 409, Message: "用户已被合并，请使用合并后的用户"}, nil
+	}
+	if keep.unionID.Valid && merge.unionID.Valid && keep.unionID.String != merge.unionID.String {
+		return &Response{Code: 409, Message: "两个用户的unionid不同，不是同一个人"}, nil
+	}
+
+	// 同一房间中的两个玩家分数互相独立，合并后无法保持分数守恒
+	shared, err := sharedRoomIDs(ctx, tx, req.KeepUserId, req.MergeUserId)
+	if err != nil {
+		return failed(ctx, "查询共同房间失败"), nil
+	}
+	if len(shared) > 0 {
+		return &Response{Code: 409, Message: fmt.Sprintf("两个用户在同一房间中玩过，无法合并，房间ID: %s", joinInt64s(shared))}, nil
+	}
+
+	keepID, mergeID := req.KeepUserId, req.MergeUserId
+	steps := []struct {
+		name  string
+		query string
+		args  []interface{}
+	}{
+		{"rooms", `UPDATE rooms SET creator_id = ? WHERE creator_id = ?`, []interface{}{keepID, mergeID}},
+		{"room_players", `UPDATE room_players SET user_id = ? WHERE user_id = ?`, []interface{}{keepID, mergeID}},
+		{"score_transfers_from", `UPDATE score_transfers SET from_user_id = ? WHERE from_user_id = ?`, []interface{}{keepID, mergeID}},
+		{"score_transfers_to", `UPDATE score_transfers SET to_user_id = ? WHERE to_user_id = ?`, []interface{}{keepID, mergeID}},
+		{"settlements_from", `UPDATE settlements SET from_user_id = ? WHERE from_user_id = ?`, []interface{}{keepID, mergeID}},
+		{"settlements_to", `UPDATE settlements SET to_user_id = ? WHERE to_user_id = ?`, []interface{}{keepID, mergeID}},
+		{"room_events_user", `UPDATE room_events SET user_id = ? WHERE user_id = ?`, []interface{}{keepID, mergeID}},
+		{"room_events_target", `UPDATE room_events SET target_user_id = ? WHERE target_user_id = ?`, []interface{}{keepID, mergeID}},
+		// 没有共同房间，最近房间不会冲突；IGNORE只是兜底
+		{"user_recent_rooms", `UPDATE IGNORE user_recent_rooms SET user_id = ? WHERE user_id = ?`, []interface{}{keepID, mergeID}},
+		{"user_recent_rooms_rest", `DELETE FROM user_recent_rooms WHERE user_id = ?`, []interface{}{mergeID}},
+		{"group_members", `UPDATE IGNORE group_members SET user_id = ? WHERE user_id = ?`, []interface{}{keepID, mergeID}},
+		{"group_members_rest", `DELETE FROM group_members WHERE user_id = ?`, []interface{}{mergeID}},
+		// 被合并用户在其他小程序的openid归到保留的用户，同一小程序已有openid时以保留的用户为准
+		{"user_openids", `UPDATE IGNORE user_openids SET user_id = ? WHERE user_id = ?`, []interface{}{keepID, mergeID}},
+		{"user_openids_rest", `DELETE FROM user_openids WHERE user_id = ?`, []interface{}{mergeID}},
+		// 已登录的设备继续有效，之后都是保留的用户
+		{"user_sessions", `UPDATE user_sessions SET user_id = ? WHERE user_id = ?`, []interface{}{keepID, mergeID}},
+		// session_key和订阅授权属于被合并用户的openid，对保留的用户无效
+		{"user_session_keys", `DELETE FROM user_session_keys WHERE user_id = ?`, []interface{}{mergeID}},
+		{"subscribe_consents", `DELETE FROM subscribe_consents WHERE user_id = ?`, []interface{}{mergeID}},
+		{"notifications", `UPDATE notifications SET status = 2, last_error = '用户已合并' WHERE user_id = ? AND status = 0`, []interface{}{mergeID}},
+		// 之前合并到被合并用户的记录直接指向保留的用户
+		{"users_merged", `UPDATE users SET merged_into = ? WHERE merged_into = ?`, []interface{}{keepID, mergeID}},
+	}
+	affected := make(map[string]int64, len(steps))
+	for _, step := range steps {
+		result, err := tx.ExecContext(ctx, step.query, step.args...)
+		if err != nil {
+			logger.ErrorContext(ctx, "合并用户失败", "step", step.name, "error", err.Error())
+			return failed(ctx, "合并用户失败: "+step.name), nil
+		}
+		affected[step.name], _ = result.RowsAffected()
+	}
+
+	// unionid和手机号转移到保留的用户，unionid有唯一索引，需先清除被合并用户的
+	unionID := keep.unionID
+	if !unionID.Valid {
+		unionID = merge.unionID
+	}
+	phone := keep.phone
+	if phone == "" {
+		phone = merge.phone
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE users SET unionid = NULL, merged_into = ?, updated_at = NOW() WHERE id = ?`, keepID, mergeID); err != nil {
+		return failed(ctx, "更新被合并用户失败"), nil
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE users SET unionid = ?, phone_number = ?, updated_at = NOW() WHERE id = ?`, unionID, phone, keepID); err != nil {
+		return failed(ctx, "更新保留用户失败"), nil
+	}
+
+	if req.DryRun {
+		// 预演：返回影响的记录数，不提交
+		data, _ := json.Marshal(map[string]interface{}{"dry_run": true, "affected": affected})
+		return &Response{Code: 200, Message: "预演完成，未提交", Data: string(data)}, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return failed(ctx, "提交事务失败"), nil
+	}
+
+	logger.LogBusiness(ctx, "merge_users", keepID, "merge_user_id", mergeID, "affected", affected)
+
+	data, _ := json.Marshal(map[string]interface{}{"dry_run": false, "affected": affected})
+	return &Response{Code: 200, Message: "合并成功", Data: string(data)}, nil
+}
+
+// sharedRoomIDs 两个用户都加入过的房间
+func sharedRoomIDs(ctx context.Context, tx *tracedTx, userA, userB int64) ([]int64, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT a.room_id FROM room_players a
+		INNER JOIN room_players b ON b.room_id = a.room_id
+		WHERE a.user_id = ? AND b.user_id = ?
+		ORDER BY a.room_id
+	`, userA, userB)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var roomIDs []int64
+	for rows.Next() {
+		var roomID int64
+		if err := rows.Scan(&roomID); err != nil {
+			return nil, err
+		}
+		roomIDs = append(roomIDs, roomID)
+	}
+	return roomIDs, rows.Err()
+}
+
+func joinInt64s(values []int64) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// expectMergeLock 预期合并时锁定两个用户
+func expectMergeLock(mock sqlmock.Sqlmock, keepID, mergeID int64, rows *sqlmock.Rows) {
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, unionid, phone_number, merged_into FROM users WHERE id IN").
+		WithArgs(keepID, mergeID).
+		WillReturnRows(rows)
+}
+
+func TestMergeUsers(t *testing.T) {
+	service, mock := newMockService(t, nil)
+	expectMergeLock(mock, 1, 2, sqlmock.NewRows([]string{"id", "unionid", "phone_number", "merged_into"}).
+		AddRow(1, nil, "13800000000", 0).
+		AddRow(2, "union-a", "", 0))
+	mock.ExpectQuery("SELECT a.room_id FROM room_players a").
+		WithArgs(int64(1), int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"room_id"}))
+
+	// 房间、分数转移、结算都改指向保留的用户
+	repoint := []struct {
+		query    string
+		affected int64
+	}{
+		{"UPDATE rooms SET creator_id = \\? WHERE creator_id = \\?", 2},
+		{"UPDATE room_players SET user_id = \\? WHERE user_id = \\?", 3},
+		{"UPDATE score_transfers SET from_user_id = \\? WHERE from_user_id = \\?", 4},
+		{"UPDATE score_transfers SET to_user_id = \\? WHERE to_user_id = \\?", 5},
+		{"UPDATE settlements SET from_user_id = \\? WHERE from_user_id = \\?", 6},
+		{"UPDATE settlements SET to_user_id = \\? WHERE to_user_id = \\?", 7},
+		{"UPDATE room_events SET user_id = \\? WHERE user_id = \\?", 8},
+		{"UPDATE room_events SET target_user_id = \\? WHERE target_user_id = \\?", 0},
+	}
+	for _, step := range repoint {
+		mock.ExpectExec(step.query).WithArgs(int64(1), int64(2)).WillReturnResult(sqlmock.NewResult(0, step.affected))
+	}
+	// 其余关联数据
+	rest := []struct {
+		query string
+		args  []driver.Value
+	}{
+		{"UPDATE IGNORE user_recent_rooms", []driver.Value{int64(1), int64(2)}},
+		{"DELETE FROM user_recent_rooms", []driver.Value{int64(2)}},
+		{"UPDATE IGNORE group_members", []driver.Value{int64(1), int64(2)}},
+		{"DELETE FROM group_members", []driver.Value{int64(2)}},
+		{"UPDATE IGNORE user_openids", []driver.Value{int64(1), int64(2)}},
+		{"DELETE FROM user_openids", []driver.Value{int64(2)}},
+		{"UPDATE user_sessions", []driver.Value{int64(1), int64(2)}},
+		{"DELETE FROM user_session_keys", []driver.Value{int64(2)}},
+		{"DELETE FROM subscribe_consents", []driver.Value{int64(2)}},
+		{"UPDATE notifications", []driver.Value{int64(2)}},
+		{"UPDATE users SET merged_into = \\? WHERE merged_into = \\?", []driver.Value{int64(1), int64(2)}},
+	}
+	for _, step := range rest {
+		mock.ExpectExec(step.query).WithArgs(step.args...).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+	// 被合并用户的unionid转移到保留的用户，手机号以保留的用户为准
+	mock.ExpectExec("UPDATE users SET unionid = NULL, merged_into = \\?").
+		WithArgs(int64(1), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET unionid = \\?, phone_number = \\?").
+		WithArgs("union-a", "13800000000", int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	resp, err := service.MergeUsers(context.Background(), &MergeUsersRequest{KeepUserId: 1, MergeUserId: 2})
+	if err != nil || resp.Code != 200 {
+		t.Fatalf("MergeUsers = %+v, %v", resp, err)
+	}
+	var data struct {
+		DryRun   bool             `json:"dry_run"`
+		Affected map[string]int64 `json:"affected"`
+	}
+	if err := json.Unmarshal([]byte(resp.Data), &data); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	want := map[string]int64{
+		"rooms":                2,
+		"room_players":         3,
+		"score_transfers_from": 4,
+		"score_transfers_to":   5,
+		"settlements_from":     6,
+		"settlements_to":       7,
+		"room_events_user":     8,
+	}
+	if data.DryRun {
+		t.Error("未要求预演时应提交")
+	}
+	for name, count := range want {
+		if data.Affected[name] != count {
+			t.Errorf("%s影响行数 = %d，期望 %d", name, data.Affected[name], count)
+		}
+	}
+}
+
+func TestMergeUsersRejected(t *testing.T) {
+	columns := []string{"id", "unionid", "phone_number", "merged_into"}
+	tests := []struct {
+		name   string
+		rows   *sqlmock.Rows
+		shared bool
+		code   int32
+	}{
+		{"用户不存在", sqlmock.NewRows(columns).AddRow(1, nil, "", 0), false, 404},
+		{"已被合并", sqlmock.NewRows(columns).AddRow(1, nil, "", 0).AddRow(2, nil, "", 3), false, 409},
+		{"unionid不同", sqlmock.NewRows(columns).AddRow(1, "union-a", "", 0).AddRow(2, "union-b", "", 0), false, 409},
+		{"有共同房间", sqlmock.NewRows(columns).AddRow(1, nil, "", 0).AddRow(2, nil, "", 0), true, 409},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newMockService(t, nil)
+			expectMergeLock(mock, 1, 2, tt.rows)
+			if tt.shared {
+				mock.ExpectQuery("SELECT a.room_id FROM room_players a").
+					WithArgs(int64(1), int64(2)).
+					WillReturnRows(sqlmock.NewRows([]string{"room_id"}).AddRow(5))
+			}
+			// 拒绝时不改动任何记录
+			mock.ExpectRollback()
+
+			resp, _ := service.MergeUsers(context.Background(), &MergeUsersRequest{KeepUserId: 1, MergeUserId: 2})
+			if resp.Code != tt.code {
+				t.Fatalf("响应码 = %d %s，期望 %d", resp.Code, resp.Message, tt.code)
+			}
+		})
+	}
+}
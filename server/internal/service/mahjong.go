@@ -5,120 +5,204 @@ import (
 	"crypto/md5"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
-	"reflect"
+	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"mahjong-server/internal/events"
 	"mahjong-server/internal/logger"
+	"mahjong-server/internal/storage"
 )
 
 type MahjongService struct {
-	db          *sql.DB
+	db            *tracedDB
 	wechatService *WeChatService
-	hub         interface{} // WebSocket Hub接口，避免循环依赖
+	publisher     events.EventPublisher
+	presence      PresenceProvider
+	qrcodes       *RoomQRCodes
+	storage       storage.Storage
+	notifier      *Notifier
+	content       *ContentChecker
+	sessionKeys   *SessionKeyCipher
+
+	writeMu  sync.Mutex
+	writes   sync.WaitGroup // 进行中的写事务
+	draining bool           // 关闭中，不再接受新的写事务，由writeMu保护
 }
 
-func NewMahjongService(db *sql.DB, wechatService *WeChatService) *MahjongService {
+func NewMahjongService(db *sql.DB, wechatService *WeChatService, publisher events.EventPublisher) *MahjongService {
 	return &MahjongService{
-		db:            db,
+		db:            &tracedDB{db: db},
 		wechatService: wechatService,
+		publisher:     publisher,
 	}
 }
 
-// SetHub 设置WebSocket Hub（避免循环依赖）
-func (s *MahjongService) SetHub(hub interface{}) {
-	s.hub = hub
+// PresenceProvider 提供房间内用户的在线状态，由WebSocket Hub实现
+type PresenceProvider interface {
+	IsOnline(roomID, userID int64) bool
 }
 
-// broadcastToRoom 向房间广播消息
-func (s *MahjongService) broadcastToRoom(roomID int64, eventType string, data interface{}) {
-	if s.hub != nil {
-		// 使用反射调用Hub的BroadcastToRoom方法
-		hubValue := reflect.ValueOf(s.hub)
-		method := hubValue.MethodByName("BroadcastToRoom")
-		if method.IsValid() {
-			method.Call([]reflect.Value{
-				reflect.ValueOf(roomID),
-				reflect.ValueOf(eventType),
-				reflect.ValueOf(data),
-			})
-			logger.Info("已广播房间事件", "room_id", roomID, "event_type", eventType)
-		}
+// SetPresenceProvider 设置在线状态来源
+func (s *MahjongService) SetPresenceProvider(presence PresenceProvider) {
+	s.presence = presence
+}
+
+// SetRoomQRCodes 设置房间小程序码的生成和缓存
+func (s *MahjongService) SetRoomQRCodes(qrcodes *RoomQRCodes) {
+	s.qrcodes = qrcodes
+}
+
+// publish 发布业务事件，WebSocket推送等订阅者同步执行，耗时计入events.Publish span
+func (s *MahjongService) publish(ctx context.Context, event events.Event) {
+	if s.publisher == nil {
+		return
+	}
+	ctx, span := tracer.Start(ctx, "events.Publish",
+		trace.WithAttributes(attribute.String("event.type", event.EventType()), attribute.Int64("room_id", event.RoomID())))
+	defer span.End()
+	s.publisher.Publish(ctx, event)
+}
+
+// failed 构造内部错误响应；请求已超时或被取消时，数据库调用失败是由此引起的，返回504/499以便区分
+func failed(ctx context.Context, message string) *Response {
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return &Response{Code: 504, Message: "请求超时，请稍后重试"}
+	case context.Canceled:
+		return &Response{Code: 499, Message: "请求已取消"}
+	}
+	return &Response{Code: 500, Message: message}
+}
+
+// beginWrite 登记一个写事务，服务关闭中时返回false
+func (s *MahjongService) beginWrite() bool {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if s.draining {
+		return false
+	}
+	s.writes.Add(1)
+	return true
+}
+
+// endWrite 写事务结束
+func (s *MahjongService) endWrite() {
+	s.writes.Done()
+}
+
+// Drain 拒绝新的写事务，并等待进行中的分数转移、撤销、结算等事务完成
+func (s *MahjongService) Drain(ctx context.Context) error {
+	s.writeMu.Lock()
+	s.draining = true
+	s.writeMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.writes.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("等待进行中的事务超时: %w", ctx.Err())
 	}
 }
 
 // 自动登录（只获取openid，查询或创建用户记录）
 func (s *MahjongService) AutoLogin(ctx context.Context, req *AutoLoginRequest) (*Response, error) {
-	logger.Info("开始自动登录", "code_length", len(req.Code))
+	ctx, span := tracer.Start(ctx, "MahjongService.AutoLogin")
+	defer span.End()
+
+	logger.InfoContext(ctx, "开始自动登录", "code_length", len(req.Code))
 	
 	// 通过微信code获取openid
-	wechatResp, err := s.wechatService.GetOpenID(req.Code)
+	wechatResp, err := s.wechatService.GetOpenID(ctx, req.Code)
 	if err != nil {
-		logger.Error("获取微信用户信息失败", "error", err.Error())
-		return &Response{Code: 500, Message: "获取微信用户信息失败: " + err.Error()}, nil
+		logger.ErrorContext(ctx, "获取微信用户信息失败", "error", err.Error())
+		return failed(ctx, "获取微信用户信息失败: " + err.Error()), nil
 	}
 	
-	logger.Info("获取微信openid成功", "openid", wechatResp.OpenID)
+	logger.InfoContext(ctx, "获取微信openid成功", "openid", wechatResp.OpenID)
 	
 	openid := wechatResp.OpenID
 	if openid == "" {
-		return &Response{Code: 500, Message: "获取openid失败"}, nil
+		return failed(ctx, "获取openid失败"), nil
 	}
 	
-	// 查询用户是否已存在
+	// 查询用户是否已存在，有unionid时优先按unionid查找
+	found, err := s.findLoginUser(ctx, s.appID(), openid, wechatResp.UnionID)
+	if err != nil {
+		return failed(ctx, "查询用户失败: " + err.Error()), nil
+	}
+
 	var user User
-	var createdAt, updatedAt time.Time
-	err = s.db.QueryRow(`
-		SELECT id, openid, nickname, avatar_url, created_at, updated_at 
-		FROM users WHERE openid = ?
-	`, openid).Scan(&user.Id, &user.Openid, &user.Nickname, &user.AvatarUrl, &createdAt, &updatedAt)
-	
-	if err == sql.ErrNoRows {
+	if found == nil {
 		// 用户不存在，创建新用户记录（使用默认值）
-		result, err := s.db.Exec(`
-			INSERT INTO users (openid, nickname, avatar_url, created_at, updated_at) 
-			VALUES (?, ?, ?, NOW(), NOW())
-		`, openid, "微信用户", "/images/default-avatar.png")
+		result, err := s.db.ExecContext(ctx, `
+			INSERT INTO users (openid, unionid, nickname, avatar_url, created_at, updated_at) 
+			VALUES (?, ?, ?, ?, NOW(), NOW())
+		`, openid, nullableUnionID(wechatResp.UnionID), "微信用户", "/images/default-avatar.png")
 		
+		if isDuplicateKey(err) {
+			// 同一个人的并发首次登录（如多个小程序同时打开）已由另一个请求创建了用户，改用该用户
+			logger.InfoContext(ctx, "用户已由并发的登录请求创建", "openid", openid)
+			found, err = s.findLoginUser(ctx, s.appID(), openid, wechatResp.UnionID)
+			if err == nil && found == nil {
+				err = fmt.Errorf("创建用户冲突后未找到用户")
+			}
+		}
 		if err != nil {
-			return &Response{Code: 500, Message: "创建用户失败: " + err.Error()}, nil
+			return failed(ctx, "创建用户失败: " + err.Error()), nil
 		}
 		
-		userID, _ := result.LastInsertId()
-		user = User{
-			Id:        userID,
-			Openid:    openid,
-			Nickname:  "微信用户",
-			AvatarUrl: "/images/default-avatar.png",
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
+		if found == nil {
+			userID, _ := result.LastInsertId()
+			user = User{
+				Id:        userID,
+				Openid:    openid,
+				Nickname:  "微信用户",
+				AvatarUrl: "/images/default-avatar.png",
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+			}
 		}
-	} else if err != nil {
-		return &Response{Code: 500, Message: "查询用户失败: " + err.Error()}, nil
-	} else {
-		// 用户已存在，更新最后登录时间
-		user.CreatedAt = createdAt
-		user.UpdatedAt = updatedAt
+	}
+	if found != nil {
+		// 用户已存在，更新最后登录时间；返回当前小程序的openid
+		user = found.User
+		user.Openid = openid
 		
-		_, err = s.db.Exec(`UPDATE users SET updated_at = NOW() WHERE id = ?`, user.Id)
+		_, err = s.db.ExecContext(ctx, `UPDATE users SET updated_at = NOW() WHERE id = ?`, user.Id)
 		if err != nil {
-			return &Response{Code: 500, Message: "更新用户登录时间失败: " + err.Error()}, nil
+			return failed(ctx, "更新用户登录时间失败: " + err.Error()), nil
 		}
 	}
 	
+	// 按小程序保存openid，发送订阅消息和内容安全检测使用当前小程序的openid
+	s.saveOpenID(ctx, s.appID(), openid, user.Id)
+
+	// 保存session_key，用于之后解密手机号、群信息等开放数据
+	s.saveSessionKey(ctx, user.Id, wechatResp.SessionKey)
+
 	// 生成session
 	sessionID := fmt.Sprintf("%x", md5.Sum([]byte(fmt.Sprintf("%d_%s_%d", user.Id, user.Openid, time.Now().UnixNano()))))
 	expiresAt := time.Now().Add(24 * time.Hour)
 	
 	// 保存session到数据库
-	_, err = s.db.Exec(`
+	_, err = s.db.ExecContext(ctx, `
 		INSERT INTO user_sessions (session_id, user_id, expires_at, created_at) 
 		VALUES (?, ?, ?, NOW())
 	`, sessionID, user.Id, expiresAt)
 	
 	if err != nil {
-		return &Response{Code: 500, Message: "保存session失败: " + err.Error()}, nil
+		return failed(ctx, "保存session失败: " + err.Error()), nil
 	}
 	
 	// 返回用户信息和session
@@ -135,20 +219,45 @@ func (s *MahjongService) AutoLogin(ctx context.Context, req *AutoLoginRequest) (
 
 // 更新用户信息
 func (s *MahjongService) UpdateUser(ctx context.Context, req *UpdateUserRequest) (*Response, error) {
-	_, err := s.db.Exec(`
+	ctx, span := tracer.Start(ctx, "MahjongService.UpdateUser")
+	defer span.End()
+
+	nickname, err := s.checkText(ctx, fieldNickname, req.Nickname, req.UserId)
+	if err != nil {
+		return textErrorResponse(ctx, err), nil
+	}
+
+	// 头像只能是uploadAvatar保存的地址，未修改头像时沿用原值
+	if req.AvatarUrl != "" && !s.isStoredAvatarURL(req.AvatarUrl) {
+		var current string
+		err := s.db.QueryRowContext(ctx, `SELECT avatar_url FROM users WHERE id = ?`, req.UserId).Scan(&current)
+		if err != nil && err != sql.ErrNoRows {
+			return failed(ctx, "查询用户失败"), nil
+		}
+		if req.AvatarUrl != current {
+			return &Response{Code: 400, Message: "头像地址无效，请先上传头像"}, nil
+		}
+	}
+
+	_, err = s.db.ExecContext(ctx, `
 		UPDATE users SET nickname = ?, avatar_url = ?, updated_at = NOW() 
 		WHERE id = ?
-	`, req.Nickname, req.AvatarUrl, req.UserId)
+	`, nickname, req.AvatarUrl, req.UserId)
 	
 	if err != nil {
-		return &Response{Code: 500, Message: "更新用户信息失败"}, nil
+		return failed(ctx, "更新用户信息失败"), nil
 	}
 
-	return &Response{Code: 200, Message: "更新成功"}, nil
+	// 昵称可能被清理或打码，客户端以返回值为准
+	data, _ := json.Marshal(map[string]interface{}{"nickname": nickname})
+	return &Response{Code: 200, Message: "更新成功", Data: string(data)}, nil
 }
 
 // 验证登录态
 func (s *MahjongService) ValidateSession(ctx context.Context, sessionID string) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "MahjongService.ValidateSession")
+	defer span.End()
+
 	if sessionID == "" {
 		return &Response{Code: 401, Message: "未登录"}, nil
 	}
@@ -167,11 +276,14 @@ func (s *MahjongService) ValidateSession(ctx context.Context, sessionID string)
 	// 获取用户信息
 	user := &User{}
 	var createdAt, updatedAt time.Time
-	err = s.db.QueryRow(`
+	err = s.db.QueryRowContext(ctx, `
 		SELECT id, openid, nickname, avatar_url, created_at, updated_at 
 		FROM users WHERE id = ?
 	`, customSession.UserID).Scan(&user.Id, &user.Openid, &user.Nickname, &user.AvatarUrl, &createdAt, &updatedAt)
 	
+	if err != nil && err != sql.ErrNoRows {
+		return failed(ctx, "查询用户失败"), nil
+	}
 	if err != nil {
 		return &Response{Code: 404, Message: "用户不存在"}, nil
 	}
@@ -185,13 +297,19 @@ func (s *MahjongService) ValidateSession(ctx context.Context, sessionID string)
 
 // 获取用户信息
 func (s *MahjongService) GetUser(ctx context.Context, req *GetUserRequest) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "MahjongService.GetUser")
+	defer span.End()
+
 	user := &User{}
 	var createdAt, updatedAt time.Time
-	err := s.db.QueryRow(`
+	err := s.db.QueryRowContext(ctx, `
 		SELECT id, openid, nickname, avatar_url, created_at, updated_at 
 		FROM users WHERE id = ?
 	`, req.UserId).Scan(&user.Id, &user.Openid, &user.Nickname, &user.AvatarUrl, &createdAt, &updatedAt)
 	
+	if err != nil && err != sql.ErrNoRows {
+		return failed(ctx, "查询用户失败"), nil
+	}
 	if err != nil {
 		return &Response{Code: 404, Message: "用户不存在"}, nil
 	}
@@ -205,37 +323,100 @@ func (s *MahjongService) GetUser(ctx context.Context, req *GetUserRequest) (*Res
 
 // 创建房间
 func (s *MahjongService) CreateRoom(ctx context.Context, req *CreateRoomRequest) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "MahjongService.CreateRoom")
+	defer span.End()
+
+	roomName, err := s.checkText(ctx, fieldRoomName, req.RoomName, req.CreatorId)
+	if err != nil {
+		return textErrorResponse(ctx, err), nil
+	}
+
+	// 关联群时不能只凭客户端传的creator_id，须校验登录态
+	if req.GroupId != 0 || req.ShareEncryptedData != "" {
+		if resp := s.checkRoomUser(ctx, req.SessionID, req.CreatorId); resp != nil {
+			return resp, nil
+		}
+	}
+
+	// 为群创建房间须是该群成员，群分享信息在创建后关联
+	if req.GroupId != 0 && req.ShareEncryptedData == "" {
+		member, err := s.isGroupMember(ctx, req.GroupId, req.CreatorId)
+		if err != nil {
+			return failed(ctx, "查询群成员失败"), nil
+		}
+		if !member {
+			return &Response{Code: 403, Message: ErrNotGroupMember.Error()}, nil
+		}
+	}
+
 	// 生成唯一的房间号（包含时间戳的字符串）
-	roomCode := s.generateUniqueRoomCode()
-	
+	roomCode := s.generateUniqueRoomCode(ctx)
+
+	if !s.beginWrite() {
+		return &Response{Code: 503, Message: "服务正在重启，请稍后重试"}, nil
+	}
+	defer s.endWrite()
+
+	// 房间、创建者和joined事件在同一事务中写入，事件日志与room_players保持一致
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return failed(ctx, "开始事务失败"), nil
+	}
+	defer tx.Rollback()
+
 	// 创建房间
-	result, err := s.db.Exec(`
+	result, err := tx.ExecContext(ctx, `
 		INSERT INTO rooms (room_code, room_name, creator_id) 
 		VALUES (?, ?, ?)
-	`, roomCode, req.RoomName, req.CreatorId)
+	`, roomCode, roomName, req.CreatorId)
 	
 	if err != nil {
-		return &Response{Code: 500, Message: "创建房间失败"}, nil
+		return failed(ctx, "创建房间失败"), nil
 	}
 	
 	roomID, _ := result.LastInsertId()
 	
 	// 创建者加入房间
-	_, err = s.db.Exec(`
+	_, err = tx.ExecContext(ctx, `
 		INSERT INTO room_players (room_id, user_id, current_score, final_score) 
 		VALUES (?, ?, 0, 0)
 	`, roomID, req.CreatorId)
 	
 	if err != nil {
-		return &Response{Code: 500, Message: "加入房间失败"}, nil
+		return failed(ctx, "加入房间失败"), nil
+	}
+
+	if err := appendRoomEvent(ctx, tx, &RoomEvent{RoomId: roomID, EventType: RoomEventJoined, UserId: req.CreatorId}); err != nil {
+		return failed(ctx, "记录房间事件失败"), nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return failed(ctx, "提交事务失败"), nil
 	}
 
 	// 更新用户最近房间
-	s.updateRecentRoom(req.CreatorId, roomID)
+	s.updateRecentRoom(ctx, req.CreatorId, roomID)
+
+	// 关联微信群
+	var groupID int64
+	if req.ShareEncryptedData != "" {
+		groupID = s.attachShareGroup(ctx, roomID, req.CreatorId, req.ShareGroup)
+	} else if req.GroupId != 0 {
+		groupID = s.linkRoomGroup(ctx, roomID, req.GroupId)
+	}
+
+	s.publish(ctx, &events.RoomCreated{
+		RoomId:    roomID,
+		RoomCode:  roomCode,
+		RoomName:  roomName,
+		CreatorId: req.CreatorId,
+	})
 
 	roomData := map[string]interface{}{
 		"room_id":   roomID,
 		"room_code": roomCode,
+		"room_name": roomName,
+		"group_id":  groupID,
 	}
 	
 	data, _ := json.Marshal(roomData)
@@ -244,26 +425,37 @@ func (s *MahjongService) CreateRoom(ctx context.Context, req *CreateRoomRequest)
 
 // 加入房间
 func (s *MahjongService) JoinRoom(ctx context.Context, req *JoinRoomRequest) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "MahjongService.JoinRoom", trace.WithAttributes(attribute.Int64("room_id", req.RoomId)))
+	defer span.End()
+
 	// 获取房间信息
 	var roomID int64
 	var status int
-	err := s.db.QueryRow(`
+	err := s.db.QueryRowContext(ctx, `
 		SELECT id, status FROM rooms WHERE id = ?
 	`, req.RoomId).Scan(&roomID, &status)
 	
 	if err == sql.ErrNoRows {
 		return &Response{Code: 404, Message: "房间不存在"}, nil
 	} else if err != nil {
-		return &Response{Code: 500, Message: "查询房间失败"}, nil
+		return failed(ctx, "查询房间失败"), nil
 	}
 
 	if status != 1 {
 		return &Response{Code: 400, Message: "房间已结算"}, nil
 	}
 
+	// 从群聊中的分享卡片打开时，把房间关联到该群，须校验登录态
+	if req.ShareEncryptedData != "" {
+		if resp := s.checkRoomUser(ctx, req.SessionID, req.UserId); resp != nil {
+			return resp, nil
+		}
+		s.attachShareGroup(ctx, roomID, req.UserId, req.ShareGroup)
+	}
+
 	// 检查是否已经在房间中
 	var exists int
-	s.db.QueryRow(`
+	s.db.QueryRowContext(ctx, `
 		SELECT COUNT(*) FROM room_players 
 		WHERE room_id = ? AND user_id = ?
 	`, roomID, req.UserId).Scan(&exists)
@@ -276,13 +468,13 @@ func (s *MahjongService) JoinRoom(ctx context.Context, req *JoinRoomRequest) (*R
 		var settledAt sql.NullTime
 		
 		// 获取房间详细信息
-		err := s.db.QueryRow(`
+		err := s.db.QueryRowContext(ctx, `
 			SELECT room_code, room_name, creator_id, created_at, settled_at
 			FROM rooms WHERE id = ?
 		`, roomID).Scan(&roomCode, &roomName, &creatorId, &createdAt, &settledAt)
 		
 		if err != nil {
-			return &Response{Code: 500, Message: "获取房间信息失败"}, nil
+			return failed(ctx, "获取房间信息失败"), nil
 		}
 		
 		// 返回与正常加入房间相同的数据结构
@@ -292,37 +484,64 @@ func (s *MahjongService) JoinRoom(ctx context.Context, req *JoinRoomRequest) (*R
 		}
 		
 		data, _ := json.Marshal(roomData)
-		logger.Info("已在房间中，返回房间数据", "data", string(data))
+		logger.InfoContext(ctx, "已在房间中，返回房间数据", "data", string(data))
 		return &Response{Code: 200, Message: "已在房间中", Data: string(data)}, nil
 	}
 
+	if !s.beginWrite() {
+		return &Response{Code: 503, Message: "服务正在重启，请稍后重试"}, nil
+	}
+	defer s.endWrite()
+
+	// 玩家记录和joined事件在同一事务中写入
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return failed(ctx, "开始事务失败"), nil
+	}
+	defer tx.Rollback()
+
+	// 锁定房间后再确认一次状态，避免与结算并发时加入已结算的房间
+	if resp := lockActiveRoom(ctx, tx, roomID); resp != nil {
+		return resp, nil
+	}
+
 	// 加入房间
-	logger.Info("JoinRoom: 插入玩家记录", "room_id", roomID, "user_id", req.UserId)
-	result, err := s.db.Exec(`
+	logger.InfoContext(ctx, "JoinRoom: 插入玩家记录", "room_id", roomID, "user_id", req.UserId)
+	result, err := tx.ExecContext(ctx, `
 		INSERT INTO room_players (room_id, user_id, current_score, final_score) 
 		VALUES (?, ?, 0, 0)
 	`, roomID, req.UserId)
 	
 	if err != nil {
-		logger.Error("JoinRoom: 插入玩家记录失败", "error", err.Error())
-		return &Response{Code: 500, Message: "加入房间失败"}, nil
+		logger.ErrorContext(ctx, "JoinRoom: 插入玩家记录失败", "error", err.Error())
+		return failed(ctx, "加入房间失败"), nil
 	}
 	
 	rowsAffected, _ := result.RowsAffected()
-	logger.Info("JoinRoom: 成功插入玩家记录", "rows_affected", rowsAffected)
+
+	if err := appendRoomEvent(ctx, tx, &RoomEvent{RoomId: roomID, EventType: RoomEventJoined, UserId: req.UserId}); err != nil {
+		return failed(ctx, "记录房间事件失败"), nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return failed(ctx, "提交事务失败"), nil
+	}
+	logger.InfoContext(ctx, "JoinRoom: 成功插入玩家记录", "rows_affected", rowsAffected)
 
 	// 更新用户最近房间
-	s.updateRecentRoom(req.UserId, roomID)
+	s.updateRecentRoom(ctx, req.UserId, roomID)
+
+	groupID := s.roomGroupID(ctx, roomID)
 
 	// 获取房间的room_code
 	var roomCode string
-	s.db.QueryRow("SELECT room_code FROM rooms WHERE id = ?", roomID).Scan(&roomCode)
+	s.db.QueryRowContext(ctx, "SELECT room_code FROM rooms WHERE id = ?", roomID).Scan(&roomCode)
 
 	// 获取新加入玩家的信息用于广播
 	var userID int64
 	var nickname, avatarUrl string
 	var currentScore, finalScore int32
-	err = s.db.QueryRow(`
+	err = s.db.QueryRowContext(ctx, `
 		SELECT u.id, u.nickname, u.avatar_url, rp.current_score, rp.final_score
 		FROM users u
 		JOIN room_players rp ON u.id = rp.user_id
@@ -332,14 +551,15 @@ func (s *MahjongService) JoinRoom(ctx context.Context, req *JoinRoomRequest) (*R
 	)
 	
 	if err == nil {
-		// 广播玩家加入事件
-		s.broadcastToRoom(roomID, "player_joined", map[string]interface{}{
-			"player": map[string]interface{}{
-				"user_id":       userID,
-				"nickname":      nickname,
-				"avatar_url":    avatarUrl,
-				"current_score": currentScore,
-				"final_score":   finalScore,
+		// 发布玩家加入事件
+		s.publish(ctx, &events.PlayerJoined{
+			RoomId: roomID,
+			Player: events.Player{
+				UserId:       userID,
+				Nickname:     nickname,
+				AvatarUrl:    avatarUrl,
+				CurrentScore: currentScore,
+				FinalScore:   finalScore,
 			},
 		})
 	}
@@ -347,50 +567,81 @@ func (s *MahjongService) JoinRoom(ctx context.Context, req *JoinRoomRequest) (*R
 	roomData := map[string]interface{}{
 		"room_id":   roomID,
 		"room_code": roomCode,
+		"group_id":  groupID,
 	}
 	
 	data, _ := json.Marshal(roomData)
 	return &Response{Code: 200, Message: "加入成功", Data: string(data)}, nil
 }
 
+// RoomConnectionUser 由登录态确定WebSocket连接的用户并确认其已加入房间，连接上的命令都以该用户执行；
+// 失败时返回给客户端的响应
+func (s *MahjongService) RoomConnectionUser(ctx context.Context, sessionID string, roomID int64) (int64, *Response) {
+	userID, err := s.userIDBySession(ctx, sessionID)
+	if errors.Is(err, ErrSessionInvalid) {
+		return 0, &Response{Code: 401, Message: err.Error()}
+	}
+	if err != nil {
+		return 0, failed(ctx, "查询登录态失败")
+	}
+
+	var count int
+	err = s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM room_players WHERE room_id = ? AND user_id = ?
+	`, roomID, userID).Scan(&count)
+	if err != nil {
+		return 0, failed(ctx, "查询房间玩家失败")
+	}
+	if count == 0 {
+		return 0, &Response{Code: 403, Message: "未加入该房间"}
+	}
+	return userID, nil
+}
+
 // 获取房间信息
 func (s *MahjongService) GetRoom(ctx context.Context, req *GetRoomRequest) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "MahjongService.GetRoom", trace.WithAttributes(attribute.Int64("room_id", req.RoomId)))
+	defer span.End()
+
 	var query string
 	var args []interface{}
 	
 	// 添加调试日志
-	logger.Debug("GetRoom请求", "room_id", req.RoomId, "room_code", req.RoomCode)
+	logger.DebugContext(ctx, "GetRoom请求", "room_id", req.RoomId, "room_code", req.RoomCode)
 	
 	// 优先使用room_id，如果没有则使用room_code
 	if req.RoomId > 0 {
 		query = "SELECT id, room_code, room_name, creator_id, status, created_at, settled_at FROM rooms WHERE id = ?"
 		args = []interface{}{req.RoomId}
-		logger.Debug("使用room_id查询", "room_id", req.RoomId)
+		logger.DebugContext(ctx, "使用room_id查询", "room_id", req.RoomId)
 	} else if req.RoomCode != "" {
 		query = "SELECT id, room_code, room_name, creator_id, status, created_at, settled_at FROM rooms WHERE room_code = ?"
 		args = []interface{}{req.RoomCode}
-		logger.Debug("使用room_code查询", "room_code", req.RoomCode)
+		logger.DebugContext(ctx, "使用room_code查询", "room_code", req.RoomCode)
 	} else {
-		logger.Warn("缺少房间标识")
+		logger.WarnContext(ctx, "缺少房间标识")
 		return &Response{Code: 400, Message: "缺少房间标识"}, nil
 	}
 
-	logger.Debug("执行查询", "query", query, "args", args)
+	logger.DebugContext(ctx, "执行查询", "query", query, "args", args)
 
 	room := &Room{}
 	var createdAt time.Time
 	var settledAt sql.NullTime
-	err := s.db.QueryRow(query, args...).Scan(
+	err := s.db.QueryRowContext(ctx, query, args...).Scan(
 		&room.Id, &room.RoomCode, &room.RoomName, &room.CreatorId, 
 		&room.Status, &createdAt, &settledAt,
 	)
 	
+	if err != nil && err != sql.ErrNoRows {
+		logger.ErrorContext(ctx, "查询错误", "error", err.Error())
+		return failed(ctx, "查询房间失败"), nil
+	}
 	if err != nil {
-		logger.Error("查询错误", "error", err.Error())
 		return &Response{Code: 404, Message: "房间不存在"}, nil
 	}
 	
-	logger.Info("查询成功", "room_id", room.Id, "room_code", room.RoomCode)
+	logger.InfoContext(ctx, "查询成功", "room_id", room.Id, "room_code", room.RoomCode)
 
 	// 转换时间戳
 	room.CreatedAt = createdAt
@@ -401,9 +652,9 @@ func (s *MahjongService) GetRoom(ctx context.Context, req *GetRoomRequest) (*Res
 	}
 
 	// 获取房间玩家
-	players, err := s.getRoomPlayers(room.Id)
+	players, err := s.getRoomPlayers(ctx, room.Id)
 	if err != nil {
-		return &Response{Code: 500, Message: "获取玩家信息失败"}, nil
+		return failed(ctx, "获取玩家信息失败"), nil
 	}
 	room.Players = players
 
@@ -413,9 +664,12 @@ func (s *MahjongService) GetRoom(ctx context.Context, req *GetRoomRequest) (*Res
 
 // 获取房间玩家
 func (s *MahjongService) GetRoomPlayers(ctx context.Context, req *GetRoomPlayersRequest) (*Response, error) {
-	players, err := s.getRoomPlayers(req.RoomId)
+	ctx, span := tracer.Start(ctx, "MahjongService.GetRoomPlayers", trace.WithAttributes(attribute.Int64("room_id", req.RoomId)))
+	defer span.End()
+
+	players, err := s.getRoomPlayers(ctx, req.RoomId)
 	if err != nil {
-		return &Response{Code: 500, Message: "获取玩家信息失败"}, nil
+		return failed(ctx, "获取玩家信息失败"), nil
 	}
 
 	playersData, _ := json.Marshal(players)
@@ -424,6 +678,9 @@ func (s *MahjongService) GetRoomPlayers(ctx context.Context, req *GetRoomPlayers
 
 // 获取房间转移记录
 func (s *MahjongService) GetRoomTransfers(ctx context.Context, req *GetRoomTransfersRequest) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "MahjongService.GetRoomTransfers", trace.WithAttributes(attribute.Int64("room_id", req.RoomId)))
+	defer span.End()
+
 	var query string
 	var args []interface{}
 	
@@ -432,7 +689,7 @@ func (s *MahjongService) GetRoomTransfers(ctx context.Context, req *GetRoomTrans
 		// 增量更新：获取ID大于lastTransferId的记录
 		query = `
 			SELECT st.id, st.room_id, st.from_user_id, st.to_user_id, st.amount, st.created_at,
-			       u1.nickname as from_user_name, u2.nickname as to_user_name
+			       u1.nickname as from_user_name, u2.nickname as to_user_name, st.voided_at IS NOT NULL
 			FROM score_transfers st
 			LEFT JOIN users u1 ON st.from_user_id = u1.id
 			LEFT JOIN users u2 ON st.to_user_id = u2.id
@@ -445,7 +702,7 @@ func (s *MahjongService) GetRoomTransfers(ctx context.Context, req *GetRoomTrans
 		// 全量获取：获取最新的100条记录
 		query = `
 			SELECT st.id, st.room_id, st.from_user_id, st.to_user_id, st.amount, st.created_at,
-			       u1.nickname as from_user_name, u2.nickname as to_user_name
+			       u1.nickname as from_user_name, u2.nickname as to_user_name, st.voided_at IS NOT NULL
 			FROM score_transfers st
 			LEFT JOIN users u1 ON st.from_user_id = u1.id
 			LEFT JOIN users u2 ON st.to_user_id = u2.id
@@ -456,9 +713,9 @@ func (s *MahjongService) GetRoomTransfers(ctx context.Context, req *GetRoomTrans
 		args = []interface{}{req.RoomId}
 	}
 	
-	rows, err := s.db.Query(query, args...)
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return &Response{Code: 500, Message: "查询转移记录失败"}, nil
+		return failed(ctx, "查询转移记录失败"), nil
 	}
 	defer rows.Close()
 
@@ -468,6 +725,7 @@ func (s *MahjongService) GetRoomTransfers(ctx context.Context, req *GetRoomTrans
 		err := rows.Scan(
 			&transfer.Id, &transfer.RoomId, &transfer.FromUserId, &transfer.ToUserId,
 			&transfer.Amount, &transfer.CreatedAt, &transfer.FromUserName, &transfer.ToUserName,
+			&transfer.Voided,
 		)
 		if err != nil {
 			continue
@@ -486,22 +744,229 @@ func (s *MahjongService) GetRoomTransfers(ctx context.Context, req *GetRoomTrans
 	return &Response{Code: 200, Message: "获取成功", Data: string(transfersData)}, nil
 }
 
+// roundGap 按局聚合时，同一赢家的两次转移间隔超过该时间视为新的一局。
+// 转移不记录所属的局，按局聚合是启发式的：一局和牌后输家依次向赢家转移，
+// 收款人变化或与上一笔间隔超过roundGap就算新的一局
+const roundGap = 2 * time.Minute
+
+// 获取房间分数曲线（按顺序回放未撤销的score_transfers，得到每次转移后各玩家的累计分数）
+func (s *MahjongService) GetRoomScoreSeries(ctx context.Context, req *GetRoomScoreSeriesRequest) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "MahjongService.GetRoomScoreSeries", trace.WithAttributes(attribute.Int64("room_id", req.RoomId)))
+	defer span.End()
+
+	if req.BucketMinutes < 0 {
+		return &Response{Code: 400, Message: "bucket_minutes不能为负数"}, nil
+	}
+	if req.ByRound && req.BucketMinutes > 0 {
+		return &Response{Code: 400, Message: "by_round和bucket_minutes不能同时使用"}, nil
+	}
+
+	// 撤销的转移可能在游标之前，增量回放无法反映；撤销数与客户端上次拿到的不同时改为全量返回
+	var voidedCount int32
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM score_transfers WHERE room_id = ? AND voided_at IS NOT NULL
+	`, req.RoomId).Scan(&voidedCount)
+	if err != nil {
+		return failed(ctx, "查询转移记录失败"), nil
+	}
+
+	lastTransferID := req.LastTransferId
+	reset := false
+	if lastTransferID > 0 && voidedCount != req.VoidedCount {
+		lastTransferID = 0
+		reset = true
+	}
+
+	series := &ScoreSeries{
+		RoomId:         req.RoomId,
+		Players:        []*SeriesPlayer{},
+		Points:         []*ScoreSeriesPoint{},
+		LastTransferId: lastTransferID,
+		VoidedCount:    voidedCount,
+		Reset:          reset,
+	}
+
+	// 获取房间玩家，所有玩家初始分数为0
+	scores := make(map[int64]int32)
+	playerRows, err := s.db.QueryContext(ctx, `
+		SELECT rp.user_id, u.nickname
+		FROM room_players rp
+		LEFT JOIN users u ON rp.user_id = u.id
+		WHERE rp.room_id = ?
+		ORDER BY rp.joined_at ASC
+	`, req.RoomId)
+	if err != nil {
+		return failed(ctx, "获取玩家信息失败"), nil
+	}
+	defer playerRows.Close()
+
+	for playerRows.Next() {
+		var userID int64
+		var nickname sql.NullString
+		if err := playerRows.Scan(&userID, &nickname); err != nil {
+			return failed(ctx, "获取玩家信息失败"), nil
+		}
+		series.Players = append(series.Players, &SeriesPlayer{UserId: userID, Nickname: nickname.String})
+		scores[userID] = 0
+	}
+	if err := playerRows.Err(); err != nil {
+		return failed(ctx, "获取玩家信息失败"), nil
+	}
+
+	// 按局聚合时局数需要从头计算，始终从头回放，只返回last_transfer_id之后有变化的局
+	replayFrom := lastTransferID
+	if req.ByRound {
+		replayFrom = 0
+	}
+
+	// 增量获取时，先汇总last_transfer_id之前的转移作为起点
+	if replayFrom > 0 {
+		baseRows, err := s.db.QueryContext(ctx, `
+			SELECT from_user_id, to_user_id, SUM(amount)
+			FROM score_transfers
+			WHERE room_id = ? AND id <= ? AND voided_at IS NULL
+			GROUP BY from_user_id, to_user_id
+		`, req.RoomId, lastTransferID)
+		if err != nil {
+			return failed(ctx, "查询转移记录失败"), nil
+		}
+		defer baseRows.Close()
+
+		for baseRows.Next() {
+			var fromUserID, toUserID int64
+			var amount int32
+			if err := baseRows.Scan(&fromUserID, &toUserID, &amount); err != nil {
+				return failed(ctx, "读取转移记录失败"), nil
+			}
+			scores[fromUserID] -= amount
+			scores[toUserID] += amount
+		}
+		if err := baseRows.Err(); err != nil {
+			return failed(ctx, "读取转移记录失败"), nil
+		}
+	}
+
+	// 按ID顺序回放之后的转移
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, from_user_id, to_user_id, amount, created_at
+		FROM score_transfers
+		WHERE room_id = ? AND id > ? AND voided_at IS NULL
+		ORDER BY id ASC
+	`, req.RoomId, replayFrom)
+	if err != nil {
+		return failed(ctx, "查询转移记录失败"), nil
+	}
+	defer rows.Close()
+
+	bucketSeconds := int64(req.BucketMinutes) * 60
+	var round int32
+	var roundWinner int64
+	var roundEnd time.Time
+	for rows.Next() {
+		var transferID, fromUserID, toUserID int64
+		var amount int32
+		var createdAt time.Time
+		if err := rows.Scan(&transferID, &fromUserID, &toUserID, &amount, &createdAt); err != nil {
+			return failed(ctx, "读取转移记录失败"), nil
+		}
+
+		scores[fromUserID] -= amount
+		scores[toUserID] += amount
+
+		if req.ByRound {
+			if round == 0 || toUserID != roundWinner || createdAt.Sub(roundEnd) > roundGap {
+				round++
+			}
+			roundWinner, roundEnd = toUserID, createdAt
+			series.LastTransferId = transferID
+			if transferID <= lastTransferID {
+				continue
+			}
+			point := &ScoreSeriesPoint{
+				TransferId: transferID,
+				Timestamp:  createdAt.Unix(),
+				Round:      round,
+				Scores:     copyScores(scores),
+			}
+			// 同一局只保留最后一次转移后的分数；增量获取时客户端按round替换已有的点
+			if n := len(series.Points); n > 0 && series.Points[n-1].Round == round {
+				series.Points[n-1] = point
+			} else {
+				series.Points = append(series.Points, point)
+			}
+			continue
+		}
+
+		timestamp := createdAt.Unix()
+		if bucketSeconds > 0 {
+			// 时间桶的起始时间作为该点的时间戳，同一桶内只保留最后一次转移后的分数
+			timestamp = timestamp - timestamp%bucketSeconds
+		}
+
+		point := &ScoreSeriesPoint{
+			TransferId: transferID,
+			Timestamp:  timestamp,
+			Scores:     copyScores(scores),
+		}
+		if n := len(series.Points); bucketSeconds > 0 && n > 0 && series.Points[n-1].Timestamp == timestamp {
+			series.Points[n-1] = point
+		} else {
+			series.Points = append(series.Points, point)
+		}
+		series.LastTransferId = transferID
+	}
+	if err := rows.Err(); err != nil {
+		return failed(ctx, "读取转移记录失败"), nil
+	}
+	if series.LastTransferId < lastTransferID {
+		series.LastTransferId = lastTransferID
+	}
+
+	seriesData, _ := json.Marshal(series)
+	return &Response{Code: 200, Message: "获取成功", Data: string(seriesData)}, nil
+}
+
+// 复制分数快照，避免后续回放修改已生成的点
+func copyScores(scores map[int64]int32) map[int64]int32 {
+	snapshot := make(map[int64]int32, len(scores))
+	for userID, score := range scores {
+		snapshot[userID] = score
+	}
+	return snapshot
+}
+
 // 转移分数
 func (s *MahjongService) TransferScore(ctx context.Context, req *TransferScoreRequest) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "MahjongService.TransferScore", trace.WithAttributes(attribute.Int64("room_id", req.RoomId)))
+	defer span.End()
+
+	if !s.beginWrite() {
+		return &Response{Code: 503, Message: "服务正在重启，请稍后重试"}, nil
+	}
+	defer s.endWrite()
+
 	// 开始事务
-	tx, err := s.db.Begin()
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return &Response{Code: 500, Message: "开始事务失败"}, nil
+		return failed(ctx, "开始事务失败"), nil
 	}
 	defer tx.Rollback()
 
+	// 锁定房间，已结算的房间不能再转移
+	if resp := lockActiveRoom(ctx, tx, req.RoomId); resp != nil {
+		return resp, nil
+	}
+
 	// 检查转出用户分数是否足够
 	var fromScore int32
-	err = tx.QueryRow(`
+	err = tx.QueryRowContext(ctx, `
 		SELECT current_score FROM room_players 
 		WHERE room_id = ? AND user_id = ?
 	`, req.RoomId, req.FromUserId).Scan(&fromScore)
 	
+	if err != nil && err != sql.ErrNoRows {
+		return failed(ctx, "查询转出用户分数失败"), nil
+	}
 	if err != nil {
 		return &Response{Code: 404, Message: "转出用户不在房间中"}, nil
 	}
@@ -509,80 +974,213 @@ func (s *MahjongService) TransferScore(ctx context.Context, req *TransferScoreRe
 	// 允许分数为负数，移除分数不足检查
 
 	// 更新转出用户分数
-	_, err = tx.Exec(`
+	_, err = tx.ExecContext(ctx, `
 		UPDATE room_players 
 		SET current_score = current_score - ? 
 		WHERE room_id = ? AND user_id = ?
 	`, req.Amount, req.RoomId, req.FromUserId)
 	
 	if err != nil {
-		return &Response{Code: 500, Message: "更新转出用户分数失败"}, nil
+		return failed(ctx, "更新转出用户分数失败"), nil
 	}
 
 	// 更新转入用户分数
-	_, err = tx.Exec(`
+	_, err = tx.ExecContext(ctx, `
 		UPDATE room_players 
 		SET current_score = current_score + ? 
 		WHERE room_id = ? AND user_id = ?
 	`, req.Amount, req.RoomId, req.ToUserId)
 	
 	if err != nil {
-		return &Response{Code: 500, Message: "更新转入用户分数失败"}, nil
+		return failed(ctx, "更新转入用户分数失败"), nil
 	}
 
 	// 记录转移
-	_, err = tx.Exec(`
+	result, err := tx.ExecContext(ctx, `
 		INSERT INTO score_transfers (room_id, from_user_id, to_user_id, amount) 
 		VALUES (?, ?, ?, ?)
 	`, req.RoomId, req.FromUserId, req.ToUserId, req.Amount)
 	
 	if err != nil {
-		return &Response{Code: 500, Message: "记录转移失败"}, nil
+		return failed(ctx, "记录转移失败"), nil
+	}
+
+	transferID, _ := result.LastInsertId()
+	err = appendRoomEvent(ctx, tx, &RoomEvent{
+		RoomId:       req.RoomId,
+		EventType:    RoomEventTransferred,
+		UserId:       req.FromUserId,
+		TargetUserId: req.ToUserId,
+		Amount:       req.Amount,
+		TransferId:   transferID,
+	})
+	if err != nil {
+		return failed(ctx, "记录房间事件失败"), nil
 	}
 
 	// 提交事务
 	if err = tx.Commit(); err != nil {
-		return &Response{Code: 500, Message: "提交事务失败"}, nil
+		return failed(ctx, "提交事务失败"), nil
 	}
 
 	// 获取转移双方的昵称用于广播
 	var fromUserName, toUserName string
-	s.db.QueryRow("SELECT nickname FROM users WHERE id = ?", req.FromUserId).Scan(&fromUserName)
-	s.db.QueryRow("SELECT nickname FROM users WHERE id = ?", req.ToUserId).Scan(&toUserName)
-
-	// 广播分数转移事件
-	s.broadcastToRoom(req.RoomId, "score_transfer", map[string]interface{}{
-		"transfer": map[string]interface{}{
-			"from_user_id":   req.FromUserId,
-			"to_user_id":     req.ToUserId,
-			"from_user_name": fromUserName,
-			"to_user_name":   toUserName,
-			"amount":         req.Amount,
+	s.db.QueryRowContext(ctx, "SELECT nickname FROM users WHERE id = ?", req.FromUserId).Scan(&fromUserName)
+	s.db.QueryRowContext(ctx, "SELECT nickname FROM users WHERE id = ?", req.ToUserId).Scan(&toUserName)
+
+	// 发布分数转移事件
+	s.publish(ctx, &events.ScoreTransferred{
+		RoomId: req.RoomId,
+		Transfer: events.Transfer{
+			Id:           transferID,
+			FromUserId:   req.FromUserId,
+			ToUserId:     req.ToUserId,
+			FromUserName: fromUserName,
+			ToUserName:   toUserName,
+			Amount:       req.Amount,
 		},
 	})
 
 	return &Response{Code: 200, Message: "转移成功"}, nil
 }
 
+// 撤销分数转移
+func (s *MahjongService) VoidTransfer(ctx context.Context, req *VoidTransferRequest) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "MahjongService.VoidTransfer", trace.WithAttributes(attribute.Int64("room_id", req.RoomId)))
+	defer span.End()
+
+	if !s.beginWrite() {
+		return &Response{Code: 503, Message: "服务正在重启，请稍后重试"}, nil
+	}
+	defer s.endWrite()
+
+	// 开始事务
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return failed(ctx, "开始事务失败"), nil
+	}
+	defer tx.Rollback()
+
+	if resp := lockActiveRoom(ctx, tx, req.RoomId); resp != nil {
+		return resp, nil
+	}
+
+	// 锁定转移记录，避免重复撤销
+	var fromUserID, toUserID int64
+	var amount int32
+	var voidedAt sql.NullTime
+	err = tx.QueryRowContext(ctx, `
+		SELECT from_user_id, to_user_id, amount, voided_at
+		FROM score_transfers
+		WHERE id = ? AND room_id = ?
+		FOR UPDATE
+	`, req.TransferId, req.RoomId).Scan(&fromUserID, &toUserID, &amount, &voidedAt)
+
+	if err == sql.ErrNoRows {
+		return &Response{Code: 404, Message: "转移记录不存在"}, nil
+	} else if err != nil {
+		return failed(ctx, "查询转移记录失败"), nil
+	}
+
+	// 只有转出的玩家可以撤销自己的转移
+	if req.UserId != fromUserID {
+		return &Response{Code: 403, Message: "只能撤销自己转出的分数"}, nil
+	}
+
+	if voidedAt.Valid {
+		return &Response{Code: 400, Message: "转移已撤销"}, nil
+	}
+
+	// 冲正双方分数
+	_, err = tx.ExecContext(ctx, `
+		UPDATE room_players 
+		SET current_score = current_score + ? 
+		WHERE room_id = ? AND user_id = ?
+	`, amount, req.RoomId, fromUserID)
+
+	if err != nil {
+		return failed(ctx, "更新转出用户分数失败"), nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE room_players 
+		SET current_score = current_score - ? 
+		WHERE room_id = ? AND user_id = ?
+	`, amount, req.RoomId, toUserID)
+
+	if err != nil {
+		return failed(ctx, "更新转入用户分数失败"), nil
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE score_transfers SET voided_at = NOW() WHERE id = ?", req.TransferId)
+	if err != nil {
+		return failed(ctx, "更新转移记录失败"), nil
+	}
+
+	err = appendRoomEvent(ctx, tx, &RoomEvent{
+		RoomId:       req.RoomId,
+		EventType:    RoomEventVoided,
+		UserId:       fromUserID,
+		TargetUserId: toUserID,
+		Amount:       amount,
+		TransferId:   req.TransferId,
+	})
+	if err != nil {
+		return failed(ctx, "记录房间事件失败"), nil
+	}
+
+	// 提交事务
+	if err = tx.Commit(); err != nil {
+		return failed(ctx, "提交事务失败"), nil
+	}
+
+	// 发布撤销事件
+	s.publish(ctx, &events.TransferVoided{
+		RoomId: req.RoomId,
+		Transfer: events.Transfer{
+			Id:         req.TransferId,
+			FromUserId: fromUserID,
+			ToUserId:   toUserID,
+			Amount:     amount,
+		},
+		OperatorId: req.UserId,
+	})
+
+	return &Response{Code: 200, Message: "撤销成功"}, nil
+}
+
 // 结算房间
 func (s *MahjongService) SettleRoom(ctx context.Context, req *SettleRoomRequest) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "MahjongService.SettleRoom", trace.WithAttributes(attribute.Int64("room_id", req.RoomId)))
+	defer span.End()
+
+	if !s.beginWrite() {
+		return &Response{Code: 503, Message: "服务正在重启，请稍后重试"}, nil
+	}
+	defer s.endWrite()
+
 	// 开始事务
-	tx, err := s.db.Begin()
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return &Response{Code: 500, Message: "开始事务失败"}, nil
+		return failed(ctx, "开始事务失败"), nil
 	}
 	defer tx.Rollback()
 
+	// 锁定房间，同一房间只能结算一次，结算时不会有转移插入
+	if resp := lockActiveRoom(ctx, tx, req.RoomId); resp != nil {
+		return resp, nil
+	}
+
 	// 获取所有玩家分数
-	rows, err := tx.Query(`
-		SELECT rp.user_id, rp.current_score, u.nickname
+	rows, err := tx.QueryContext(ctx, `
+		SELECT rp.user_id, rp.current_score, COALESCE(u.nickname, '')
 		FROM room_players rp
 		LEFT JOIN users u ON rp.user_id = u.id
 		WHERE rp.room_id = ?
 	`, req.RoomId)
 	
 	if err != nil {
-		return &Response{Code: 500, Message: "获取玩家分数失败"}, nil
+		return failed(ctx, "获取玩家分数失败"), nil
 	}
 	defer rows.Close()
 
@@ -598,55 +1196,83 @@ func (s *MahjongService) SettleRoom(ctx context.Context, req *SettleRoomRequest)
 			Score    int32
 			Nickname string
 		}
-		rows.Scan(&player.UserID, &player.Score, &player.Nickname)
+		if err := rows.Scan(&player.UserID, &player.Score, &player.Nickname); err != nil {
+			return failed(ctx, "读取玩家分数失败"), nil
+		}
 		players = append(players, player)
 	}
+	if err := rows.Err(); err != nil {
+		return failed(ctx, "读取玩家分数失败"), nil
+	}
+	rows.Close()
 
 	// 计算最优转账方案
 	settlements := s.calculateOptimalSettlement(players)
 
 	// 记录结算
 	for _, settlement := range settlements {
-		_, err = tx.Exec(`
+		_, err = tx.ExecContext(ctx, `
 			INSERT INTO settlements (room_id, from_user_id, to_user_id, amount) 
 			VALUES (?, ?, ?, ?)
 		`, req.RoomId, settlement.FromUserId, settlement.ToUserId, settlement.Amount)
 		
 		if err != nil {
-			return &Response{Code: 500, Message: "记录结算失败"}, nil
+			return failed(ctx, "记录结算失败"), nil
 		}
 	}
 
 	// 更新房间状态
-	_, err = tx.Exec(`
+	_, err = tx.ExecContext(ctx, `
 		UPDATE rooms SET status = 2, settled_at = NOW() WHERE id = ?
 	`, req.RoomId)
 	
 	if err != nil {
-		return &Response{Code: 500, Message: "更新房间状态失败"}, nil
+		return failed(ctx, "更新房间状态失败"), nil
+	}
+
+	err = appendRoomEvent(ctx, tx, &RoomEvent{RoomId: req.RoomId, EventType: RoomEventSettled, UserId: req.UserId})
+	if err != nil {
+		return failed(ctx, "记录房间事件失败"), nil
 	}
 
 	// 更新玩家最终分数
 	for _, player := range players {
-		_, err = tx.Exec(`
+		_, err = tx.ExecContext(ctx, `
 			UPDATE room_players SET final_score = ? WHERE room_id = ? AND user_id = ?
 		`, player.Score, req.RoomId, player.UserID)
 		
 		if err != nil {
-			return &Response{Code: 500, Message: "更新最终分数失败"}, nil
+			return failed(ctx, "更新最终分数失败"), nil
 		}
 	}
 
 	// 提交事务
 	if err = tx.Commit(); err != nil {
-		return &Response{Code: 500, Message: "提交事务失败"}, nil
+		return failed(ctx, "提交事务失败"), nil
 	}
 
-	// 广播房间结算事件
-	s.broadcastToRoom(req.RoomId, "room_settled", map[string]interface{}{
-		"settlements": settlements,
-		"players":     players,
-	})
+	// 发布房间结算事件
+	settled := &events.RoomSettled{
+		RoomId:      req.RoomId,
+		SettledBy:   req.UserId,
+		Settlements: make([]events.Settlement, 0, len(settlements)),
+		Players:     make([]events.SettledPlayer, 0, len(players)),
+	}
+	for _, settlement := range settlements {
+		settled.Settlements = append(settled.Settlements, events.Settlement{
+			FromUserId: settlement.FromUserId,
+			ToUserId:   settlement.ToUserId,
+			Amount:     settlement.Amount,
+		})
+	}
+	for _, player := range players {
+		settled.Players = append(settled.Players, events.SettledPlayer{
+			UserId:   player.UserID,
+			Nickname: player.Nickname,
+			Score:    player.Score,
+		})
+	}
+	s.publish(ctx, settled)
 
 	settlementsData, _ := json.Marshal(settlements)
 	return &Response{Code: 200, Message: "结算成功", Data: string(settlementsData)}, nil
@@ -725,6 +1351,9 @@ func min(a, b int32) int32 {
 
 // 获取用户房间列表
 func (s *MahjongService) GetUserRooms(ctx context.Context, req *GetUserRoomsRequest) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "MahjongService.GetUserRooms")
+	defer span.End()
+
 	// 限制最大返回100条记录
 	if req.PageSize > 100 {
 		req.PageSize = 100
@@ -732,7 +1361,7 @@ func (s *MahjongService) GetUserRooms(ctx context.Context, req *GetUserRoomsRequ
 	
 	offset := (req.Page - 1) * req.PageSize
 	
-	rows, err := s.db.Query(`
+	rows, err := s.db.QueryContext(ctx, `
 		SELECT r.id, r.room_code, r.room_name, r.status, r.created_at, r.settled_at,
 		       rp.current_score, rp.final_score,
 		       (SELECT COUNT(*) FROM room_players WHERE room_id = r.id) as player_count,
@@ -745,7 +1374,7 @@ func (s *MahjongService) GetUserRooms(ctx context.Context, req *GetUserRoomsRequ
 	`, req.UserId, req.PageSize, offset)
 	
 	if err != nil {
-		return &Response{Code: 500, Message: "查询房间列表失败"}, nil
+		return failed(ctx, "查询房间列表失败"), nil
 	}
 	defer rows.Close()
 
@@ -787,9 +1416,12 @@ func (s *MahjongService) GetUserRooms(ctx context.Context, req *GetUserRoomsRequ
 
 // 获取房间详情
 func (s *MahjongService) GetRoomDetail(ctx context.Context, req *GetRoomDetailRequest) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "MahjongService.GetRoomDetail", trace.WithAttributes(attribute.Int64("room_id", req.RoomId)))
+	defer span.End()
+
 	// 获取房间基本信息
 	room := &Room{}
-	err := s.db.QueryRow(`
+	err := s.db.QueryRowContext(ctx, `
 		SELECT id, room_code, room_name, creator_id, status, created_at, settled_at 
 		FROM rooms WHERE id = ?
 	`, req.RoomId).Scan(
@@ -797,27 +1429,30 @@ func (s *MahjongService) GetRoomDetail(ctx context.Context, req *GetRoomDetailRe
 		&room.Status, &room.CreatedAt, &room.SettledAt,
 	)
 	
+	if err != nil && err != sql.ErrNoRows {
+		return failed(ctx, "查询房间失败"), nil
+	}
 	if err != nil {
 		return &Response{Code: 404, Message: "房间不存在"}, nil
 	}
 
 	// 获取玩家信息
-	players, err := s.getRoomPlayers(req.RoomId)
+	players, err := s.getRoomPlayers(ctx, req.RoomId)
 	if err != nil {
-		return &Response{Code: 500, Message: "获取玩家信息失败"}, nil
+		return failed(ctx, "获取玩家信息失败"), nil
 	}
 	room.Players = players
 
 	// 获取转移记录
-	transfers, err := s.getRoomTransfers(req.RoomId)
+	transfers, err := s.getRoomTransfers(ctx, req.RoomId)
 	if err != nil {
-		return &Response{Code: 500, Message: "获取转移记录失败"}, nil
+		return failed(ctx, "获取转移记录失败"), nil
 	}
 
 	// 获取结算记录
-	settlements, err := s.getRoomSettlements(req.RoomId)
+	settlements, err := s.getRoomSettlements(ctx, req.RoomId)
 	if err != nil {
-		return &Response{Code: 500, Message: "获取结算记录失败"}, nil
+		return failed(ctx, "获取结算记录失败"), nil
 	}
 
 	detail := map[string]interface{}{
@@ -832,8 +1467,11 @@ func (s *MahjongService) GetRoomDetail(ctx context.Context, req *GetRoomDetailRe
 
 // 获取最近房间
 func (s *MahjongService) GetRecentRoom(ctx context.Context, req *GetUserRequest) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "MahjongService.GetRecentRoom")
+	defer span.End()
+
 	// 查询最近20个房间，限制数量以优化性能
-	rows, err := s.db.Query(`
+	rows, err := s.db.QueryContext(ctx, `
 		SELECT r.id, r.room_code, r.room_name, r.status, r.created_at,
 		       rp.current_score,
 		       (SELECT COUNT(*) FROM room_players WHERE room_id = r.id) as player_count,
@@ -846,7 +1484,7 @@ func (s *MahjongService) GetRecentRoom(ctx context.Context, req *GetUserRequest)
 	`, req.UserId)
 	
 	if err != nil {
-		return &Response{Code: 500, Message: "查询最近房间失败"}, nil
+		return failed(ctx, "查询最近房间失败"), nil
 	}
 	defer rows.Close()
 
@@ -860,7 +1498,7 @@ func (s *MahjongService) GetRecentRoom(ctx context.Context, req *GetUserRequest)
 			&createdAt, &recentRoom.CurrentScore, &recentRoom.PlayerCount, &recentRoom.TransferCount,
 		)
 		if err != nil {
-			return &Response{Code: 500, Message: "解析房间数据失败"}, nil
+			return failed(ctx, "解析房间数据失败"), nil
 		}
 		
 		// 设置房间创建时间
@@ -879,7 +1517,7 @@ func (s *MahjongService) GetRecentRoom(ctx context.Context, req *GetUserRequest)
 // 辅助方法
 
 // 生成唯一的房间号（包含时间戳的字符串）
-func (s *MahjongService) generateUniqueRoomCode() string {
+func (s *MahjongService) generateUniqueRoomCode(ctx context.Context) string {
 	// 获取当前时间戳（毫秒）
 	timestamp := time.Now().UnixMilli()
 	
@@ -894,7 +1532,7 @@ func (s *MahjongService) generateUniqueRoomCode() string {
 	// 检查房间号是否已存在，如果存在则重新生成
 	for {
 		var exists int
-		err := s.db.QueryRow("SELECT COUNT(*) FROM rooms WHERE room_code = ?", roomCode).Scan(&exists)
+		err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM rooms WHERE room_code = ?", roomCode).Scan(&exists)
 		if err != nil || exists == 0 {
 			break
 		}
@@ -905,24 +1543,24 @@ func (s *MahjongService) generateUniqueRoomCode() string {
 	return roomCode
 }
 
-func (s *MahjongService) updateRecentRoom(userID, roomID int64) {
-	s.db.Exec(`
+func (s *MahjongService) updateRecentRoom(ctx context.Context, userID, roomID int64) {
+	s.db.ExecContext(ctx, `
 		INSERT INTO user_recent_rooms (user_id, room_id, last_accessed_at) 
 		VALUES (?, ?, NOW())
 		ON DUPLICATE KEY UPDATE last_accessed_at = NOW()
 	`, userID, roomID)
 }
 
-func (s *MahjongService) getRoomPlayers(roomID int64) ([]*RoomPlayer, error) {
-	logger.Debug("getRoomPlayers: 查询房间玩家", "room_id", roomID)
+func (s *MahjongService) getRoomPlayers(ctx context.Context, roomID int64) ([]*RoomPlayer, error) {
+	logger.DebugContext(ctx, "getRoomPlayers: 查询房间玩家", "room_id", roomID)
 	
 	// 先简单查询room_players表，看看是否有记录
 	var count int
-	err := s.db.QueryRow("SELECT COUNT(*) FROM room_players WHERE room_id = ?", roomID).Scan(&count)
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM room_players WHERE room_id = ?", roomID).Scan(&count)
 	if err != nil {
-		logger.Error("getRoomPlayers: 查询room_players表失败", "error", err.Error())
+		logger.ErrorContext(ctx, "getRoomPlayers: 查询room_players表失败", "error", err.Error())
 	} else {
-		logger.Debug("getRoomPlayers: room_players表记录数", "room_id", roomID, "count", count)
+		logger.DebugContext(ctx, "getRoomPlayers: room_players表记录数", "room_id", roomID, "count", count)
 	}
 	
 	query := `
@@ -933,12 +1571,12 @@ func (s *MahjongService) getRoomPlayers(roomID int64) ([]*RoomPlayer, error) {
 		WHERE rp.room_id = ?
 		ORDER BY rp.joined_at ASC
 	`
-	logger.Debug("getRoomPlayers: 执行查询", "query", query, "room_id", roomID)
+	logger.DebugContext(ctx, "getRoomPlayers: 执行查询", "query", query, "room_id", roomID)
 	
-	rows, err := s.db.Query(query, roomID)
+	rows, err := s.db.QueryContext(ctx, query, roomID)
 	
 	if err != nil {
-		logger.Error("getRoomPlayers: 查询失败", "error", err.Error())
+		logger.ErrorContext(ctx, "getRoomPlayers: 查询失败", "error", err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -946,9 +1584,9 @@ func (s *MahjongService) getRoomPlayers(roomID int64) ([]*RoomPlayer, error) {
 	var players []*RoomPlayer
 	playerCount := 0
 	
-	logger.Debug("getRoomPlayers: 开始遍历查询结果")
+	logger.DebugContext(ctx, "getRoomPlayers: 开始遍历查询结果")
 	for rows.Next() {
-		logger.Debug("getRoomPlayers: 处理行数据", "row_number", playerCount+1)
+		logger.DebugContext(ctx, "getRoomPlayers: 处理行数据", "row_number", playerCount+1)
 		player := &RoomPlayer{}
 		user := &User{}
 		
@@ -959,30 +1597,33 @@ func (s *MahjongService) getRoomPlayers(roomID int64) ([]*RoomPlayer, error) {
 			&user.CreatedAt, &user.UpdatedAt,
 		)
 		if err != nil {
-			logger.Error("getRoomPlayers: 扫描行数据失败", "error", err.Error())
+			logger.ErrorContext(ctx, "getRoomPlayers: 扫描行数据失败", "error", err.Error())
 			continue
 		}
 		
 		player.User = user
+		if s.presence != nil {
+			player.Online = s.presence.IsOnline(roomID, player.UserId)
+		}
 		players = append(players, player)
 		playerCount++
-		logger.Debug("getRoomPlayers: 找到玩家", "player_count", playerCount, "user_id", player.UserId, "nickname", user.Nickname, "current_score", player.CurrentScore)
+		logger.DebugContext(ctx, "getRoomPlayers: 找到玩家", "player_count", playerCount, "user_id", player.UserId, "nickname", user.Nickname, "current_score", player.CurrentScore)
 	}
 	
 	// 检查是否有行扫描错误
 	if err := rows.Err(); err != nil {
-		logger.Error("getRoomPlayers: 行扫描过程中出现错误", "error", err.Error())
+		logger.ErrorContext(ctx, "getRoomPlayers: 行扫描过程中出现错误", "error", err.Error())
 		return nil, err
 	}
 
-	logger.Info("getRoomPlayers: 查询完成", "total_players", playerCount)
+	logger.InfoContext(ctx, "getRoomPlayers: 查询完成", "total_players", playerCount)
 	return players, nil
 }
 
-func (s *MahjongService) getRoomTransfers(roomID int64) ([]*ScoreTransfer, error) {
-	rows, err := s.db.Query(`
+func (s *MahjongService) getRoomTransfers(ctx context.Context, roomID int64) ([]*ScoreTransfer, error) {
+	rows, err := s.db.QueryContext(ctx, `
 		SELECT st.id, st.room_id, st.from_user_id, st.to_user_id, st.amount, st.created_at,
-		       u1.nickname as from_user_name, u2.nickname as to_user_name
+		       u1.nickname as from_user_name, u2.nickname as to_user_name, st.voided_at IS NOT NULL
 		FROM score_transfers st
 		LEFT JOIN users u1 ON st.from_user_id = u1.id
 		LEFT JOIN users u2 ON st.to_user_id = u2.id
@@ -1001,6 +1642,7 @@ func (s *MahjongService) getRoomTransfers(roomID int64) ([]*ScoreTransfer, error
 		err := rows.Scan(
 			&transfer.Id, &transfer.RoomId, &transfer.FromUserId, &transfer.ToUserId,
 			&transfer.Amount, &transfer.CreatedAt, &transfer.FromUserName, &transfer.ToUserName,
+			&transfer.Voided,
 		)
 		if err != nil {
 			continue
@@ -1011,8 +1653,8 @@ func (s *MahjongService) getRoomTransfers(roomID int64) ([]*ScoreTransfer, error
 	return transfers, nil
 }
 
-func (s *MahjongService) getRoomSettlements(roomID int64) ([]*Settlement, error) {
-	rows, err := s.db.Query(`
+func (s *MahjongService) getRoomSettlements(ctx context.Context, roomID int64) ([]*Settlement, error) {
+	rows, err := s.db.QueryContext(ctx, `
 		SELECT s.id, s.room_id, s.from_user_id, s.to_user_id, s.amount, s.created_at,
 		       u1.nickname as from_user_name, u2.nickname as to_user_name
 		FROM settlements s
@@ -1044,32 +1686,31 @@ func (s *MahjongService) getRoomSettlements(roomID int64) ([]*Settlement, error)
 }
 
 
-// 生成房间二维码
+// 生成房间二维码，图片保存后返回访问路径，客户端拼接服务地址后直接作为图片地址使用
 func (s *MahjongService) GenerateQRCode(ctx context.Context, req *GenerateQRCodeRequest) (*Response, error) {
-	// 验证房间是否存在
-	var roomExists bool
-	err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM rooms WHERE id = ?)", req.RoomId).Scan(&roomExists)
+	ctx, span := tracer.Start(ctx, "MahjongService.GenerateQRCode", trace.WithAttributes(attribute.Int64("room_id", req.RoomId)))
+	defer span.End()
+
+	envVersion, err := NormalizeEnvVersion(req.EnvVersion)
 	if err != nil {
-		return &Response{Code: 500, Message: "查询房间失败: " + err.Error()}, nil
+		return &Response{Code: 400, Message: err.Error()}, nil
 	}
-	
-	if !roomExists {
+
+	_, err = s.qrcodes.Get(ctx, req.RoomId, envVersion)
+	switch {
+	case errors.Is(err, ErrRoomNotFound):
 		return &Response{Code: 404, Message: "房间不存在"}, nil
+	case errors.Is(err, ErrRoomSettled):
+		return &Response{Code: 400, Message: "房间已结算"}, nil
+	case err != nil:
+		return failed(ctx, "生成二维码失败: "+err.Error()), nil
 	}
-	
-	// 调用微信API生成小程序码
-	qrCodeData, err := s.wechatService.GenerateUnlimitedQRCode(req.RoomId, req.EnvVersion)
-	if err != nil {
-		return &Response{Code: 500, Message: "生成二维码失败: " + err.Error()}, nil
-	}
-	
-	// 将二维码数据转换为base64返回
-	responseData := map[string]interface{}{
-		"room_id": req.RoomId,
-		"qr_code": qrCodeData,
-	}
-	
-	data, _ := json.Marshal(responseData)
+
+	data, _ := json.Marshal(GenerateQRCodeResponse{
+		RoomId:     req.RoomId,
+		EnvVersion: envVersion,
+		QRCodeURL:  RoomQRCodePath(req.RoomId, envVersion),
+	})
 	return &Response{Code: 200, Message: "生成成功", Data: string(data)}, nil
 }
 
@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"mahjong-server/internal/logger"
+)
+
+func TestMain(m *testing.M) {
+	// 用例有意制造的查询失败会打印错误日志
+	logger.SetLevel(logger.FATAL)
+	os.Exit(m.Run())
+}
+
+// newMockService 使用sqlmock的MahjongService，用例结束时检查所有预期的SQL都已执行
+func newMockService(t *testing.T, wechat *WeChatService) (*MahjongService, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("创建sqlmock失败: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("SQL预期未满足: %v", err)
+		}
+		db.Close()
+	})
+	return NewMahjongService(db, wechat, nil), mock
+}
+
+// expectLockRoom 预期写入事务先锁定房间行
+func expectLockRoom(mock sqlmock.Sqlmock, roomID int64, status int) {
+	mock.ExpectQuery("SELECT status FROM rooms WHERE id = \\? FOR UPDATE").
+		WithArgs(roomID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(status))
+}
+
+func TestFailedMapsContextError(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		code int32
+	}{
+		{"正常请求", context.Background(), 500},
+		{"客户端断开", cancelled, 499},
+		{"处理超时", expired, 504},
+	}
+	for _, tt := range tests {
+		if resp := failed(tt.ctx, "查询失败"); resp.Code != tt.code {
+			t.Errorf("%s: failed返回 %d，期望 %d", tt.name, resp.Code, tt.code)
+		}
+	}
+}
+
+func TestCancelAbortsQuery(t *testing.T) {
+	service, mock := newMockService(t, nil)
+	// 查询需要10秒才返回，取消后应立即中止
+	mock.ExpectQuery("SELECT id, room_code, room_name").
+		WithArgs(int64(7)).
+		WillDelayFor(10 * time.Second).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	resp, err := service.GetRoom(ctx, &GetRoomRequest{RoomId: 7})
+	if err != nil {
+		t.Fatalf("GetRoom返回错误: %v", err)
+	}
+	if resp.Code != 499 {
+		t.Fatalf("客户端断开后应返回499，实际 %d %s", resp.Code, resp.Message)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("取消后查询未中止，耗时 %v", elapsed)
+	}
+}
+
+func TestDeadlineAbortsQuery(t *testing.T) {
+	service, mock := newMockService(t, nil)
+	mock.ExpectQuery("SELECT id, room_code, room_name").
+		WithArgs("123456").
+		WillDelayFor(10 * time.Second).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	resp, _ := service.GetRoom(ctx, &GetRoomRequest{RoomCode: "123456"})
+	if resp.Code != 504 {
+		t.Fatalf("超时后应返回504，实际 %d %s", resp.Code, resp.Message)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("超时后查询未中止，耗时 %v", elapsed)
+	}
+}
+
+func TestCancelRollsBackTransaction(t *testing.T) {
+	service, mock := newMockService(t, nil)
+	mock.ExpectBegin()
+	expectLockRoom(mock, 1, 1)
+	mock.ExpectQuery("SELECT current_score FROM room_players").
+		WithArgs(int64(1), int64(10)).
+		WillReturnRows(sqlmock.NewRows([]string{"current_score"}).AddRow(0))
+	// 第一条UPDATE执行期间客户端断开
+	mock.ExpectExec("UPDATE room_players").
+		WithArgs(int32(5), int64(1), int64(10)).
+		WillDelayFor(10 * time.Second).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	resp, _ := service.TransferScore(ctx, &TransferScoreRequest{RoomId: 1, FromUserId: 10, ToUserId: 11, Amount: 5})
+	if resp.Code != 499 {
+		t.Fatalf("事务中断开应返回499，实际 %d %s", resp.Code, resp.Message)
+	}
+	// 事务回滚由database/sql在ctx取消后异步完成
+	deadline := time.Now().Add(time.Second)
+	for mock.ExpectationsWereMet() != nil && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWritesRejectSettledRoom(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(*MahjongService) (*Response, error)
+	}{
+		{"转移", func(s *MahjongService) (*Response, error) {
+			return s.TransferScore(context.Background(), &TransferScoreRequest{RoomId: 1, FromUserId: 10, ToUserId: 11, Amount: 5})
+		}},
+		{"撤销", func(s *MahjongService) (*Response, error) {
+			return s.VoidTransfer(context.Background(), &VoidTransferRequest{RoomId: 1, TransferId: 3, UserId: 10})
+		}},
+		{"重复结算", func(s *MahjongService) (*Response, error) {
+			return s.SettleRoom(context.Background(), &SettleRoomRequest{RoomId: 1, UserId: 10})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newMockService(t, nil)
+			// 锁定房间后发现已结算，不写入分数和事件
+			mock.ExpectBegin()
+			expectLockRoom(mock, 1, 2)
+			mock.ExpectRollback()
+
+			resp, err := tt.write(service)
+			if err != nil || resp.Code != 400 {
+				t.Fatalf("已结算的房间应返回400，实际 %+v, %v", resp, err)
+			}
+		})
+	}
+}
+
+func TestQueryErrorWithoutCancelIs500(t *testing.T) {
+	service, mock := newMockService(t, nil)
+	mock.ExpectQuery("SELECT id, room_code, room_name").
+		WithArgs(int64(7)).
+		WillReturnError(errors.New("connection refused"))
+
+	resp, _ := service.GetRoom(context.Background(), &GetRoomRequest{RoomId: 7})
+	if resp.Code != 500 {
+		t.Fatalf("与请求无关的数据库错误应返回500，实际 %d", resp.Code)
+	}
+}
+
+var seriesTransferColumns = []string{"id", "from_user_id", "to_user_id", "amount", "created_at"}
+
+// expectSeriesStart 预期分数曲线先查询撤销数和房间玩家
+func expectSeriesStart(mock sqlmock.Sqlmock, voided int) {
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM score_transfers").
+		WithArgs(int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(voided))
+	mock.ExpectQuery("SELECT rp.user_id, u.nickname").
+		WithArgs(int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "nickname"}).
+			AddRow(1, "东").AddRow(2, "南").AddRow(3, "西"))
+}
+
+func getScoreSeries(t *testing.T, service *MahjongService, req *GetRoomScoreSeriesRequest) *ScoreSeries {
+	t.Helper()
+	resp, err := service.GetRoomScoreSeries(context.Background(), req)
+	if err != nil || resp.Code != 200 {
+		t.Fatalf("GetRoomScoreSeries = %+v, %v", resp, err)
+	}
+	series := &ScoreSeries{}
+	if err := json.Unmarshal([]byte(resp.Data), series); err != nil {
+		t.Fatalf("解析分数曲线失败: %v", err)
+	}
+	return series
+}
+
+func TestScoreSeriesBuckets(t *testing.T) {
+	service, mock := newMockService(t, nil)
+	start := time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC)
+	expectSeriesStart(mock, 0)
+	mock.ExpectQuery("SELECT id, from_user_id, to_user_id, amount, created_at").
+		WithArgs(int64(9), int64(0)).
+		WillReturnRows(sqlmock.NewRows(seriesTransferColumns).
+			AddRow(1, 1, 2, 10, start.Add(30*time.Second)).
+			AddRow(2, 3, 2, 5, start.Add(4*time.Minute)).
+			AddRow(3, 2, 1, 8, start.Add(6*time.Minute)))
+
+	series := getScoreSeries(t, service, &GetRoomScoreSeriesRequest{RoomId: 9, BucketMinutes: 5})
+	// 前两笔在同一个5分钟桶内，只保留第二笔之后的分数
+	if len(series.Points) != 2 {
+		t.Fatalf("点数 = %d，期望2", len(series.Points))
+	}
+	first, second := series.Points[0], series.Points[1]
+	if first.TransferId != 2 || first.Timestamp != start.Unix() || first.Scores[2] != 15 || first.Scores[3] != -5 {
+		t.Errorf("第一个桶 = %+v", first)
+	}
+	if second.TransferId != 3 || second.Timestamp != start.Add(5*time.Minute).Unix() || second.Scores[1] != -2 || second.Scores[2] != 7 {
+		t.Errorf("第二个桶 = %+v", second)
+	}
+	if series.LastTransferId != 3 || series.Reset {
+		t.Errorf("last_transfer_id = %d, reset = %v", series.LastTransferId, series.Reset)
+	}
+}
+
+func TestScoreSeriesByRound(t *testing.T) {
+	start := time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC)
+	transfers := func() *sqlmock.Rows {
+		return sqlmock.NewRows(seriesTransferColumns).
+			AddRow(1, 1, 3, 4, start).
+			AddRow(2, 2, 3, 4, start.Add(30*time.Second)).           // 同一赢家，同一局
+			AddRow(3, 3, 1, 6, start.Add(time.Minute)).              // 换了赢家，第2局
+			AddRow(4, 2, 1, 2, start.Add(time.Minute+3*time.Minute)) // 同一赢家但间隔超过2分钟，第3局
+	}
+
+	service, mock := newMockService(t, nil)
+	expectSeriesStart(mock, 0)
+	mock.ExpectQuery("SELECT id, from_user_id, to_user_id, amount, created_at").
+		WithArgs(int64(9), int64(0)).
+		WillReturnRows(transfers())
+
+	series := getScoreSeries(t, service, &GetRoomScoreSeriesRequest{RoomId: 9, ByRound: true})
+	if len(series.Points) != 3 {
+		t.Fatalf("局数 = %d，期望3", len(series.Points))
+	}
+	for i, want := range []struct {
+		round      int32
+		transferID int64
+		winner     int64
+		score      int32
+	}{
+		{1, 2, 3, 8},
+		{2, 3, 1, 2},
+		{3, 4, 1, 4},
+	} {
+		point := series.Points[i]
+		if point.Round != want.round || point.TransferId != want.transferID || point.Scores[want.winner] != want.score {
+			t.Errorf("第%d个点 = %+v，期望第%d局转移%d后玩家%d为%d", i+1, point, want.round, want.transferID, want.winner, want.score)
+		}
+	}
+
+	// 增量获取时仍从头回放以得到局数，只返回游标之后有变化的局
+	service, mock = newMockService(t, nil)
+	expectSeriesStart(mock, 0)
+	mock.ExpectQuery("SELECT id, from_user_id, to_user_id, amount, created_at").
+		WithArgs(int64(9), int64(0)).
+		WillReturnRows(transfers())
+
+	series = getScoreSeries(t, service, &GetRoomScoreSeriesRequest{RoomId: 9, ByRound: true, LastTransferId: 3})
+	if len(series.Points) != 1 || series.Points[0].Round != 3 || series.LastTransferId != 4 {
+		t.Fatalf("增量按局 = %+v", series)
+	}
+}
+
+func TestScoreSeriesIncremental(t *testing.T) {
+	service, mock := newMockService(t, nil)
+	expectSeriesStart(mock, 1)
+	mock.ExpectQuery("SELECT from_user_id, to_user_id, SUM\\(amount\\)").
+		WithArgs(int64(9), int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"from_user_id", "to_user_id", "sum"}).AddRow(1, 2, 10))
+	mock.ExpectQuery("SELECT id, from_user_id, to_user_id, amount, created_at").
+		WithArgs(int64(9), int64(2)).
+		WillReturnRows(sqlmock.NewRows(seriesTransferColumns).AddRow(3, 2, 1, 4, time.Now()))
+
+	series := getScoreSeries(t, service, &GetRoomScoreSeriesRequest{RoomId: 9, LastTransferId: 2, VoidedCount: 1})
+	if series.Reset || len(series.Points) != 1 {
+		t.Fatalf("撤销数未变时应只返回增量: %+v", series)
+	}
+	if point := series.Points[0]; point.TransferId != 3 || point.Scores[1] != -6 || point.Scores[2] != 6 {
+		t.Errorf("增量点 = %+v", point)
+	}
+	if series.LastTransferId != 3 || series.VoidedCount != 1 {
+		t.Errorf("last_transfer_id = %d, voided_count = %d", series.LastTransferId, series.VoidedCount)
+	}
+}
+
+func TestScoreSeriesIncrementalAfterVoid(t *testing.T) {
+	service, mock := newMockService(t, nil)
+	// 客户端上次拿到时没有撤销，现在有1笔：游标之前的转移可能被撤销，改为从头回放
+	expectSeriesStart(mock, 1)
+	mock.ExpectQuery("SELECT id, from_user_id, to_user_id, amount, created_at").
+		WithArgs(int64(9), int64(0)).
+		WillReturnRows(sqlmock.NewRows(seriesTransferColumns).
+			AddRow(2, 3, 2, 5, time.Now()).
+			AddRow(3, 2, 1, 4, time.Now()))
+
+	series := getScoreSeries(t, service, &GetRoomScoreSeriesRequest{RoomId: 9, LastTransferId: 2})
+	if !series.Reset || series.VoidedCount != 1 {
+		t.Fatalf("有新的撤销时应返回全量曲线并置reset: %+v", series)
+	}
+	if len(series.Points) != 2 || series.Points[1].Scores[2] != 1 || series.LastTransferId != 3 {
+		t.Errorf("全量曲线 = %+v", series.Points)
+	}
+}
+
+func TestScoreSeriesReadError(t *testing.T) {
+	tests := []struct {
+		name string
+		rows *sqlmock.Rows
+	}{
+		{"读取转移失败", sqlmock.NewRows(seriesTransferColumns).AddRow(1, 1, 2, "abc", time.Now())},
+		{"遍历中途出错", sqlmock.NewRows(seriesTransferColumns).
+			AddRow(1, 1, 2, 10, time.Now()).
+			AddRow(2, 2, 1, 5, time.Now()).
+			RowError(1, errors.New("connection reset"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newMockService(t, nil)
+			expectSeriesStart(mock, 0)
+			mock.ExpectQuery("SELECT id, from_user_id, to_user_id, amount, created_at").
+				WithArgs(int64(9), int64(0)).
+				WillReturnRows(tt.rows)
+
+			// 不能返回截断的曲线
+			resp, _ := service.GetRoomScoreSeries(context.Background(), &GetRoomScoreSeriesRequest{RoomId: 9})
+			if resp.Code != 500 {
+				t.Fatalf("读取转移出错应返回500，实际 %d %s", resp.Code, resp.Data)
+			}
+		})
+	}
+}
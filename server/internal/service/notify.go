@@ -0,0 +1,735 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"mahjong-server/internal/events"
+	"mahjong-server/internal/logger"
+	"mahjong-server/internal/metrics"
+)
+
+// 订阅消息类型
+const (
+	NotifyRoomSettled     = "room_settled"
+	NotifyPaymentReminder = "payment_reminder"
+)
+
+// 发送队列中的状态
+const (
+	notificationPending = 0
+	notificationSent    = 1
+	notificationFailed  = 2
+)
+
+const (
+	notifyPollInterval    = 5 * time.Second
+	notifyBatchSize       = 50
+	notifyLease           = time.Minute // 领取后在此期间其他实例不会重复发送
+	notifyRetryBase       = 30 * time.Second
+	notifyRetryMax        = time.Hour
+	notifySendTimeout     = 15 * time.Second
+	notifyEnqueueTimeout  = 10 * time.Second
+	maxSubscribeConsents  = 50 // 单个模板累计的授权次数上限
+	templateThingMaxRunes = 20 // thing类型关键词的长度上限
+	templateTimeLayout    = "2006-01-02 15:04"
+)
+
+// 模板关键词的默认映射，格式为 字段:关键词，逗号分隔
+const (
+	DefaultRoomSettledFields     = "room:thing1,score:character_string2,time:time3,note:thing4"
+	DefaultPaymentReminderFields = "room:thing1,payee:thing2,amount:character_string3,note:thing4"
+)
+
+// 各类消息可用的字段
+var notifyFieldNames = map[string][]string{
+	NotifyRoomSettled:     {"room", "score", "time", "note"},
+	NotifyPaymentReminder: {"room", "payee", "amount", "note"},
+}
+
+var notificationsTotal = metrics.NewCounterVec("mahjong_notifications_total",
+	"订阅消息处理次数，result为queued、skipped、sent、retry或failed", "kind", "result")
+
+var (
+	ErrNotifyDisabled = errors.New("订阅消息未启用")
+	ErrNoOpenID       = errors.New("用户未在当前小程序登录过，没有可用的openid")
+)
+
+// NotifierOptions 订阅消息配置，模板ID为空的消息类型不发送
+type NotifierOptions struct {
+	RoomSettledTemplate     string
+	PaymentReminderTemplate string
+	RoomSettledFields       string        // 为空时使用DefaultRoomSettledFields
+	PaymentReminderFields   string        // 为空时使用DefaultPaymentReminderFields
+	MiniprogramState        string        // 点击消息打开的小程序版本：developer、trial或formal
+	UserHourlyLimit         int           // 每个用户每小时最多收到的消息数
+	ReminderInterval        time.Duration // 同一房间对同一用户的付款提醒间隔
+	MaxAttempts             int           // 发送失败后的最多尝试次数
+}
+
+// Notifier 订阅消息：记录用户授权，房间结算和付款提醒时写入发送队列，由后台协程发送和重试
+// 队列保存在数据库中，重启不丢失，多实例时每条消息只由一个实例发送
+type Notifier struct {
+	db        *tracedDB
+	wechat    *WeChatService
+	opts      NotifierOptions
+	templates map[string]string            // 消息类型 -> 模板ID
+	fields    map[string]map[string]string // 消息类型 -> 字段 -> 模板关键词
+	wake      chan struct{}
+}
+
+// NewNotifier 创建订阅消息服务，关键词映射无效时返回错误
+func NewNotifier(db *sql.DB, wechat *WeChatService, opts NotifierOptions) (*Notifier, error) {
+	if opts.RoomSettledFields == "" {
+		opts.RoomSettledFields = DefaultRoomSettledFields
+	}
+	if opts.PaymentReminderFields == "" {
+		opts.PaymentReminderFields = DefaultPaymentReminderFields
+	}
+	if opts.MiniprogramState == "" {
+		opts.MiniprogramState = "formal"
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 1
+	}
+
+	n := &Notifier{
+		db:        &tracedDB{db: db},
+		wechat:    wechat,
+		opts:      opts,
+		templates: make(map[string]string),
+		fields:    make(map[string]map[string]string),
+		wake:      make(chan struct{}, 1),
+	}
+	for kind, spec := range map[string]string{
+		NotifyRoomSettled:     opts.RoomSettledFields,
+		NotifyPaymentReminder: opts.PaymentReminderFields,
+	} {
+		fields, err := parseTemplateFields(kind, spec)
+		if err != nil {
+			return nil, err
+		}
+		n.fields[kind] = fields
+	}
+	if opts.RoomSettledTemplate != "" {
+		n.templates[NotifyRoomSettled] = opts.RoomSettledTemplate
+	}
+	if opts.PaymentReminderTemplate != "" {
+		n.templates[NotifyPaymentReminder] = opts.PaymentReminderTemplate
+	}
+	return n, nil
+}
+
+// parseTemplateFields 解析 字段:关键词 列表，如 room:thing1,score:character_string2
+func parseTemplateFields(kind, spec string) (map[string]string, error) {
+	allowed := make(map[string]bool)
+	for _, name := range notifyFieldNames[kind] {
+		allowed[name] = true
+	}
+	fields := make(map[string]string)
+	for _, pair := range strings.Split(spec, ",") {
+		name, key, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || key == "" || !allowed[name] {
+			return nil, fmt.Errorf("%s的模板关键词映射无效: %q，可用字段: %s", kind, pair, strings.Join(notifyFieldNames[kind], ","))
+		}
+		fields[name] = key
+	}
+	return fields, nil
+}
+
+// Templates 返回已启用的消息类型及其模板ID，客户端用于wx.requestSubscribeMessage
+func (n *Notifier) Templates() map[string]string {
+	templates := make(map[string]string, len(n.templates))
+	for kind, id := range n.templates {
+		templates[kind] = id
+	}
+	return templates
+}
+
+// RecordConsent 记录用户同意的模板，一次性订阅每次同意可发送一条消息；未启用的模板忽略
+// 返回记录的模板数
+func (n *Notifier) RecordConsent(ctx context.Context, userID int64, templateIDs []string) (int, error) {
+	enabled := make(map[string]bool)
+	for _, id := range n.templates {
+		enabled[id] = true
+	}
+	recorded := 0
+	for _, id := range templateIDs {
+		if !enabled[id] {
+			continue
+		}
+		_, err := n.db.ExecContext(ctx, `
+			INSERT INTO subscribe_consents (user_id, template_id, remaining) VALUES (?, ?, 1)
+			ON DUPLICATE KEY UPDATE remaining = LEAST(remaining + 1, ?)
+		`, userID, id, maxSubscribeConsents)
+		if err != nil {
+			return recorded, err
+		}
+		recorded++
+	}
+	return recorded, nil
+}
+
+// notification 待写入队列的消息
+type notification struct {
+	userID int64
+	roomID int64
+	kind   string
+	values map[string]string // 字段 -> 内容，按关键词映射转换为模板数据
+}
+
+// enqueue 消耗一次用户授权并写入发送队列；未授权或超过频率限制时不写入，返回原因
+func (n *Notifier) enqueue(ctx context.Context, item *notification) (string, error) {
+	templateID, ok := n.templates[item.kind]
+	if !ok {
+		return ErrNotifyDisabled.Error(), nil
+	}
+	data := make(map[string]string)
+	for name, key := range n.fields[item.kind] {
+		if value := item.values[name]; value != "" {
+			data[key] = templateValue(key, value)
+		}
+	}
+	dataJSON, _ := json.Marshal(data)
+
+	tx, err := n.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	// 锁住授权记录，同一用户同一模板的入队串行执行，频率限制不会被并发请求绕过
+	var remaining int
+	err = tx.QueryRowContext(ctx, `
+		SELECT remaining FROM subscribe_consents WHERE user_id = ? AND template_id = ? FOR UPDATE
+	`, item.userID, templateID).Scan(&remaining)
+	if err != nil && err != sql.ErrNoRows {
+		return "", err
+	}
+	if remaining <= 0 {
+		return n.skip(item, "用户未订阅该消息"), nil
+	}
+
+	if n.opts.UserHourlyLimit > 0 {
+		var recent int
+		err = tx.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM notifications
+			WHERE user_id = ? AND status <> ? AND created_at > DATE_SUB(NOW(), INTERVAL 1 HOUR)
+		`, item.userID, notificationFailed).Scan(&recent)
+		if err != nil {
+			return "", err
+		}
+		if recent >= n.opts.UserHourlyLimit {
+			return n.skip(item, "超过每小时发送上限"), nil
+		}
+	}
+	if item.kind == NotifyPaymentReminder && n.opts.ReminderInterval > 0 {
+		var recent int
+		err = tx.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM notifications
+			WHERE user_id = ? AND room_id = ? AND kind = ? AND status <> ? AND created_at > DATE_SUB(NOW(), INTERVAL ? SECOND)
+		`, item.userID, item.roomID, item.kind, notificationFailed, int(n.opts.ReminderInterval.Seconds())).Scan(&recent)
+		if err != nil {
+			return "", err
+		}
+		if recent > 0 {
+			return n.skip(item, "提醒过于频繁，请稍后再试"), nil
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE subscribe_consents SET remaining = remaining - 1 WHERE user_id = ? AND template_id = ?
+	`, item.userID, templateID)
+	if err != nil {
+		return "", err
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO notifications (user_id, room_id, kind, template_id, page, data) VALUES (?, ?, ?, ?, ?, ?)
+	`, item.userID, item.roomID, item.kind, templateID, roomPage(item.roomID), string(dataJSON))
+	if err != nil {
+		return "", err
+	}
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	notificationsTotal.Inc(item.kind, "queued")
+	select {
+	case n.wake <- struct{}{}:
+	default:
+	}
+	return "", nil
+}
+
+func (n *Notifier) skip(item *notification, reason string) string {
+	notificationsTotal.Inc(item.kind, "skipped")
+	logger.Debug("订阅消息未入队", "kind", item.kind, "user_id", item.userID, "room_id", item.roomID, "reason", reason)
+	return reason
+}
+
+// roomPage 点击消息打开的房间页面
+func roomPage(roomID int64) string {
+	return "pages/room/room?roomId=" + strconv.FormatInt(roomID, 10)
+}
+
+// templateValue 按关键词类型裁剪内容，thing类型超长或包含emoji时微信会拒绝整条消息
+func templateValue(key, value string) string {
+	if strings.HasPrefix(key, "thing") {
+		value = strings.TrimSpace(StripEmoji(value))
+		if value == "" {
+			// 如昵称只有emoji，空值同样会被拒绝
+			value = "-"
+		}
+		if runes := []rune(value); len(runes) > templateThingMaxRunes {
+			return string(runes[:templateThingMaxRunes-1]) + "…"
+		}
+	}
+	return value
+}
+
+// HandleEvent 作为事件订阅者，房间结算后异步通知结算人以外的玩家
+func (n *Notifier) HandleEvent(ctx context.Context, event events.Event) {
+	settled, ok := event.(*events.RoomSettled)
+	if !ok || n.templates[NotifyRoomSettled] == "" {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), notifyEnqueueTimeout)
+		defer cancel()
+		n.notifyRoomSettled(ctx, settled)
+	}()
+}
+
+func (n *Notifier) notifyRoomSettled(ctx context.Context, e *events.RoomSettled) {
+	roomName, err := n.roomName(ctx, e.RoomId)
+	if err != nil {
+		logger.ErrorContext(ctx, "查询房间失败，未发送结算通知", "room_id", e.RoomId, "error", err.Error())
+		return
+	}
+	nicknames := make(map[int64]string, len(e.Players))
+	for _, player := range e.Players {
+		nicknames[player.UserId] = displayName(player.Nickname)
+	}
+	settledAt := time.Now().Format(templateTimeLayout)
+
+	for _, player := range e.Players {
+		if player.UserId == e.SettledBy {
+			continue
+		}
+		_, err := n.enqueue(ctx, &notification{
+			userID: player.UserId,
+			roomID: e.RoomId,
+			kind:   NotifyRoomSettled,
+			values: map[string]string{
+				"room":  roomName,
+				"score": fmt.Sprintf("%+d", player.Score),
+				"time":  settledAt,
+				"note":  settlementNote(player.UserId, e.Settlements, nicknames),
+			},
+		})
+		if err != nil {
+			logger.ErrorContext(ctx, "结算通知入队失败", "room_id", e.RoomId, "user_id", player.UserId, "error", err.Error())
+		}
+	}
+}
+
+// settlementNote 玩家需要支付或收取的分数
+func settlementNote(userID int64, settlements []events.Settlement, nicknames map[int64]string) string {
+	var pay, receive []events.Settlement
+	for _, s := range settlements {
+		switch userID {
+		case s.FromUserId:
+			pay = append(pay, s)
+		case s.ToUserId:
+			receive = append(receive, s)
+		}
+	}
+	switch {
+	case len(pay) == 1:
+		return fmt.Sprintf("需向%s支付%d分", nicknames[pay[0].ToUserId], pay[0].Amount)
+	case len(pay) > 1:
+		return fmt.Sprintf("需支付%d笔共%d分", len(pay), sumAmounts(pay))
+	case len(receive) > 0:
+		return fmt.Sprintf("待收取%d分", sumAmounts(receive))
+	default:
+		return "无需转账"
+	}
+}
+
+func sumAmounts(settlements []events.Settlement) int32 {
+	var total int32
+	for _, s := range settlements {
+		total += s.Amount
+	}
+	return total
+}
+
+func displayName(nickname string) string {
+	if nickname == "" {
+		return "玩家"
+	}
+	return nickname
+}
+
+func (n *Notifier) roomName(ctx context.Context, roomID int64) (string, error) {
+	var name string
+	err := n.db.QueryRowContext(ctx, `SELECT room_name FROM rooms WHERE id = ?`, roomID).Scan(&name)
+	if err != nil {
+		return "", err
+	}
+	if name == "" {
+		name = "麻将房间"
+	}
+	return name, nil
+}
+
+// RemindPaymentResult 付款提醒的结果
+type RemindPaymentResult struct {
+	Queued  []int64           `json:"queued"`
+	Skipped []SkippedReminder `json:"skipped"`
+}
+
+type SkippedReminder struct {
+	UserId int64  `json:"user_id"`
+	Reason string `json:"reason"`
+}
+
+// RemindPayment 收款人提醒房间内欠自己分数的玩家付款，debtorID为0时提醒所有人
+// 房间没有对应的结算记录时返回sql.ErrNoRows
+func (n *Notifier) RemindPayment(ctx context.Context, roomID, payeeID, debtorID int64) (*RemindPaymentResult, error) {
+	if n.templates[NotifyPaymentReminder] == "" {
+		return nil, ErrNotifyDisabled
+	}
+
+	query := `SELECT from_user_id, amount FROM settlements WHERE room_id = ? AND to_user_id = ?`
+	args := []interface{}{roomID, payeeID}
+	if debtorID != 0 {
+		query += ` AND from_user_id = ?`
+		args = append(args, debtorID)
+	}
+	rows, err := n.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	var debts []events.Settlement
+	for rows.Next() {
+		var s events.Settlement
+		if err := rows.Scan(&s.FromUserId, &s.Amount); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		debts = append(debts, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(debts) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	roomName, err := n.roomName(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	var payee string
+	if err := n.db.QueryRowContext(ctx, `SELECT nickname FROM users WHERE id = ?`, payeeID).Scan(&payee); err != nil {
+		return nil, err
+	}
+	payee = displayName(payee)
+
+	result := &RemindPaymentResult{Queued: []int64{}, Skipped: []SkippedReminder{}}
+	for _, debt := range debts {
+		reason, err := n.enqueue(ctx, &notification{
+			userID: debt.FromUserId,
+			roomID: roomID,
+			kind:   NotifyPaymentReminder,
+			values: map[string]string{
+				"room":   roomName,
+				"payee":  payee,
+				"amount": strconv.Itoa(int(debt.Amount)),
+				"note":   fmt.Sprintf("请尽快向%s支付%d分", payee, debt.Amount),
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			result.Skipped = append(result.Skipped, SkippedReminder{UserId: debt.FromUserId, Reason: reason})
+		} else {
+			result.Queued = append(result.Queued, debt.FromUserId)
+		}
+	}
+	return result, nil
+}
+
+// Start 启动后台发送协程，返回的函数停止协程并等待正在发送的消息完成
+func (n *Notifier) Start() func() {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		n.run(stop)
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(stop)
+			<-done
+		})
+	}
+}
+
+func (n *Notifier) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(notifyPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		case <-n.wake:
+		}
+		// 一批全部领取成功时继续处理；有消息未能领取（已被其他实例领取或数据库出错）时等到下次轮询，
+		// 否则这些仍然到期的消息会被反复查出，循环不会结束
+		for n.processDue(context.Background(), stop) == notifyBatchSize {
+		}
+	}
+}
+
+// pendingNotification 待发送的消息
+type pendingNotification struct {
+	id         int64
+	userID     int64
+	kind       string
+	templateID string
+	page       string
+	data       string
+	attempts   int
+	openID     string
+}
+
+// processDue 发送到期的消息，返回本批领取成功的数量
+func (n *Notifier) processDue(ctx context.Context, stop <-chan struct{}) int {
+	// 订阅消息只能发给用户在当前小程序的openid，users.openid可能属于其他小程序
+	rows, err := n.db.QueryContext(ctx, `
+		SELECT n.id, n.user_id, n.kind, n.template_id, n.page, n.data, n.attempts, COALESCE(o.openid, '')
+		FROM notifications n LEFT JOIN user_openids o ON o.user_id = n.user_id AND o.app_id = ?
+		WHERE n.status = ? AND n.next_attempt_at <= NOW()
+		ORDER BY n.id LIMIT ?
+	`, n.wechat.appID, notificationPending, notifyBatchSize)
+	if err != nil {
+		logger.Error("查询待发送的订阅消息失败", "error", err.Error())
+		return 0
+	}
+	var batch []pendingNotification
+	for rows.Next() {
+		var p pendingNotification
+		if err := rows.Scan(&p.id, &p.userID, &p.kind, &p.templateID, &p.page, &p.data, &p.attempts, &p.openID); err != nil {
+			logger.Error("读取待发送的订阅消息失败", "error", err.Error())
+			break
+		}
+		batch = append(batch, p)
+	}
+	rows.Close()
+
+	claimed := 0
+	for i := range batch {
+		select {
+		case <-stop:
+			return 0
+		default:
+		}
+		if n.send(ctx, &batch[i]) {
+			claimed++
+		}
+	}
+	return claimed
+}
+
+// send 领取并发送一条消息，返回是否领取成功；领取失败说明已被其他实例处理
+func (n *Notifier) send(ctx context.Context, p *pendingNotification) bool {
+	claimed, err := n.db.ExecContext(ctx, `
+		UPDATE notifications SET next_attempt_at = DATE_ADD(NOW(), INTERVAL ? SECOND)
+		WHERE id = ? AND status = ? AND next_attempt_at <= NOW()
+	`, int(notifyLease.Seconds()), p.id, notificationPending)
+	if err != nil {
+		logger.Error("领取订阅消息失败", "notification_id", p.id, "error", err.Error())
+		return false
+	}
+	if affected, _ := claimed.RowsAffected(); affected != 1 {
+		return false
+	}
+
+	ctx, span := tracer.Start(ctx, "Notifier.send", trace.WithAttributes(attribute.Int64("notification_id", p.id), attribute.String("kind", p.kind)))
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, notifySendTimeout)
+	defer cancel()
+
+	if p.openID == "" {
+		n.finish(ctx, p, notificationFailed, p.attempts, ErrNoOpenID)
+		notificationsTotal.Inc(p.kind, "failed")
+		logger.WarnContext(ctx, "订阅消息发送失败", "notification_id", p.id, "kind", p.kind, "user_id", p.userID,
+			"error", ErrNoOpenID.Error())
+		return true
+	}
+
+	var data map[string]string
+	json.Unmarshal([]byte(p.data), &data)
+	err = n.wechat.SendSubscribeMessage(ctx, &SubscribeMessage{
+		ToUser:           p.openID,
+		TemplateID:       p.templateID,
+		Page:             p.page,
+		MiniprogramState: n.opts.MiniprogramState,
+		Data:             data,
+	})
+	attempts := p.attempts + 1
+	if err == nil {
+		n.finish(ctx, p, notificationSent, attempts, nil)
+		notificationsTotal.Inc(p.kind, "sent")
+		logger.InfoContext(ctx, "订阅消息已发送", "notification_id", p.id, "kind", p.kind, "user_id", p.userID)
+		return true
+	}
+
+	recordError(span, err)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.ErrCode == 43101 {
+		// 用户在微信侧没有可用的订阅，本地的授权计数作废
+		n.db.ExecContext(ctx, `UPDATE subscribe_consents SET remaining = 0 WHERE user_id = ? AND template_id = ?`, p.userID, p.templateID)
+	}
+	if isPermanentSendError(err) || attempts >= n.opts.MaxAttempts {
+		n.finish(ctx, p, notificationFailed, attempts, err)
+		notificationsTotal.Inc(p.kind, "failed")
+		logger.WarnContext(ctx, "订阅消息发送失败", "notification_id", p.id, "kind", p.kind, "user_id", p.userID,
+			"attempts", attempts, "error", err.Error())
+		return true
+	}
+
+	delay := notifyRetryBase << (attempts - 1)
+	if delay > notifyRetryMax || delay <= 0 {
+		delay = notifyRetryMax
+	}
+	_, dbErr := n.db.ExecContext(ctx, `
+		UPDATE notifications SET attempts = ?, last_error = ?, next_attempt_at = DATE_ADD(NOW(), INTERVAL ? SECOND)
+		WHERE id = ?
+	`, attempts, truncateError(err), int(delay.Seconds()), p.id)
+	if dbErr != nil {
+		logger.Error("更新订阅消息状态失败", "notification_id", p.id, "error", dbErr.Error())
+	}
+	notificationsTotal.Inc(p.kind, "retry")
+	logger.WarnContext(ctx, "订阅消息发送失败，稍后重试", "notification_id", p.id, "kind", p.kind,
+		"attempts", attempts, "retry_in", delay.String(), "error", err.Error())
+	return true
+}
+
+// finish 记录最终结果，sendErr为nil表示发送成功
+func (n *Notifier) finish(ctx context.Context, p *pendingNotification, status, attempts int, sendErr error) {
+	query := `UPDATE notifications SET status = ?, attempts = ?, last_error = ? WHERE id = ?`
+	if status == notificationSent {
+		query = `UPDATE notifications SET status = ?, attempts = ?, last_error = ?, sent_at = NOW() WHERE id = ?`
+	}
+	if _, err := n.db.ExecContext(ctx, query, status, attempts, truncateError(sendErr), p.id); err != nil {
+		logger.Error("更新订阅消息状态失败", "notification_id", p.id, "error", err.Error())
+	}
+}
+
+// truncateError 截断到last_error的列宽
+func truncateError(err error) string {
+	if err == nil {
+		return ""
+	}
+	if runes := []rune(err.Error()); len(runes) > 255 {
+		return string(runes[:255])
+	}
+	return err.Error()
+}
+
+// isPermanentSendError 重试也不会成功的错误：用户未订阅、openid或模板无效、参数错误
+func isPermanentSendError(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.ErrCode {
+	case 40003, 40037, 41030, 43101, 47003:
+		return true
+	}
+	return false
+}
+
+// SetNotifier 设置订阅消息服务
+func (s *MahjongService) SetNotifier(notifier *Notifier) {
+	s.notifier = notifier
+}
+
+// GetSubscribeTemplates 返回已启用的订阅消息模板，未启用时为空
+func (s *MahjongService) GetSubscribeTemplates(ctx context.Context) (*Response, error) {
+	templates := map[string]string{}
+	if s.notifier != nil {
+		templates = s.notifier.Templates()
+	}
+	data, _ := json.Marshal(templates)
+	return &Response{Code: 200, Message: "获取成功", Data: string(data)}, nil
+}
+
+// SubscribeConsent 记录wx.requestSubscribeMessage中用户同意的模板
+func (s *MahjongService) SubscribeConsent(ctx context.Context, req *SubscribeConsentRequest) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "MahjongService.SubscribeConsent")
+	defer span.End()
+
+	if s.notifier == nil {
+		return &Response{Code: 400, Message: ErrNotifyDisabled.Error()}, nil
+	}
+	userID, err := s.userIDBySession(ctx, req.SessionID)
+	if errors.Is(err, ErrSessionInvalid) {
+		return &Response{Code: 401, Message: err.Error()}, nil
+	}
+	if err != nil {
+		return failed(ctx, "查询登录态失败"), nil
+	}
+
+	recorded, err := s.notifier.RecordConsent(ctx, userID, req.TemplateIds)
+	if err != nil {
+		return failed(ctx, "记录订阅失败"), nil
+	}
+	data, _ := json.Marshal(map[string]interface{}{"recorded": recorded})
+	return &Response{Code: 200, Message: "订阅成功", Data: string(data)}, nil
+}
+
+// RemindPayment 结算后收款人提醒欠款玩家付款
+func (s *MahjongService) RemindPayment(ctx context.Context, req *RemindPaymentRequest) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "MahjongService.RemindPayment", trace.WithAttributes(attribute.Int64("room_id", req.RoomId)))
+	defer span.End()
+
+	if s.notifier == nil {
+		return &Response{Code: 400, Message: ErrNotifyDisabled.Error()}, nil
+	}
+	userID, err := s.userIDBySession(ctx, req.SessionID)
+	if errors.Is(err, ErrSessionInvalid) {
+		return &Response{Code: 401, Message: err.Error()}, nil
+	}
+	if err != nil {
+		return failed(ctx, "查询登录态失败"), nil
+	}
+
+	result, err := s.notifier.RemindPayment(ctx, req.RoomId, userID, req.UserId)
+	switch {
+	case errors.Is(err, ErrNotifyDisabled):
+		return &Response{Code: 400, Message: err.Error()}, nil
+	case err == sql.ErrNoRows:
+		return &Response{Code: 404, Message: "没有需要提醒的待付款"}, nil
+	case err != nil:
+		return failed(ctx, "发送付款提醒失败"), nil
+	}
+	data, _ := json.Marshal(result)
+	return &Response{Code: 200, Message: "提醒已发送", Data: string(data)}, nil
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"mahjong-server/internal/wechatfake"
+)
+
+const (
+	testSettledTemplate  = "tmpl_settled"
+	testReminderTemplate = "tmpl_reminder"
+)
+
+// newMockNotifier 使用sqlmock和模拟微信接口的Notifier
+func newMockNotifier(t *testing.T, opts NotifierOptions) (*Notifier, sqlmock.Sqlmock, *wechatfake.Server) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("创建sqlmock失败: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("SQL预期未满足: %v", err)
+		}
+		db.Close()
+	})
+	wechat, fake := newFakeWeChat(t)
+	opts.RoomSettledTemplate = testSettledTemplate
+	opts.PaymentReminderTemplate = testReminderTemplate
+	n, err := NewNotifier(db, wechat, opts)
+	if err != nil {
+		t.Fatalf("创建Notifier失败: %v", err)
+	}
+	return n, mock, fake
+}
+
+func testPending(attempts int) *pendingNotification {
+	return &pendingNotification{
+		id:         1,
+		userID:     2,
+		kind:       NotifyRoomSettled,
+		templateID: testSettledTemplate,
+		page:       roomPage(9),
+		data:       `{"thing1":"周末麻将","character_string2":"+12"}`,
+		attempts:   attempts,
+		openID:     "openid_2",
+	}
+}
+
+func expectClaim(mock sqlmock.Sqlmock, id int64, affected int64) {
+	mock.ExpectExec("UPDATE notifications SET next_attempt_at").
+		WithArgs(int(notifyLease.Seconds()), id, notificationPending).
+		WillReturnResult(sqlmock.NewResult(0, affected))
+}
+
+func TestRecordConsentIgnoresDisabledTemplates(t *testing.T) {
+	n, mock, _ := newMockNotifier(t, NotifierOptions{})
+	mock.ExpectExec("INSERT INTO subscribe_consents").
+		WithArgs(int64(2), testSettledTemplate, maxSubscribeConsents).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	recorded, err := n.RecordConsent(context.Background(), 2, []string{"unknown", testSettledTemplate})
+	if err != nil || recorded != 1 {
+		t.Fatalf("RecordConsent = %d, %v，期望只记录已启用的模板", recorded, err)
+	}
+}
+
+func TestEnqueueConsumesConsent(t *testing.T) {
+	n, mock, _ := newMockNotifier(t, NotifierOptions{UserHourlyLimit: 3})
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT remaining FROM subscribe_consents").
+		WithArgs(int64(2), testSettledTemplate).
+		WillReturnRows(sqlmock.NewRows([]string{"remaining"}).AddRow(1))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM notifications").
+		WithArgs(int64(2), notificationFailed).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectExec("UPDATE subscribe_consents SET remaining = remaining - 1").
+		WithArgs(int64(2), testSettledTemplate).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO notifications").
+		WithArgs(int64(2), int64(9), NotifyRoomSettled, testSettledTemplate, roomPage(9), `{"thing1":"周末麻将"}`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	reason, err := n.enqueue(context.Background(), &notification{
+		userID: 2, roomID: 9, kind: NotifyRoomSettled, values: map[string]string{"room": "周末麻将"},
+	})
+	if err != nil || reason != "" {
+		t.Fatalf("enqueue = %q, %v，期望入队", reason, err)
+	}
+	select {
+	case <-n.wake:
+	default:
+		t.Error("入队后应唤醒发送协程")
+	}
+}
+
+func TestEnqueueSkips(t *testing.T) {
+	tests := []struct {
+		name      string
+		remaining int
+		recent    int
+		reason    string
+	}{
+		{"未授权", 0, 0, "用户未订阅该消息"},
+		{"超过每小时上限", 1, 3, "超过每小时发送上限"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, mock, _ := newMockNotifier(t, NotifierOptions{UserHourlyLimit: 3})
+			mock.ExpectBegin()
+			mock.ExpectQuery("SELECT remaining FROM subscribe_consents").
+				WithArgs(int64(2), testSettledTemplate).
+				WillReturnRows(sqlmock.NewRows([]string{"remaining"}).AddRow(tt.remaining))
+			if tt.remaining > 0 {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM notifications").
+					WithArgs(int64(2), notificationFailed).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.recent))
+			}
+			// 未入队时不消耗授权
+			mock.ExpectRollback()
+
+			reason, err := n.enqueue(context.Background(), &notification{userID: 2, roomID: 9, kind: NotifyRoomSettled})
+			if err != nil || reason != tt.reason {
+				t.Fatalf("enqueue = %q, %v，期望 %q", reason, err, tt.reason)
+			}
+		})
+	}
+}
+
+func TestSendDeliversMessage(t *testing.T) {
+	n, mock, fake := newMockNotifier(t, NotifierOptions{MiniprogramState: "trial"})
+	expectClaim(mock, 1, 1)
+	mock.ExpectExec("UPDATE notifications SET status = \\?, attempts = \\?, last_error = \\?, sent_at = NOW\\(\\)").
+		WithArgs(notificationSent, 1, "", int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if !n.send(context.Background(), testPending(0)) {
+		t.Fatal("领取成功时send应返回true")
+	}
+	messages := fake.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("发送了 %d 条消息", len(messages))
+	}
+	msg := messages[0]
+	if msg.ToUser != "openid_2" || msg.TemplateID != testSettledTemplate || msg.Page != roomPage(9) || msg.MiniprogramState != "trial" {
+		t.Errorf("消息 = %+v", msg)
+	}
+	if msg.Data["thing1"]["value"] != "周末麻将" || msg.Data["character_string2"]["value"] != "+12" {
+		t.Errorf("模板数据 = %v", msg.Data)
+	}
+}
+
+func TestSendRetriesWithBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		delay    int
+	}{
+		{0, 30},
+		{1, 60},
+		{3, 240},
+		{20, int(notifyRetryMax.Seconds())},
+	}
+	for _, tt := range tests {
+		n, mock, fake := newMockNotifier(t, NotifierOptions{MaxAttempts: 30})
+		fake.FailNext(wechatfake.APISubscribeSend, -1, "system error")
+		expectClaim(mock, 1, 1)
+		mock.ExpectExec("UPDATE notifications SET attempts = \\?, last_error = \\?, next_attempt_at").
+			WithArgs(tt.attempts+1, sqlmock.AnyArg(), tt.delay, int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		n.send(context.Background(), testPending(tt.attempts))
+		if len(fake.Messages()) != 0 {
+			t.Errorf("第%d次失败后不应有消息发出", tt.attempts+1)
+		}
+	}
+}
+
+func TestSendPermanentError(t *testing.T) {
+	n, mock, fake := newMockNotifier(t, NotifierOptions{MaxAttempts: 5})
+	// 用户在微信侧没有可用的订阅：作废本地授权，不再重试
+	fake.FailNext(wechatfake.APISubscribeSend, 43101, "user refuse to accept the msg")
+	expectClaim(mock, 1, 1)
+	mock.ExpectExec("UPDATE subscribe_consents SET remaining = 0").
+		WithArgs(int64(2), testSettledTemplate).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE notifications SET status = \\?, attempts = \\?, last_error = \\? WHERE id").
+		WithArgs(notificationFailed, 1, sqlmock.AnyArg(), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	n.send(context.Background(), testPending(0))
+	if calls := fake.Calls(wechatfake.APISubscribeSend); calls != 1 {
+		t.Fatalf("永久错误不应在请求内重试，调用了 %d 次", calls)
+	}
+}
+
+func TestSendGivesUpAfterMaxAttempts(t *testing.T) {
+	n, mock, fake := newMockNotifier(t, NotifierOptions{MaxAttempts: 3})
+	fake.FailNext(wechatfake.APISubscribeSend, -1, "system error")
+	expectClaim(mock, 1, 1)
+	mock.ExpectExec("UPDATE notifications SET status = \\?, attempts = \\?, last_error = \\? WHERE id").
+		WithArgs(notificationFailed, 3, sqlmock.AnyArg(), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	n.send(context.Background(), testPending(2))
+}
+
+func TestSendWithoutCurrentAppOpenID(t *testing.T) {
+	n, mock, fake := newMockNotifier(t, NotifierOptions{MaxAttempts: 5})
+	expectClaim(mock, 1, 1)
+	mock.ExpectExec("UPDATE notifications SET status = \\?, attempts = \\?, last_error = \\? WHERE id").
+		WithArgs(notificationFailed, 0, ErrNoOpenID.Error(), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// 用户只在其他小程序登录过，不能用users.openid发送
+	p := testPending(0)
+	p.openID = ""
+	n.send(context.Background(), p)
+	if calls := fake.Calls(wechatfake.APISubscribeSend); calls != 0 {
+		t.Fatalf("没有当前小程序的openid时不应调用发送接口，调用了 %d 次", calls)
+	}
+}
+
+func TestSendSkipsClaimedByOthers(t *testing.T) {
+	n, mock, fake := newMockNotifier(t, NotifierOptions{})
+	expectClaim(mock, 1, 0)
+
+	if n.send(context.Background(), testPending(0)) {
+		t.Fatal("未领取到时send应返回false")
+	}
+	if calls := fake.Calls(wechatfake.APISubscribeSend); calls != 0 {
+		t.Fatalf("未领取到的消息不应发送，调用了 %d 次", calls)
+	}
+}
+
+func TestProcessDueCountsOnlyClaimed(t *testing.T) {
+	n, mock, fake := newMockNotifier(t, NotifierOptions{})
+	rows := sqlmock.NewRows([]string{"id", "user_id", "kind", "template_id", "page", "data", "attempts", "openid"})
+	for i := 1; i <= notifyBatchSize; i++ {
+		rows.AddRow(i, 2, NotifyRoomSettled, testSettledTemplate, roomPage(9), `{"thing1":"周末麻将"}`, 0, "openid_2")
+	}
+	mock.ExpectQuery("SELECT n.id, n.user_id").
+		WithArgs(testAppID, notificationPending, notifyBatchSize).
+		WillReturnRows(rows)
+	// 整批都已被其他实例领取，返回0后run不会立即再次查询
+	for i := 1; i <= notifyBatchSize; i++ {
+		expectClaim(mock, int64(i), 0)
+	}
+
+	if claimed := n.processDue(context.Background(), make(chan struct{})); claimed != 0 {
+		t.Fatalf("processDue = %d，没有领取到消息时应返回0", claimed)
+	}
+	if calls := fake.Calls(wechatfake.APISubscribeSend); calls != 0 {
+		t.Fatalf("调用了 %d 次发送接口", calls)
+	}
+}
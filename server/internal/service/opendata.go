@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"mahjong-server/internal/logger"
+)
+
+var (
+	ErrDecryptFailed      = errors.New("解密失败，session_key可能已更新，请重新登录后重试")
+	ErrWatermarkMismatch  = errors.New("数据不属于本小程序")
+	ErrSessionKeyMissing  = errors.New("未找到session_key，请重新登录后重试")
+	ErrSessionKeyDisabled = errors.New("服务端未开启开放数据解密")
+)
+
+// openDataWatermark 开放数据中的水印，appid须与本小程序一致
+type openDataWatermark struct {
+	AppID     string `json:"appid"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// DecryptData 按微信开放数据的约定解密encryptedData：AES-128-CBC，密钥为session_key，PKCS#7填充，
+// 三个参数均为base64编码；解密后校验水印中的appid，再解析到out
+func (w *WeChatService) DecryptData(encryptedData, iv, sessionKey string, out interface{}) error {
+	key, err := base64.StdEncoding.DecodeString(sessionKey)
+	if err != nil || len(key) != 16 {
+		return ErrDecryptFailed
+	}
+	ivBytes, err := base64.StdEncoding.DecodeString(iv)
+	if err != nil || len(ivBytes) != aes.BlockSize {
+		return fmt.Errorf("iv无效")
+	}
+	data, err := base64.StdEncoding.DecodeString(encryptedData)
+	if err != nil || len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return fmt.Errorf("encryptedData无效")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return ErrDecryptFailed
+	}
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, ivBytes).CryptBlocks(plain, data)
+	plain, ok := pkcs7Unpad(plain)
+	if !ok {
+		// session_key与加密时不一致（如用户在其他设备重新登录）时通常表现为填充错误
+		return ErrDecryptFailed
+	}
+
+	var envelope struct {
+		Watermark openDataWatermark `json:"watermark"`
+	}
+	if err := json.Unmarshal(plain, &envelope); err != nil {
+		return ErrDecryptFailed
+	}
+	if envelope.Watermark.AppID != w.appID {
+		return ErrWatermarkMismatch
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(plain, out); err != nil {
+		return fmt.Errorf("解析解密数据失败: %v", err)
+	}
+	return nil
+}
+
+// pkcs7Unpad 去掉PKCS#7填充，填充无效时返回false
+// 微信部分接口按32字节块填充，因此填充长度允许到32
+func pkcs7Unpad(data []byte) ([]byte, bool) {
+	if len(data) == 0 {
+		return nil, false
+	}
+	n := int(data[len(data)-1])
+	if n == 0 || n > 32 || n > len(data) {
+		return nil, false
+	}
+	for _, b := range data[len(data)-n:] {
+		if int(b) != n {
+			return nil, false
+		}
+	}
+	return data[:len(data)-n], true
+}
+
+// SessionKeyCipher 加密保存用户的session_key（AES-256-GCM），数据库泄露时不能直接用来解密用户数据
+type SessionKeyCipher struct {
+	aead cipher.AEAD
+}
+
+// NewSessionKeyCipher 由配置的密钥创建，密钥经SHA-256派生为AES-256密钥
+func NewSessionKeyCipher(secret string) (*SessionKeyCipher, error) {
+	if secret == "" {
+		return nil, errors.New("session_key加密密钥不能为空")
+	}
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &SessionKeyCipher{aead: aead}, nil
+}
+
+// seal 加密session_key，用户ID作为附加数据，密文不能挪给其他用户使用
+// 随机数不可用时返回错误，GCM的nonce不能重复使用
+func (c *SessionKeyCipher) seal(userID int64, sessionKey string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("生成nonce失败: %w", err)
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(sessionKey), []byte(strconv.FormatInt(userID, 10)))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// open 解密session_key，密钥更换或数据被篡改时返回错误
+func (c *SessionKeyCipher) open(userID int64, sealed string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < c.aead.NonceSize() {
+		return "", errors.New("session_key密文格式错误")
+	}
+	nonce, ciphertext := data[:c.aead.NonceSize()], data[c.aead.NonceSize():]
+	plain, err := c.aead.Open(nil, nonce, ciphertext, []byte(strconv.FormatInt(userID, 10)))
+	if err != nil {
+		return "", errors.New("session_key解密失败")
+	}
+	return string(plain), nil
+}
+
+// SetSessionKeyCipher 设置session_key的加密方式，未设置时不保存session_key，开放数据解密接口不可用
+func (s *MahjongService) SetSessionKeyCipher(sessionKeys *SessionKeyCipher) {
+	s.sessionKeys = sessionKeys
+}
+
+// saveSessionKey 登录时保存最新的session_key，每个用户只保留一个，重新登录后旧的失效
+func (s *MahjongService) saveSessionKey(ctx context.Context, userID int64, sessionKey string) {
+	if s.sessionKeys == nil || sessionKey == "" {
+		return
+	}
+	sealed, err := s.sessionKeys.seal(userID, sessionKey)
+	if err != nil {
+		logger.ErrorContext(ctx, "加密session_key失败", "user_id", userID, "error", err.Error())
+		return
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO user_session_keys (user_id, session_key) VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE session_key = VALUES(session_key), updated_at = NOW()
+	`, userID, sealed)
+	if err != nil {
+		// 不影响登录，只是之后无法解密开放数据
+		logger.ErrorContext(ctx, "保存session_key失败", "user_id", userID, "error", err.Error())
+	}
+}
+
+// sessionKeyByUser 读取用户最近一次登录保存的session_key
+func (s *MahjongService) sessionKeyByUser(ctx context.Context, userID int64) (string, error) {
+	if s.sessionKeys == nil {
+		return "", ErrSessionKeyDisabled
+	}
+	var sealed string
+	err := s.db.QueryRowContext(ctx, `SELECT session_key FROM user_session_keys WHERE user_id = ?`, userID).Scan(&sealed)
+	if err == sql.ErrNoRows {
+		return "", ErrSessionKeyMissing
+	}
+	if err != nil {
+		return "", err
+	}
+	sessionKey, err := s.sessionKeys.open(userID, sealed)
+	if err != nil {
+		// 通常是更换了加密密钥，重新登录后会保存新的密文
+		logger.WarnContext(ctx, "读取session_key失败", "user_id", userID, "error", err.Error())
+		return "", ErrSessionKeyMissing
+	}
+	return sessionKey, nil
+}
+
+// sessionKeyBySession 由登录态查询用户及其session_key，失败时返回给客户端的响应
+func (s *MahjongService) sessionKeyBySession(ctx context.Context, sessionID string) (int64, string, *Response) {
+	if s.sessionKeys == nil {
+		return 0, "", &Response{Code: 503, Message: ErrSessionKeyDisabled.Error()}
+	}
+	userID, err := s.userIDBySession(ctx, sessionID)
+	if errors.Is(err, ErrSessionInvalid) {
+		return 0, "", &Response{Code: 401, Message: err.Error()}
+	}
+	if err != nil {
+		return 0, "", failed(ctx, "查询登录态失败")
+	}
+
+	sessionKey, err := s.sessionKeyByUser(ctx, userID)
+	if errors.Is(err, ErrSessionKeyMissing) {
+		return 0, "", &Response{Code: 401, Message: err.Error()}
+	}
+	if err != nil {
+		return 0, "", failed(ctx, "查询session_key失败")
+	}
+	return userID, sessionKey, nil
+}
+
+// decryptOpenGID 解密wx.getShareInfo返回的群信息，得到群的openGId
+func (s *MahjongService) decryptOpenGID(encryptedData, iv, sessionKey string) (string, error) {
+	var share struct {
+		OpenGID string `json:"openGId"`
+	}
+	if err := s.wechatService.DecryptData(encryptedData, iv, sessionKey, &share); err != nil {
+		return "", err
+	}
+	if share.OpenGID == "" {
+		return "", errors.New("解密数据中没有群信息")
+	}
+	return share.OpenGID, nil
+}
+
+// decryptResponse 解密失败时的响应，session_key不一致需要客户端重新登录
+func decryptResponse(err error) *Response {
+	if errors.Is(err, ErrDecryptFailed) {
+		return &Response{Code: 401, Message: err.Error()}
+	}
+	return &Response{Code: 400, Message: err.Error()}
+}
+
+// DecryptPhoneNumber 解密getPhoneNumber返回的手机号并保存，手机号只返回给本人
+func (s *MahjongService) DecryptPhoneNumber(ctx context.Context, req *DecryptDataRequest) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "MahjongService.DecryptPhoneNumber")
+	defer span.End()
+
+	userID, sessionKey, resp := s.sessionKeyBySession(ctx, req.SessionID)
+	if resp != nil {
+		return resp, nil
+	}
+	var phone struct {
+		PhoneNumber     string `json:"phoneNumber"`
+		PurePhoneNumber string `json:"purePhoneNumber"`
+		CountryCode     string `json:"countryCode"`
+	}
+	if err := s.wechatService.DecryptData(req.EncryptedData, req.Iv, sessionKey, &phone); err != nil {
+		logger.WarnContext(ctx, "解密手机号失败", "user_id", userID, "error", err.Error())
+		return decryptResponse(err), nil
+	}
+	if phone.PhoneNumber == "" {
+		return &Response{Code: 400, Message: "解密数据中没有手机号"}, nil
+	}
+
+	_, err := s.db.ExecContext(ctx, `UPDATE users SET phone_number = ?, updated_at = NOW() WHERE id = ?`, phone.PhoneNumber, userID)
+	if err != nil {
+		return failed(ctx, "保存手机号失败"), nil
+	}
+	logger.InfoContext(ctx, "手机号已更新", "user_id", userID)
+
+	data, _ := json.Marshal(PhoneNumberResponse{
+		PhoneNumber:     phone.PhoneNumber,
+		PurePhoneNumber: phone.PurePhoneNumber,
+		CountryCode:     phone.CountryCode,
+	})
+	return &Response{Code: 200, Message: "获取成功", Data: string(data)}, nil
+}
+
+// DecryptShareInfo 解密wx.getShareInfo返回的群信息，记录用户为该群成员，用于将房间关联到微信群
+func (s *MahjongService) DecryptShareInfo(ctx context.Context, req *DecryptDataRequest) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "MahjongService.DecryptShareInfo")
+	defer span.End()
+
+	userID, sessionKey, resp := s.sessionKeyBySession(ctx, req.SessionID)
+	if resp != nil {
+		return resp, nil
+	}
+	openGID, err := s.decryptOpenGID(req.EncryptedData, req.Iv, sessionKey)
+	if err != nil {
+		logger.WarnContext(ctx, "解密群信息失败", "user_id", userID, "error", err.Error())
+		return decryptResponse(err), nil
+	}
+
+	groupID, err := s.joinGroup(ctx, openGID, userID)
+	if err != nil {
+		return failed(ctx, "记录群信息失败"), nil
+	}
+
+	data, _ := json.Marshal(ShareInfoResponse{GroupId: groupID, OpenGId: openGID})
+	return &Response{Code: 200, Message: "获取成功", Data: string(data)}, nil
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// 微信开放数据解密文档中的示例数据
+const (
+	sampleAppID         = "wx4f4bc4dec97d474b"
+	sampleSessionKey    = "tiihtNczf5v6AKRyjwEUhQ=="
+	sampleIV            = "r7BXXKkLb8qrSNn05n0qiA=="
+	sampleEncryptedData = "CiyLU1Aw2KjvrjMdj8YKliAjtP4gsMZMQmRzooG2xrDcvSnxIMXFufNstNGTyaGS9uT5geRa0W4oTOb1WT7fJlAC+oNPdbB+3hVbJSRgv+4lGOETKUQz6OYStslQ142dNCuabNPGBzlooOmB231qMM85d2/fV6ChevvXvQP8Hkue1poOFtnEtpyxVLW1zAo6/1Xx1COxFvrc2d7UL/lmHInNlxuacJXwu0fjpXfz/YqYzBIBzD6WUfTIF9GRHpOn/Hz7saL8xz+W//FRAUid1OksQaQx4CMs8LOddcQhULW4ucetDf96JcR3g0gfRK4PC7E/r7Z6xNrXd2UIeorGj5Ef7b1pJAYB6Y5anaHqZ9J6nKEBvB4DnNLIVWSgARns/8wR2SiRS7MNACwTyrGvt9ts8p12PKFdlqYTopNHR1Vf7XjfhQlVsAJdNiKdYmYVoKlaRv85IfVunYzO0IKXsyl7JCUjCpoG20f0a04COwfneQAGGwd5oa+T8yO5hzuyDb/XcxxmK01EpqOyuxINew=="
+)
+
+func TestDecryptData(t *testing.T) {
+	tests := []struct {
+		name          string
+		appID         string
+		encryptedData string
+		iv            string
+		sessionKey    string
+		wantErr       error  // 期望的哨兵错误
+		wantMessage   string // 非哨兵错误的消息
+	}{
+		{"官方示例", sampleAppID, sampleEncryptedData, sampleIV, sampleSessionKey, nil, ""},
+		{"session_key不一致", sampleAppID, sampleEncryptedData, sampleIV, "AAAAAAAAAAAAAAAAAAAAAA==", ErrDecryptFailed, ""},
+		{"session_key长度错误", sampleAppID, sampleEncryptedData, sampleIV, "AAAA", ErrDecryptFailed, ""},
+		{"水印appid不一致", testAppID, sampleEncryptedData, sampleIV, sampleSessionKey, ErrWatermarkMismatch, ""},
+		{"encryptedData不是base64", sampleAppID, "not base64!", sampleIV, sampleSessionKey, nil, "encryptedData无效"},
+		{"encryptedData长度不是块大小的倍数", sampleAppID, "AAAA", sampleIV, sampleSessionKey, nil, "encryptedData无效"},
+		{"iv不是base64", sampleAppID, sampleEncryptedData, "not base64!", sampleSessionKey, nil, "iv无效"},
+		{"iv长度错误", sampleAppID, sampleEncryptedData, "AAAAAAAAAAA=", sampleSessionKey, nil, "iv无效"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewWeChatService(tt.appID, testAppSecret)
+			var user struct {
+				OpenID   string `json:"openId"`
+				NickName string `json:"nickName"`
+				UnionID  string `json:"unionId"`
+			}
+			err := w.DecryptData(tt.encryptedData, tt.iv, tt.sessionKey, &user)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("错误 = %v，期望 %v", err, tt.wantErr)
+				}
+			case tt.wantMessage != "":
+				if err == nil || !strings.Contains(err.Error(), tt.wantMessage) {
+					t.Fatalf("错误 = %v，期望包含 %q", err, tt.wantMessage)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("解密失败: %v", err)
+				}
+				if user.OpenID != "oGZUI0egBJY1zhBYw2KhdUfwVJJE" || user.NickName != "Band" || user.UnionID != "ocMvos6NjeKLIBqg5Mr9QjxrP1FA" {
+					t.Fatalf("解密结果 = %+v", user)
+				}
+			}
+		})
+	}
+}
+
+func TestPKCS7Unpad(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+		ok   bool
+	}{
+		{"正常填充", []byte("abc\x05\x05\x05\x05\x05"), "abc", true},
+		{"32字节块填充", []byte(strings.Repeat("\x20", 32)), "", true},
+		{"填充长度为0", []byte("abc\x00"), "", false},
+		{"填充字节不一致", []byte("abc\x03\x02\x03"), "", false},
+		{"填充长度超过数据", []byte("\x05\x05"), "", false},
+		{"空数据", nil, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := pkcs7Unpad(tt.data)
+			if ok != tt.ok || (ok && string(got) != tt.want) {
+				t.Fatalf("pkcs7Unpad = %q, %v，期望 %q, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestSessionKeyCipherRoundTrip(t *testing.T) {
+	c, err := NewSessionKeyCipher("test-secret")
+	if err != nil {
+		t.Fatalf("创建加密器失败: %v", err)
+	}
+	sealed, err := c.seal(7, sampleSessionKey)
+	if err != nil {
+		t.Fatalf("加密失败: %v", err)
+	}
+	if strings.Contains(sealed, sampleSessionKey) {
+		t.Fatal("密文中不应包含明文")
+	}
+	if again, _ := c.seal(7, sampleSessionKey); again == sealed {
+		t.Fatal("每次加密应使用不同的nonce")
+	}
+
+	opened, err := c.open(7, sealed)
+	if err != nil || opened != sampleSessionKey {
+		t.Fatalf("解密 = %q, %v", opened, err)
+	}
+
+	// 用户ID作为附加数据，密文不能挪给其他用户
+	if _, err := c.open(8, sealed); err == nil {
+		t.Fatal("其他用户ID解密应失败")
+	}
+	// 更换密钥后旧密文不可用
+	other, _ := NewSessionKeyCipher("other-secret")
+	if _, err := other.open(7, sealed); err == nil {
+		t.Fatal("更换密钥后解密应失败")
+	}
+	for _, bad := range []string{"not base64!", "AAAA", sealed[:len(sealed)-4] + "AAAA"} {
+		if _, err := c.open(7, bad); err == nil {
+			t.Fatalf("格式错误或被篡改的密文 %q 解密应失败", bad)
+		}
+	}
+	if _, err := NewSessionKeyCipher(""); err == nil {
+		t.Fatal("空密钥应返回错误")
+	}
+}
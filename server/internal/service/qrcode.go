@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"mahjong-server/internal/events"
+	"mahjong-server/internal/logger"
+	"mahjong-server/internal/storage"
+)
+
+// 小程序版本，决定扫码后打开的版本
+var qrCodeEnvVersions = []string{"release", "trial", "develop"}
+
+const (
+	// defaultQRCodeEnvVersion 未传env_version时使用正式版
+	defaultQRCodeEnvVersion = "release"
+	// qrCodeGenerateTimeout 生成并保存一张小程序码的超时，与发起请求的ctx无关
+	qrCodeGenerateTimeout = 30 * time.Second
+	// qrCodeInvalidateTimeout 房间结算后删除小程序码的超时
+	qrCodeInvalidateTimeout = 10 * time.Second
+)
+
+var (
+	ErrRoomNotFound      = errors.New("房间不存在")
+	ErrRoomSettled       = errors.New("房间已结算")
+	ErrInvalidEnvVersion = errors.New("无效的小程序版本")
+)
+
+// RoomQRCodePath 小程序码图片的访问路径，由HTTP处理器提供
+func RoomQRCodePath(roomID int64, envVersion string) string {
+	return "/api/v1/roomQRCode?" + url.Values{
+		"room_id":     {strconv.FormatInt(roomID, 10)},
+		"env_version": {envVersion},
+	}.Encode()
+}
+
+// qrCodeCall 进行中的一次生成，同一房间和版本的并发请求等待同一次结果
+type qrCodeCall struct {
+	done   chan struct{}
+	object *storage.Object
+	err    error
+}
+
+// RoomQRCodes 房间小程序码：每个房间和小程序版本只调用一次微信接口，图片保存在storage中，
+// 房间结算后删除
+type RoomQRCodes struct {
+	db      *tracedDB
+	wechat  *WeChatService
+	storage storage.Storage
+
+	mu       sync.Mutex
+	inflight map[string]*qrCodeCall
+}
+
+// NewRoomQRCodes 创建房间小程序码管理
+func NewRoomQRCodes(db *sql.DB, wechat *WeChatService, store storage.Storage) *RoomQRCodes {
+	return &RoomQRCodes{
+		db:       &tracedDB{db: db},
+		wechat:   wechat,
+		storage:  store,
+		inflight: make(map[string]*qrCodeCall),
+	}
+}
+
+// NormalizeEnvVersion 校验小程序版本，为空时使用正式版
+func NormalizeEnvVersion(envVersion string) (string, error) {
+	if envVersion == "" {
+		return defaultQRCodeEnvVersion, nil
+	}
+	for _, version := range qrCodeEnvVersions {
+		if envVersion == version {
+			return envVersion, nil
+		}
+	}
+	return "", ErrInvalidEnvVersion
+}
+
+func qrCodeKey(roomID int64, envVersion string) string {
+	return fmt.Sprintf("qrcode/room/%d/%s.png", roomID, envVersion)
+}
+
+// Get 返回房间的小程序码，尚未生成时调用微信接口生成并保存
+// 房间不存在或已结算时分别返回ErrRoomNotFound、ErrRoomSettled
+func (q *RoomQRCodes) Get(ctx context.Context, roomID int64, envVersion string) (*storage.Object, error) {
+	envVersion, err := NormalizeEnvVersion(envVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	var status int
+	err = q.db.QueryRowContext(ctx, "SELECT status FROM rooms WHERE id = ?", roomID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return nil, ErrRoomNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询房间失败: %w", err)
+	}
+	if status != 1 {
+		return nil, ErrRoomSettled
+	}
+
+	key := qrCodeKey(roomID, envVersion)
+	object, err := q.storage.Get(ctx, key)
+	if err == nil {
+		return object, nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		// 存储暂时不可用时仍可直接生成，只是不能缓存
+		logger.WarnContext(ctx, "读取小程序码失败", "room_id", roomID, "key", key, "error", err.Error())
+	}
+	return q.generate(ctx, roomID, envVersion)
+}
+
+// generate 调用微信接口生成并保存，同一key同时只生成一次
+// 生成在独立的ctx中进行，发起请求的ctx取消只影响自己的等待
+func (q *RoomQRCodes) generate(ctx context.Context, roomID int64, envVersion string) (*storage.Object, error) {
+	key := qrCodeKey(roomID, envVersion)
+
+	q.mu.Lock()
+	call, ok := q.inflight[key]
+	if !ok {
+		call = &qrCodeCall{done: make(chan struct{})}
+		q.inflight[key] = call
+		go func() {
+			genCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), qrCodeGenerateTimeout)
+			defer cancel()
+			call.object, call.err = q.generateOnce(genCtx, roomID, envVersion, key)
+
+			q.mu.Lock()
+			delete(q.inflight, key)
+			q.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	q.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.object, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (q *RoomQRCodes) generateOnce(ctx context.Context, roomID int64, envVersion, key string) (*storage.Object, error) {
+	data, err := q.wechat.GenerateUnlimitedQRCode(ctx, roomID, envVersion)
+	if err != nil {
+		return nil, err
+	}
+	object := &storage.Object{Data: data, ContentType: http.DetectContentType(data), ModTime: time.Now()}
+	if err := q.storage.Put(ctx, key, data, object.ContentType); err != nil {
+		logger.WarnContext(ctx, "保存小程序码失败", "room_id", roomID, "key", key, "error", err.Error())
+	} else {
+		logger.InfoContext(ctx, "已生成小程序码", "room_id", roomID, "env_version", envVersion, "size", len(data))
+	}
+	return object, nil
+}
+
+// Invalidate 删除房间所有版本的小程序码
+func (q *RoomQRCodes) Invalidate(ctx context.Context, roomID int64) {
+	for _, envVersion := range qrCodeEnvVersions {
+		key := qrCodeKey(roomID, envVersion)
+		if err := q.storage.Delete(ctx, key); err != nil {
+			logger.WarnContext(ctx, "删除小程序码失败", "room_id", roomID, "key", key, "error", err.Error())
+		}
+	}
+}
+
+// HandleEvent 作为事件订阅者，房间结算后异步删除其小程序码
+func (q *RoomQRCodes) HandleEvent(ctx context.Context, event events.Event) {
+	if _, ok := event.(*events.RoomSettled); !ok {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), qrCodeInvalidateTimeout)
+		defer cancel()
+		q.Invalidate(ctx, event.RoomID())
+	}()
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"mahjong-server/internal/events"
+	"mahjong-server/internal/storage"
+	"mahjong-server/internal/wechatfake"
+)
+
+// newTestQRCodes 使用sqlmock、模拟微信接口和本地存储的房间小程序码
+func newTestQRCodes(t *testing.T) (*RoomQRCodes, sqlmock.Sqlmock, *wechatfake.Server, *storage.Local) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("创建sqlmock失败: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("SQL预期未满足: %v", err)
+		}
+		db.Close()
+	})
+	store, err := storage.NewLocal(t.TempDir(), "https://example.com/api/v1/files")
+	if err != nil {
+		t.Fatalf("创建本地存储失败: %v", err)
+	}
+	wechat, fake := newFakeWeChat(t)
+	return NewRoomQRCodes(db, wechat, store), mock, fake, store
+}
+
+func expectRoomStatus(mock sqlmock.Sqlmock, roomID int64, status int) {
+	mock.ExpectQuery("SELECT status FROM rooms WHERE id = ").
+		WithArgs(roomID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(status))
+}
+
+func TestNormalizeEnvVersion(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		err   error
+	}{
+		{"", "release", nil},
+		{"release", "release", nil},
+		{"trial", "trial", nil},
+		{"develop", "develop", nil},
+		{"Release", "", ErrInvalidEnvVersion},
+		{"beta", "", ErrInvalidEnvVersion},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeEnvVersion(tt.input)
+		if got != tt.want || !errors.Is(err, tt.err) {
+			t.Errorf("NormalizeEnvVersion(%q) = %q, %v，期望 %q, %v", tt.input, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestRoomQRCodesCachesPerVersion(t *testing.T) {
+	qrcodes, mock, fake, store := newTestQRCodes(t)
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		expectRoomStatus(mock, 1, 1)
+	}
+
+	first, err := qrcodes.Get(ctx, 1, "")
+	if err != nil {
+		t.Fatalf("生成小程序码失败: %v", err)
+	}
+	if !bytes.HasPrefix(first.Data, pngHeader) || first.ContentType != "image/png" {
+		t.Fatalf("返回的不是PNG图片: %s", first.ContentType)
+	}
+	saved, err := store.Get(ctx, qrCodeKey(1, "release"))
+	if err != nil || !bytes.Equal(saved.Data, first.Data) {
+		t.Fatalf("未传版本时应按正式版保存: %v", err)
+	}
+
+	// 已保存的直接返回，不再调用微信接口
+	again, err := qrcodes.Get(ctx, 1, "release")
+	if err != nil || !bytes.Equal(again.Data, first.Data) {
+		t.Fatalf("再次获取失败: %v", err)
+	}
+	if calls := fake.Calls(wechatfake.APIGetWxaCodeUnlimit); calls != 1 {
+		t.Fatalf("同一版本应只生成一次，调用了 %d 次", calls)
+	}
+
+	// 不同版本分别生成
+	if _, err := qrcodes.Get(ctx, 1, "trial"); err != nil {
+		t.Fatalf("生成体验版小程序码失败: %v", err)
+	}
+	if calls := fake.Calls(wechatfake.APIGetWxaCodeUnlimit); calls != 2 {
+		t.Fatalf("体验版应单独生成，共调用 %d 次", calls)
+	}
+}
+
+func TestRoomQRCodesRejected(t *testing.T) {
+	qrcodes, mock, fake, _ := newTestQRCodes(t)
+	ctx := context.Background()
+
+	if _, err := qrcodes.Get(ctx, 1, "beta"); !errors.Is(err, ErrInvalidEnvVersion) {
+		t.Errorf("无效版本应返回ErrInvalidEnvVersion，实际 %v", err)
+	}
+
+	mock.ExpectQuery("SELECT status FROM rooms WHERE id = ").
+		WithArgs(int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}))
+	if _, err := qrcodes.Get(ctx, 2, "release"); !errors.Is(err, ErrRoomNotFound) {
+		t.Errorf("房间不存在应返回ErrRoomNotFound，实际 %v", err)
+	}
+
+	expectRoomStatus(mock, 3, 2)
+	if _, err := qrcodes.Get(ctx, 3, "release"); !errors.Is(err, ErrRoomSettled) {
+		t.Errorf("已结算房间应返回ErrRoomSettled，实际 %v", err)
+	}
+
+	if calls := fake.Calls(wechatfake.APIGetWxaCodeUnlimit); calls != 0 {
+		t.Fatalf("被拒绝的请求不应调用微信接口，调用了 %d 次", calls)
+	}
+}
+
+func TestRoomQRCodesGeneratesOnceConcurrently(t *testing.T) {
+	qrcodes, mock, fake, _ := newTestQRCodes(t)
+	const requests = 20
+	mock.MatchExpectationsInOrder(false)
+	for i := 0; i < requests; i++ {
+		expectRoomStatus(mock, 1, 1)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := qrcodes.Get(context.Background(), 1, "release"); err != nil {
+				t.Errorf("并发获取失败: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls := fake.Calls(wechatfake.APIGetWxaCodeUnlimit); calls != 1 {
+		t.Fatalf("并发请求应只生成一次，调用了 %d 次", calls)
+	}
+}
+
+func TestRoomQRCodesWeChatError(t *testing.T) {
+	qrcodes, mock, fake, store := newTestQRCodes(t)
+	ctx := context.Background()
+	expectRoomStatus(mock, 1, 1)
+	expectRoomStatus(mock, 1, 1)
+	fake.FailNext(wechatfake.APIGetWxaCodeUnlimit, 45009, "reach max api daily quota limit")
+
+	if _, err := qrcodes.Get(ctx, 1, "release"); err == nil {
+		t.Fatal("微信接口失败时应返回错误")
+	}
+	if _, err := store.Get(ctx, qrCodeKey(1, "release")); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("失败时不应保存，实际 %v", err)
+	}
+	// 失败的结果不缓存，下次请求重新生成
+	if _, err := qrcodes.Get(ctx, 1, "release"); err != nil {
+		t.Fatalf("重新生成失败: %v", err)
+	}
+}
+
+func TestRoomQRCodesInvalidatedOnSettle(t *testing.T) {
+	qrcodes, mock, _, store := newTestQRCodes(t)
+	ctx := context.Background()
+	for _, envVersion := range qrCodeEnvVersions {
+		expectRoomStatus(mock, 1, 1)
+		if _, err := qrcodes.Get(ctx, 1, envVersion); err != nil {
+			t.Fatalf("生成%s小程序码失败: %v", envVersion, err)
+		}
+	}
+
+	// 结算后异步删除所有版本
+	qrcodes.HandleEvent(ctx, &events.RoomSettled{RoomId: 1})
+	deadline := time.Now().Add(5 * time.Second)
+	for _, envVersion := range qrCodeEnvVersions {
+		for {
+			_, err := store.Get(ctx, qrCodeKey(1, envVersion))
+			if errors.Is(err, storage.ErrNotFound) {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("结算后%s小程序码未删除: %v", envVersion, err)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+func TestGenerateQRCode(t *testing.T) {
+	qrcodes, mock, _, _ := newTestQRCodes(t)
+	service := NewMahjongService(nil, nil, nil)
+	service.SetRoomQRCodes(qrcodes)
+	ctx := context.Background()
+
+	expectRoomStatus(mock, 1, 1)
+	resp, _ := service.GenerateQRCode(ctx, &GenerateQRCodeRequest{RoomId: 1, EnvVersion: "trial"})
+	if resp.Code != 200 {
+		t.Fatalf("生成二维码失败: %d %s", resp.Code, resp.Message)
+	}
+	var data GenerateQRCodeResponse
+	if err := json.Unmarshal([]byte(resp.Data), &data); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	if data.QRCodeURL != "/api/v1/roomQRCode?env_version=trial&room_id=1" || data.EnvVersion != "trial" {
+		t.Fatalf("响应 = %+v", data)
+	}
+
+	tests := []struct {
+		name    string
+		req     *GenerateQRCodeRequest
+		prepare func()
+		code    int32
+	}{
+		{"无效版本", &GenerateQRCodeRequest{RoomId: 1, EnvVersion: "beta"}, func() {}, 400},
+		{"房间不存在", &GenerateQRCodeRequest{RoomId: 2}, func() {
+			mock.ExpectQuery("SELECT status FROM rooms WHERE id = ").
+				WithArgs(int64(2)).
+				WillReturnRows(sqlmock.NewRows([]string{"status"}))
+		}, 404},
+		{"房间已结算", &GenerateQRCodeRequest{RoomId: 3}, func() { expectRoomStatus(mock, 3, 2) }, 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepare()
+			if resp, _ := service.GenerateQRCode(ctx, tt.req); resp.Code != tt.code {
+				t.Fatalf("响应码 = %d %s，期望 %d", resp.Code, resp.Message, tt.code)
+			}
+		})
+	}
+}
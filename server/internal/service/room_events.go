@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"mahjong-server/internal/logger"
+)
+
+// sqlExecer tracedDB 与 tracedTx 共有的写入方法，事件既可以在事务内也可以在事务外追加
+type sqlExecer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// sqlQuerier tracedDB 与 tracedTx 共有的查询方法
+type sqlQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// appendRoomEvent 追加一条房间事件，事件表只插入不修改
+func appendRoomEvent(ctx context.Context, execer sqlExecer, event *RoomEvent) error {
+	_, err := execer.ExecContext(ctx, `
+		INSERT INTO room_events (room_id, event_type, user_id, target_user_id, amount, transfer_id)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, event.RoomId, event.EventType, event.UserId, event.TargetUserId, event.Amount, event.TransferId)
+	return err
+}
+
+// lockRoom 在事务内锁定房间行并返回房间状态。所有修改房间分数和事件的事务都先取这把锁，
+// 写入之间以及写入与RebuildRoom的回放之间互斥
+func lockRoom(ctx context.Context, tx *tracedTx, roomID int64) (int32, *Response) {
+	var status int32
+	err := tx.QueryRowContext(ctx, "SELECT status FROM rooms WHERE id = ? FOR UPDATE", roomID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return 0, &Response{Code: 404, Message: "房间不存在"}
+	} else if err != nil {
+		return 0, failed(ctx, "查询房间失败")
+	}
+	return status, nil
+}
+
+// lockActiveRoom 锁定房间行并确认房间未结算，已结算的房间不再接受写入
+func lockActiveRoom(ctx context.Context, tx *tracedTx, roomID int64) *Response {
+	status, resp := lockRoom(ctx, tx, roomID)
+	if resp != nil {
+		return resp
+	}
+	if status != 1 {
+		return &Response{Code: 400, Message: "房间已结算"}
+	}
+	return nil
+}
+
+// loadRoomEvents 按追加顺序读取房间的全部事件
+func loadRoomEvents(ctx context.Context, querier sqlQuerier, roomID int64) ([]*RoomEvent, error) {
+	return queryRoomEvents(ctx, querier, `
+		SELECT id, room_id, event_type, user_id, target_user_id, amount, transfer_id, created_at
+		FROM room_events
+		WHERE room_id = ?
+		ORDER BY id ASC
+	`, roomID)
+}
+
+// lockRoomEvents 在事务内以加锁读读取房间的全部事件，读到的是已提交的最新数据而不是事务快照
+func lockRoomEvents(ctx context.Context, tx *tracedTx, roomID int64) ([]*RoomEvent, error) {
+	return queryRoomEvents(ctx, tx, `
+		SELECT id, room_id, event_type, user_id, target_user_id, amount, transfer_id, created_at
+		FROM room_events
+		WHERE room_id = ?
+		ORDER BY id ASC
+		LOCK IN SHARE MODE
+	`, roomID)
+}
+
+func queryRoomEvents(ctx context.Context, querier sqlQuerier, query string, roomID int64) ([]*RoomEvent, error) {
+	rows, err := querier.QueryContext(ctx, query, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*RoomEvent
+	for rows.Next() {
+		event := &RoomEvent{}
+		if err := rows.Scan(
+			&event.Id, &event.RoomId, &event.EventType, &event.UserId,
+			&event.TargetUserId, &event.Amount, &event.TransferId, &event.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// replayRoomEvents 回放事件，得到每个玩家的当前分数以及房间是否已结算
+func replayRoomEvents(events []*RoomEvent) (map[int64]int32, bool) {
+	scores := make(map[int64]int32)
+	settled := false
+
+	for _, event := range events {
+		switch event.EventType {
+		case RoomEventJoined:
+			if _, ok := scores[event.UserId]; !ok {
+				scores[event.UserId] = 0
+			}
+		case RoomEventTransferred:
+			scores[event.UserId] -= event.Amount
+			scores[event.TargetUserId] += event.Amount
+		case RoomEventVoided:
+			// 撤销事件记录原转移的双方和分数，反向冲正
+			scores[event.UserId] += event.Amount
+			scores[event.TargetUserId] -= event.Amount
+		case RoomEventSettled:
+			settled = true
+		default:
+			logger.Warn("未知的房间事件类型", "room_id", event.RoomId, "event_id", event.Id, "event_type", event.EventType)
+		}
+	}
+
+	return scores, settled
+}
+
+// loadStoredScores 读取room_players中存储的当前分数
+func loadStoredScores(ctx context.Context, querier sqlQuerier, roomID int64) (map[int64]int32, error) {
+	rows, err := querier.QueryContext(ctx, `
+		SELECT user_id, current_score FROM room_players WHERE room_id = ?
+	`, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scores := make(map[int64]int32)
+	for rows.Next() {
+		var userID int64
+		var score int32
+		if err := rows.Scan(&userID, &score); err != nil {
+			return nil, err
+		}
+		scores[userID] = score
+	}
+	return scores, rows.Err()
+}
+
+// 由事件回放重建房间玩家分数
+func (s *MahjongService) RebuildRoom(ctx context.Context, req *RebuildRoomRequest) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "MahjongService.RebuildRoom", trace.WithAttributes(attribute.Int64("room_id", req.RoomId)))
+	defer span.End()
+
+	if !s.beginWrite() {
+		return &Response{Code: 503, Message: "服务正在重启，请稍后重试"}, nil
+	}
+	defer s.endWrite()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return failed(ctx, "开始事务失败"), nil
+	}
+	defer tx.Rollback()
+
+	// 锁定房间，转移、撤销、加入和结算都先取同一把锁，重建期间不会有新的写入
+	if _, resp := lockRoom(ctx, tx, req.RoomId); resp != nil {
+		return resp, nil
+	}
+
+	events, err := lockRoomEvents(ctx, tx, req.RoomId)
+	if err != nil {
+		return failed(ctx, "读取房间事件失败"), nil
+	}
+	if len(events) == 0 {
+		return &Response{Code: 400, Message: "房间没有事件记录，无法重建"}, nil
+	}
+
+	scores, settled := replayRoomEvents(events)
+	for userID, score := range scores {
+		if settled {
+			_, err = tx.ExecContext(ctx, `
+				UPDATE room_players SET current_score = ?, final_score = ? WHERE room_id = ? AND user_id = ?
+			`, score, score, req.RoomId, userID)
+		} else {
+			_, err = tx.ExecContext(ctx, `
+				UPDATE room_players SET current_score = ? WHERE room_id = ? AND user_id = ?
+			`, score, req.RoomId, userID)
+		}
+		if err != nil {
+			return failed(ctx, "更新玩家分数失败"), nil
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return failed(ctx, "提交事务失败"), nil
+	}
+
+	logger.LogBusiness(ctx, "rebuild_room", 0, "room_id", req.RoomId, "events", len(events), "players", len(scores))
+
+	scoresData, _ := json.Marshal(scores)
+	return &Response{Code: 200, Message: "重建成功", Data: string(scoresData)}, nil
+}
+
+// 检查房间存储分数与事件回放是否一致
+func (s *MahjongService) CheckConsistency(ctx context.Context, req *CheckConsistencyRequest) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "MahjongService.CheckConsistency", trace.WithAttributes(attribute.Int64("room_id", req.RoomId)))
+	defer span.End()
+
+	var roomIDs []int64
+	if req.RoomId > 0 {
+		roomIDs = []int64{req.RoomId}
+	} else {
+		rows, err := s.db.QueryContext(ctx, "SELECT id FROM rooms ORDER BY id ASC")
+		if err != nil {
+			return failed(ctx, "查询房间失败"), nil
+		}
+		roomIDs, err = scanRoomIDs(rows)
+		if err != nil {
+			logger.ErrorContext(ctx, "读取房间列表失败", "error", err.Error())
+			return failed(ctx, "查询房间失败"), nil
+		}
+	}
+
+	inconsistencies := []*RoomInconsistency{}
+	for _, roomID := range roomIDs {
+		inconsistency, err := s.checkRoomConsistency(ctx, roomID)
+		if err != nil {
+			logger.ErrorContext(ctx, "检查房间一致性失败", "room_id", roomID, "error", err.Error())
+			return failed(ctx, "检查房间一致性失败"), nil
+		}
+		if inconsistency != nil {
+			inconsistencies = append(inconsistencies, inconsistency)
+		}
+	}
+
+	logger.InfoContext(ctx, "房间一致性检查完成", "checked_rooms", len(roomIDs), "inconsistent_rooms", len(inconsistencies))
+
+	reportData, _ := json.Marshal(map[string]interface{}{
+		"checked_rooms":      len(roomIDs),
+		"inconsistent_rooms": inconsistencies,
+	})
+	return &Response{Code: 200, Message: "检查完成", Data: string(reportData)}, nil
+}
+
+// scanRoomIDs 读取房间ID列表，读取中途出错时返回错误而不是截断的列表
+func scanRoomIDs(rows *sql.Rows) ([]int64, error) {
+	defer rows.Close()
+	var roomIDs []int64
+	for rows.Next() {
+		var roomID int64
+		if err := rows.Scan(&roomID); err != nil {
+			return nil, err
+		}
+		roomIDs = append(roomIDs, roomID)
+	}
+	return roomIDs, rows.Err()
+}
+
+// checkRoomConsistency 对比单个房间，一致时返回nil
+func (s *MahjongService) checkRoomConsistency(ctx context.Context, roomID int64) (*RoomInconsistency, error) {
+	stored, err := loadStoredScores(ctx, s.db, roomID)
+	if err != nil {
+		return nil, err
+	}
+	events, err := loadRoomEvents(ctx, s.db, roomID)
+	if err != nil {
+		return nil, err
+	}
+	replayed, _ := replayRoomEvents(events)
+
+	var players []*PlayerInconsistency
+	for userID, storedScore := range stored {
+		replayedScore, inEvents := replayed[userID]
+		if !inEvents || replayedScore != storedScore {
+			players = append(players, &PlayerInconsistency{
+				UserId:        userID,
+				StoredScore:   storedScore,
+				ReplayedScore: replayedScore,
+				InEvents:      inEvents,
+				InRoomPlayers: true,
+			})
+		}
+	}
+	for userID, replayedScore := range replayed {
+		if _, ok := stored[userID]; !ok {
+			players = append(players, &PlayerInconsistency{
+				UserId:        userID,
+				ReplayedScore: replayedScore,
+				InEvents:      true,
+			})
+		}
+	}
+
+	if len(players) == 0 {
+		return nil, nil
+	}
+	return &RoomInconsistency{RoomId: roomID, Players: players}, nil
+}
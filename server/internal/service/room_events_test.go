@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var roomEventColumns = []string{"id", "room_id", "event_type", "user_id", "target_user_id", "amount", "transfer_id", "created_at"}
+
+// testRoomEvents 三人加入，1转给2十分，3转给2五分，撤销第一笔后结算
+func testRoomEvents() *sqlmock.Rows {
+	now := time.Now()
+	return sqlmock.NewRows(roomEventColumns).
+		AddRow(1, 9, RoomEventJoined, 1, 0, 0, 0, now).
+		AddRow(2, 9, RoomEventJoined, 2, 0, 0, 0, now).
+		AddRow(3, 9, RoomEventJoined, 3, 0, 0, 0, now).
+		AddRow(4, 9, RoomEventTransferred, 1, 2, 10, 101, now).
+		AddRow(5, 9, RoomEventTransferred, 3, 2, 5, 102, now).
+		AddRow(6, 9, RoomEventVoided, 1, 2, 10, 101, now).
+		AddRow(7, 9, RoomEventSettled, 2, 0, 0, 0, now)
+}
+
+func TestReplayRoomEvents(t *testing.T) {
+	service, mock := newMockService(t, nil)
+	mock.ExpectQuery("SELECT id, room_id, event_type").
+		WithArgs(int64(9)).
+		WillReturnRows(testRoomEvents())
+
+	events, err := loadRoomEvents(context.Background(), service.db, 9)
+	if err != nil {
+		t.Fatalf("读取事件失败: %v", err)
+	}
+	scores, settled := replayRoomEvents(events)
+	want := map[int64]int32{1: 0, 2: 5, 3: -5}
+	if len(scores) != len(want) {
+		t.Fatalf("回放分数 = %v，期望 %v", scores, want)
+	}
+	for userID, score := range want {
+		if scores[userID] != score {
+			t.Errorf("玩家%d回放分数 = %d，期望 %d", userID, scores[userID], score)
+		}
+	}
+	if !settled {
+		t.Error("有settled事件时应回放为已结算")
+	}
+}
+
+func TestRebuildRoom(t *testing.T) {
+	service, mock := newMockService(t, nil)
+	mock.ExpectBegin()
+	expectLockRoom(mock, 9, 2)
+	mock.ExpectQuery("(?s)SELECT id, room_id, event_type.*LOCK IN SHARE MODE").
+		WithArgs(int64(9)).
+		WillReturnRows(testRoomEvents())
+	// 分数按map遍历顺序写回，三条UPDATE不限顺序
+	mock.MatchExpectationsInOrder(false)
+	for userID, score := range map[int64]int32{1: 0, 2: 5, 3: -5} {
+		mock.ExpectExec("UPDATE room_players SET current_score = \\?, final_score = \\?").
+			WithArgs(score, score, int64(9), userID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+
+	resp, err := service.RebuildRoom(context.Background(), &RebuildRoomRequest{RoomId: 9})
+	if err != nil || resp.Code != 200 {
+		t.Fatalf("RebuildRoom = %+v, %v", resp, err)
+	}
+	var scores map[int64]int32
+	if err := json.Unmarshal([]byte(resp.Data), &scores); err != nil || scores[2] != 5 || scores[3] != -5 {
+		t.Errorf("重建结果 = %s", resp.Data)
+	}
+}
+
+func TestCheckConsistencyReportsMismatch(t *testing.T) {
+	service, mock := newMockService(t, nil)
+	mock.ExpectQuery("SELECT user_id, current_score FROM room_players").
+		WithArgs(int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "current_score"}).
+			AddRow(1, 0).AddRow(2, 15).AddRow(3, -5))
+	mock.ExpectQuery("SELECT id, room_id, event_type").
+		WithArgs(int64(9)).
+		WillReturnRows(testRoomEvents())
+
+	resp, err := service.CheckConsistency(context.Background(), &CheckConsistencyRequest{RoomId: 9})
+	if err != nil || resp.Code != 200 {
+		t.Fatalf("CheckConsistency = %+v, %v", resp, err)
+	}
+	var report struct {
+		CheckedRooms      int                  `json:"checked_rooms"`
+		InconsistentRooms []*RoomInconsistency `json:"inconsistent_rooms"`
+	}
+	if err := json.Unmarshal([]byte(resp.Data), &report); err != nil {
+		t.Fatalf("解析报告失败: %v", err)
+	}
+	if report.CheckedRooms != 1 || len(report.InconsistentRooms) != 1 {
+		t.Fatalf("报告 = %s", resp.Data)
+	}
+	players := report.InconsistentRooms[0].Players
+	if len(players) != 1 || players[0].UserId != 2 || players[0].StoredScore != 15 || players[0].ReplayedScore != 5 {
+		t.Errorf("不一致的玩家 = %s", resp.Data)
+	}
+}
+
+func TestCheckConsistencyRoomListError(t *testing.T) {
+	tests := []struct {
+		name string
+		rows *sqlmock.Rows
+	}{
+		{"读取房间ID失败", sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow("abc")},
+		{"遍历中途出错", sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).RowError(1, errors.New("connection reset"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mock := newMockService(t, nil)
+			mock.ExpectQuery("SELECT id FROM rooms").WillReturnRows(tt.rows)
+
+			// 不能只检查读到的部分房间就报告一致
+			resp, _ := service.CheckConsistency(context.Background(), &CheckConsistencyRequest{})
+			if resp.Code != 500 {
+				t.Fatalf("读取房间列表出错应返回500，实际 %d %s", resp.Code, resp.Data)
+			}
+		})
+	}
+}
@@ -33,6 +33,7 @@ type RoomPlayer struct {
 	FinalScore  int32     `json:"final_score"`
 	JoinedAt    time.Time `json:"joined_at"`
 	User        *User     `json:"user"`
+	Online      bool      `json:"online"` // 是否有WebSocket连接在线
 }
 
 // 分数转移记录
@@ -45,6 +46,7 @@ type ScoreTransfer struct {
 	CreatedAt    time.Time `json:"created_at"`
 	FromUserName string    `json:"from_user_name"`
 	ToUserName   string    `json:"to_user_name"`
+	Voided       bool      `json:"voided"`
 }
 
 // 结算记录
@@ -97,11 +99,22 @@ type GetUserRequest struct {
 type CreateRoomRequest struct {
 	CreatorId int64  `json:"creator_id"`
 	RoomName  string `json:"room_name"`
+	SessionID string `json:"session_id,omitempty"` // 关联群时必须，须与creator_id为同一用户
+	ShareGroup
+	GroupId int64 `json:"group_id,omitempty"` // 为创建者所在的群创建房间，与群分享信息二选一
 }
 
 type JoinRoomRequest struct {
-	UserId int64 `json:"user_id"`
-	RoomId int64 `json:"room_id"`
+	UserId    int64  `json:"user_id"`
+	RoomId    int64  `json:"room_id"`
+	SessionID string `json:"session_id,omitempty"` // 带群分享信息时必须，须与user_id为同一用户
+	ShareGroup
+}
+
+// ShareGroup 从群聊中的分享卡片打开时，wx.getShareInfo返回的加密群信息，用于把房间关联到该群
+type ShareGroup struct {
+	ShareEncryptedData string `json:"share_encrypted_data,omitempty"`
+	ShareIv            string `json:"share_iv,omitempty"`
 }
 
 type GetRoomRequest struct {
@@ -125,6 +138,12 @@ type TransferScoreRequest struct {
 	Amount     int32 `json:"amount"`
 }
 
+type VoidTransferRequest struct {
+	RoomId     int64 `json:"room_id"`
+	TransferId int64 `json:"transfer_id"`
+	UserId     int64 `json:"user_id"`
+}
+
 type SettleRoomRequest struct {
 	RoomId int64 `json:"room_id"`
 	UserId int64 `json:"user_id"`
@@ -149,3 +168,178 @@ type GenerateQRCodeRequest struct {
 	RoomId     int64  `json:"room_id"`
 	EnvVersion string `json:"env_version"` // 小程序版本: develop, trial, release
 }
+
+type GenerateQRCodeResponse struct {
+	RoomId     int64  `json:"room_id"`
+	EnvVersion string `json:"env_version"`
+	QRCodeURL  string `json:"qr_code_url"` // 图片访问路径，如 /api/v1/roomQRCode?env_version=release&room_id=1
+}
+
+type AvatarUploadURLRequest struct {
+	SessionID string `json:"session_id"`
+}
+
+type AvatarUploadURLResponse struct {
+	UploadURL string `json:"upload_url"` // 预签名地址，以Method上传原图，无需其他鉴权
+	Method    string `json:"method"`
+	Key       string `json:"key"` // 上传完成后传给confirmAvatarUpload
+	MaxSize   int    `json:"max_size"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+type ConfirmAvatarUploadRequest struct {
+	SessionID string `json:"session_id"`
+	Key       string `json:"key"`
+}
+
+type SubscribeConsentRequest struct {
+	SessionID   string   `json:"session_id"`
+	TemplateIds []string `json:"template_ids"` // wx.requestSubscribeMessage中结果为accept的模板
+}
+
+type RemindPaymentRequest struct {
+	SessionID string `json:"session_id"`
+	RoomId    int64  `json:"room_id"`
+	UserId    int64  `json:"user_id,omitempty"` // 被提醒的玩家，0表示提醒所有欠自己分数的玩家
+}
+
+// 开放数据解密请求，EncryptedData和Iv为小程序接口返回的原值
+type DecryptDataRequest struct {
+	SessionID     string `json:"session_id"`
+	EncryptedData string `json:"encrypted_data"`
+	Iv            string `json:"iv"`
+}
+
+type PhoneNumberResponse struct {
+	PhoneNumber     string `json:"phone_number"`      // 带区号的手机号，境外手机号会有区号
+	PurePhoneNumber string `json:"pure_phone_number"` // 不带区号的手机号
+	CountryCode     string `json:"country_code"`
+}
+
+type ShareInfoResponse struct {
+	GroupId int64  `json:"group_id"`
+	OpenGId string `json:"open_gid"` // 群的唯一标识，同一个群对同一个小程序不变
+}
+
+type GetUserGroupsRequest struct {
+	SessionID string `json:"session_id"`
+}
+
+type GetGroupRoomsRequest struct {
+	SessionID string `json:"session_id"`
+	GroupId   int64  `json:"group_id"`
+	Page      int32  `json:"page"`
+	PageSize  int32  `json:"page_size"`
+}
+
+type GetGroupLeaderboardRequest struct {
+	SessionID string `json:"session_id"`
+	GroupId   int64  `json:"group_id"`
+	Days      int32  `json:"days,omitempty"` // 只统计最近N天结算的房间，0表示全部
+}
+
+type GetGroupSuggestedMembersRequest struct {
+	SessionID string `json:"session_id"`
+	GroupId   int64  `json:"group_id"`
+	Limit     int32  `json:"limit,omitempty"`
+}
+
+// 群排行榜中的一名玩家，只统计已结算的房间
+type GroupLeaderboardEntry struct {
+	UserId     int64  `json:"user_id"`
+	Nickname   string `json:"nickname"`
+	AvatarUrl  string `json:"avatar_url"`
+	TotalScore int64  `json:"total_score"`
+	RoomCount  int64  `json:"room_count"`
+	WinCount   int64  `json:"win_count"` // 最终得分为正的房间数
+}
+
+// 建房时推荐的群成员
+type GroupMember struct {
+	UserId     int64  `json:"user_id"`
+	Nickname   string `json:"nickname"`
+	AvatarUrl  string `json:"avatar_url"`
+	RoomCount  int64  `json:"room_count"`   // 在该群的房间中玩过的次数
+	LastSeenAt int64  `json:"last_seen_at"` // 最近一次在群中打开或在群房间中游戏
+}
+
+type GetRoomScoreSeriesRequest struct {
+	RoomId         int64 `json:"room_id"`
+	LastTransferId int64 `json:"last_transfer_id,omitempty"` // 用于增量更新，0表示从头回放
+	BucketMinutes  int32 `json:"bucket_minutes,omitempty"`   // 按N分钟聚合，0表示每笔转移一个点
+	ByRound        bool  `json:"by_round,omitempty"`         // 按局聚合，不能与bucket_minutes同时使用；同一赢家2分钟内连续收到的转移算一局
+	VoidedCount    int32 `json:"voided_count,omitempty"`     // 上次响应的voided_count，增量获取时用于发现游标之前的撤销
+}
+
+// 分数曲线上的一个点：某次转移（或某个时间桶、某一局结束）后各玩家的累计分数
+type ScoreSeriesPoint struct {
+	TransferId int64           `json:"transfer_id"`
+	Timestamp  int64           `json:"timestamp"`
+	Round      int32           `json:"round,omitempty"` // 按局聚合时的局数，从1开始
+	Scores     map[int64]int32 `json:"scores"`          // user_id -> 累计分数
+}
+
+// 房间分数曲线
+type ScoreSeries struct {
+	RoomId         int64               `json:"room_id"`
+	Players        []*SeriesPlayer     `json:"players"`
+	Points         []*ScoreSeriesPoint `json:"points"`
+	LastTransferId int64               `json:"last_transfer_id"`
+	VoidedCount    int32               `json:"voided_count"`    // 房间已撤销的转移数，下次增量获取时带上
+	Reset          bool                `json:"reset,omitempty"` // 有新的撤销，本次为全量曲线，客户端应丢弃已有的点
+}
+
+// 分数曲线中的玩家
+type SeriesPlayer struct {
+	UserId   int64  `json:"user_id"`
+	Nickname string `json:"nickname"`
+}
+
+// 房间事件类型
+const (
+	RoomEventJoined      = "joined"
+	RoomEventTransferred = "transferred"
+	RoomEventVoided      = "voided"
+	RoomEventSettled     = "settled"
+)
+
+// 房间事件（只追加）
+type RoomEvent struct {
+	Id           int64     `json:"id"`
+	RoomId       int64     `json:"room_id"`
+	EventType    string    `json:"event_type"`
+	UserId       int64     `json:"user_id"`        // 加入者/转出用户/操作者
+	TargetUserId int64     `json:"target_user_id"` // 转入用户
+	Amount       int32     `json:"amount"`
+	TransferId   int64     `json:"transfer_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type RebuildRoomRequest struct {
+	RoomId int64 `json:"room_id"`
+}
+
+type MergeUsersRequest struct {
+	KeepUserId  int64 `json:"keep_user_id"`      // 保留的用户
+	MergeUserId int64 `json:"merge_user_id"`     // 被合并的用户，记录转移到保留的用户后标记为已合并
+	DryRun      bool  `json:"dry_run,omitempty"` // 只返回影响的记录数，不提交
+}
+
+type CheckConsistencyRequest struct {
+	RoomId int64 `json:"room_id,omitempty"` // 0表示检查所有房间
+}
+
+// 房间分数不一致报告
+type RoomInconsistency struct {
+	RoomId  int64                  `json:"room_id"`
+	Players []*PlayerInconsistency `json:"players"`
+}
+
+// 玩家存储分数与事件回放分数的差异
+type PlayerInconsistency struct {
+	UserId        int64 `json:"user_id"`
+	StoredScore   int32 `json:"stored_score"`
+	ReplayedScore int32 `json:"replayed_score"`
+	InEvents      bool  `json:"in_events"`
+	InRoomPlayers bool  `json:"in_room_players"`
+}
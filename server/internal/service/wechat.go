@@ -2,19 +2,73 @@ package service
 
 import (
 	"bytes"
+	"context"
 	"crypto/md5"
-	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"mahjong-server/internal/logger"
+	"mahjong-server/internal/metrics"
+)
+
+var (
+	wechatRequestDuration = metrics.NewHistogramVec("mahjong_wechat_request_duration_seconds",
+		"微信接口调用耗时（秒）", nil, "api")
+	wechatRequestErrors = metrics.NewCounterVec("mahjong_wechat_request_errors_total",
+		"微信接口调用失败次数（含网络错误和errcode非0）", "api")
 )
 
+// startWeChatCall 开始一次微信接口调用，返回的函数在调用结束时记录耗时、失败次数并结束span
+func startWeChatCall(ctx context.Context, api string) (context.Context, func(err error)) {
+	start := time.Now()
+	ctx, span := tracer.Start(ctx, "WeChat "+api, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("wechat.api", api)))
+	return ctx, func(err error) {
+		wechatRequestDuration.Observe(time.Since(start).Seconds(), api)
+		if err != nil {
+			wechatRequestErrors.Inc(api)
+			recordError(span, err)
+		}
+		span.End()
+	}
+}
+
+// DefaultWeChatAPIBase 微信服务端接口地址
+const DefaultWeChatAPIBase = "https://api.weixin.qq.com"
+
 type WeChatService struct {
-	appID     string
-	appSecret string
+	appID      string
+	appSecret  string
+	baseURL    string       // 接口地址，开发和测试时指向本地模拟服务
+	httpClient *http.Client // 各接口的超时通过ctx单独控制
+
+	tokens *tokenManager
+
+	tokenMu     sync.Mutex
+	tokenStatus TokenStatus
+}
+
+// TokenStatus access_token最近的获取结果，供健康检查判断依赖微信接口的功能（如小程序码）是否可用
+type TokenStatus struct {
+	Configured  bool // AppID和AppSecret均已配置
+	LastSuccess time.Time
+	LastFailure time.Time
+	LastError   string
+}
+
+// Healthy 已配置AppID，且尚未获取过或最近一次获取成功
+func (s TokenStatus) Healthy() bool {
+	return s.Configured && !s.LastSuccess.Before(s.LastFailure)
 }
 
 // 会话信息
@@ -53,19 +107,87 @@ type WeChatUserInfo struct {
 }
 
 func NewWeChatService(appID, appSecret string) *WeChatService {
-	return &WeChatService{
-		appID:     appID,
-		appSecret: appSecret,
+	w := &WeChatService{
+		appID:       appID,
+		appSecret:   appSecret,
+		baseURL:     DefaultWeChatAPIBase,
+		httpClient:  &http.Client{},
+		tokenStatus: TokenStatus{Configured: appID != "" && appSecret != ""},
+	}
+	w.tokens = newTokenManager(w.fetchAccessToken)
+	return w
+}
+
+// SetBaseURL 修改微信接口地址，如本地模拟服务或代理，需在处理请求前调用
+func (w *WeChatService) SetBaseURL(baseURL string) {
+	w.baseURL = strings.TrimSuffix(baseURL, "/")
+}
+
+// SetHTTPClient 修改调用微信接口的HTTP客户端，需在处理请求前调用
+func (w *WeChatService) SetHTTPClient(client *http.Client) {
+	w.httpClient = client
+}
+
+// apiURL 拼接接口地址和查询参数
+func (w *WeChatService) apiURL(path string, query url.Values) string {
+	return w.baseURL + path + "?" + query.Encode()
+}
+
+// SetTokenStore 设置access_token的共享存储，多实例部署时使用，需在处理请求前调用
+func (w *WeChatService) SetTokenStore(store TokenStore) {
+	w.tokens.store = store
+}
+
+// StartTokenRefresher 启动后台协程，在access_token过期前提前刷新，返回的函数用于停止
+func (w *WeChatService) StartTokenRefresher() func() {
+	stop := make(chan struct{})
+	go w.tokens.run(stop)
+	var once sync.Once
+	return func() { once.Do(func() { close(stop) }) }
+}
+
+// TokenStatus 返回access_token最近的获取结果
+func (w *WeChatService) TokenStatus() TokenStatus {
+	w.tokenMu.Lock()
+	defer w.tokenMu.Unlock()
+	return w.tokenStatus
+}
+
+// recordTokenResult 记录一次access_token获取结果，调用方取消的请求不计入
+func (w *WeChatService) recordTokenResult(err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	w.tokenMu.Lock()
+	defer w.tokenMu.Unlock()
+	if err != nil {
+		w.tokenStatus.LastFailure = time.Now()
+		w.tokenStatus.LastError = err.Error()
+		return
 	}
+	w.tokenStatus.LastSuccess = time.Now()
+	w.tokenStatus.LastError = ""
 }
 
 // 通过code获取微信用户openid和session_key
-func (w *WeChatService) GetOpenID(code string) (*WeChatLoginResponse, error) {
-	url := fmt.Sprintf("https://api.weixin.qq.com/sns/jscode2session?appid=%s&secret=%s&js_code=%s&grant_type=authorization_code",
-		w.appID, w.appSecret, code)
+func (w *WeChatService) GetOpenID(ctx context.Context, code string) (_ *WeChatLoginResponse, err error) {
+	ctx, done := startWeChatCall(ctx, "jscode2session")
+	defer func() { done(err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	requestURL := w.apiURL("/sns/jscode2session", url.Values{
+		"appid":      {w.appID},
+		"secret":     {w.appSecret},
+		"js_code":    {code},
+		"grant_type": {"authorization_code"},
+	})
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := w.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("请求微信API失败: %v", err)
 	}
@@ -89,11 +211,31 @@ func (w *WeChatService) GetOpenID(code string) (*WeChatLoginResponse, error) {
 }
 
 // 验证微信用户信息（通过encryptedData和iv解密）
+// 新版小程序的getUserProfile不再返回真实昵称头像，主要用于旧版客户端和获取unionid
 func (w *WeChatService) DecryptUserInfo(encryptedData, iv, sessionKey string) (*WeChatUserInfo, error) {
-	// 这里需要实现AES解密逻辑
-	// 由于微信小程序已经不再支持getUserInfo，我们主要使用wx.getUserProfile获取的用户信息
-	// 所以这个方法暂时返回nil，实际用户信息由前端直接传递
-	return nil, nil
+	var data struct {
+		OpenID    string `json:"openId"`
+		NickName  string `json:"nickName"`
+		Gender    int    `json:"gender"`
+		Province  string `json:"province"`
+		City      string `json:"city"`
+		Country   string `json:"country"`
+		AvatarURL string `json:"avatarUrl"`
+		UnionID   string `json:"unionId"`
+	}
+	if err := w.DecryptData(encryptedData, iv, sessionKey, &data); err != nil {
+		return nil, err
+	}
+	return &WeChatUserInfo{
+		OpenID:    data.OpenID,
+		NickName:  data.NickName,
+		Gender:    data.Gender,
+		Province:  data.Province,
+		City:      data.City,
+		Country:   data.Country,
+		AvatarURL: data.AvatarURL,
+		UnionID:   data.UnionID,
+	}, nil
 }
 
 // 验证用户信息的有效性
@@ -151,90 +293,286 @@ func (w *WeChatService) CreateSession(openID, sessionKey, unionID string) *Sessi
 	}
 }
 
-// 生成不限制的小程序码
-func (w *WeChatService) GenerateUnlimitedQRCode(roomID int64, envVersion string) (string, error) {
+// 生成不限制的小程序码，返回图片数据
+func (w *WeChatService) GenerateUnlimitedQRCode(ctx context.Context, roomID int64, envVersion string) ([]byte, error) {
 	// 获取access_token
-	accessToken, err := w.getAccessToken()
+	accessToken, err := w.tokens.Token(ctx)
 	if err != nil {
-		return "", fmt.Errorf("获取access_token失败: %v", err)
+		return nil, fmt.Errorf("获取access_token失败: %v", err)
 	}
-	
+
+	body, errCode, err := w.requestUnlimitedQRCode(ctx, accessToken, roomID, envVersion)
+	if isInvalidTokenErrCode(errCode) {
+		// token在别处被刷新或提前失效，强制刷新后重试一次
+		logger.WarnContext(ctx, "access_token已失效，刷新后重试", "errcode", errCode)
+		accessToken, err = w.tokens.Invalidate(ctx, accessToken)
+		if err != nil {
+			return nil, fmt.Errorf("获取access_token失败: %v", err)
+		}
+		body, _, err = w.requestUnlimitedQRCode(ctx, accessToken, roomID, envVersion)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// requestUnlimitedQRCode 调用getwxacodeunlimit，微信返回错误时同时返回errcode以便判断是否需要刷新token
+func (w *WeChatService) requestUnlimitedQRCode(ctx context.Context, accessToken string, roomID int64, envVersion string) (_ []byte, _ int, err error) {
+	ctx, done := startWeChatCall(ctx, "getwxacodeunlimit")
+	defer func() { done(err) }()
+
 	// 构建请求参数
 	requestData := map[string]interface{}{
-		"page":       "pages/room/room", // 直接跳转到房间页面
-		"scene":      "roomId=" + strconv.FormatInt(roomID, 10), // 传递房间ID参数
-		"check_path": false, // 不检查页面路径
-		"env_version": envVersion, // 使用传入的版本
-		"width":      430, // 二维码宽度
-		"auto_color": false, // 不自动配置颜色
-		"line_color": map[string]int{"r": 0, "g": 0, "b": 0}, // 黑色线条
-		"is_hyaline": false, // 不透明底色
+		"page":        "pages/room/room",                         // 直接跳转到房间页面
+		"scene":       "roomId=" + strconv.FormatInt(roomID, 10), // 传递房间ID参数
+		"check_path":  false,                                     // 不检查页面路径
+		"env_version": envVersion,                                // 使用传入的版本
+		"width":       430,                                       // 二维码宽度
+		"auto_color":  false,                                     // 不自动配置颜色
+		"line_color":  map[string]int{"r": 0, "g": 0, "b": 0},    // 黑色线条
+		"is_hyaline":  false,                                     // 不透明底色
 	}
-	
+
 	// 发送请求到微信API
-	url := fmt.Sprintf("https://api.weixin.qq.com/wxa/getwxacodeunlimit?access_token=%s", accessToken)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	requestURL := w.apiURL("/wxa/getwxacodeunlimit", url.Values{"access_token": {accessToken}})
 	jsonData, _ := json.Marshal(requestData)
-	
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Post(url, "application/json", bytes.NewBuffer(jsonData))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, requestURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("请求微信API失败: %v", err)
+		return nil, 0, fmt.Errorf("请求微信API失败: %v", err)
 	}
 	defer resp.Body.Close()
-	
+
 	// 读取响应
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("读取响应失败: %v", err)
+		return nil, 0, fmt.Errorf("读取响应失败: %v", err)
 	}
-	
-	// 检查是否是错误响应（JSON格式）
-	if resp.Header.Get("Content-Type") == "application/json" {
+
+	// 检查是否是错误响应（JSON格式，可能带有charset等参数）
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
 		var errorResp struct {
 			ErrCode int    `json:"errcode"`
 			ErrMsg  string `json:"errmsg"`
 		}
 		if err := json.Unmarshal(body, &errorResp); err == nil && errorResp.ErrCode != 0 {
-			return "", fmt.Errorf("微信API错误: %d - %s", errorResp.ErrCode, errorResp.ErrMsg)
+			return nil, errorResp.ErrCode, fmt.Errorf("微信API错误: %d - %s", errorResp.ErrCode, errorResp.ErrMsg)
 		}
 	}
-	
-	// 将图片数据转换为base64
-	base64Data := base64.StdEncoding.EncodeToString(body)
-	return base64Data, nil
+
+	return body, 0, nil
 }
 
-// 获取access_token
-func (w *WeChatService) getAccessToken() (string, error) {
-	url := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/token?grant_type=client_credential&appid=%s&secret=%s",
-		w.appID, w.appSecret)
-	
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(url)
+// APIError 微信接口返回的非0 errcode
+type APIError struct {
+	ErrCode int
+	ErrMsg  string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("微信API错误: %d - %s", e.ErrCode, e.ErrMsg)
+}
+
+// SubscribeMessage 订阅消息，Data为模板关键词到内容的映射，如 thing1 -> 房间名称
+type SubscribeMessage struct {
+	ToUser           string
+	TemplateID       string
+	Page             string // 点击消息打开的页面，可带参数
+	MiniprogramState string // developer、trial或formal
+	Data             map[string]string
+}
+
+// SendSubscribeMessage 发送订阅消息，微信返回错误时返回*APIError
+func (w *WeChatService) SendSubscribeMessage(ctx context.Context, msg *SubscribeMessage) error {
+	data := make(map[string]map[string]string, len(msg.Data))
+	for key, value := range msg.Data {
+		data[key] = map[string]string{"value": value}
+	}
+	body := map[string]interface{}{
+		"touser":            msg.ToUser,
+		"template_id":       msg.TemplateID,
+		"page":              msg.Page,
+		"miniprogram_state": msg.MiniprogramState,
+		"lang":              "zh_CN",
+		"data":              data,
+	}
+	return w.callWithToken(ctx, func(accessToken string) error {
+		return w.postJSON(ctx, "subscribe_send", "/cgi-bin/message/subscribe/send", accessToken, body, nil)
+	})
+}
+
+// SecSceneProfile 内容安全检测的资料场景，如昵称、房间名
+const SecSceneProfile = 1
+
+// MsgSecCheckResult 文本内容安全检测结果
+type MsgSecCheckResult struct {
+	Suggest  string   // pass、review或risky
+	Label    int      // 命中的标签，100为正常
+	Keywords []string // 关键词策略命中的词，可用于打码
+}
+
+// Risky 检测结果为违规
+func (r *MsgSecCheckResult) Risky() bool {
+	return r.Suggest == "risky"
+}
+
+// MsgSecCheck 调用msg_sec_check（2.0版本）检测文本，openID须为近两小时访问过小程序的用户
+func (w *WeChatService) MsgSecCheck(ctx context.Context, openID, content string, scene int) (*MsgSecCheckResult, error) {
+	body := map[string]interface{}{
+		"content": content,
+		"version": 2,
+		"scene":   scene,
+		"openid":  openID,
+	}
+	var resp struct {
+		Result struct {
+			Suggest string `json:"suggest"`
+			Label   int    `json:"label"`
+		} `json:"result"`
+		Detail []struct {
+			Strategy string `json:"strategy"`
+			Suggest  string `json:"suggest"`
+			Keyword  string `json:"keyword"`
+		} `json:"detail"`
+	}
+	err := w.callWithToken(ctx, func(accessToken string) error {
+		return w.postJSON(ctx, "msg_sec_check", "/wxa/msg_sec_check", accessToken, body, &resp)
+	})
 	if err != nil {
-		return "", fmt.Errorf("请求access_token失败: %v", err)
+		return nil, err
+	}
+
+	result := &MsgSecCheckResult{Suggest: resp.Result.Suggest, Label: resp.Result.Label}
+	for _, detail := range resp.Detail {
+		if detail.Strategy == "keyword" && detail.Suggest != "pass" && detail.Keyword != "" {
+			result.Keywords = append(result.Keywords, detail.Keyword)
+		}
+	}
+	return result, nil
+}
+
+// callWithToken 使用access_token调用接口，token在别处被刷新或提前失效时强制刷新后重试一次
+func (w *WeChatService) callWithToken(ctx context.Context, call func(accessToken string) error) error {
+	accessToken, err := w.tokens.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("获取access_token失败: %v", err)
+	}
+
+	err = call(accessToken)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && isInvalidTokenErrCode(apiErr.ErrCode) {
+		logger.WarnContext(ctx, "access_token已失效，刷新后重试", "errcode", apiErr.ErrCode)
+		accessToken, err = w.tokens.Invalidate(ctx, accessToken)
+		if err != nil {
+			return fmt.Errorf("获取access_token失败: %v", err)
+		}
+		err = call(accessToken)
+	}
+	return err
+}
+
+// postJSON 以JSON调用返回JSON的接口，errcode非0时返回*APIError，out为nil时忽略其他字段
+func (w *WeChatService) postJSON(ctx context.Context, api, path, accessToken string, body, out interface{}) (err error) {
+	ctx, done := startWeChatCall(ctx, api)
+	defer func() { done(err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	jsonData, _ := json.Marshal(body)
+	requestURL := w.apiURL(path, url.Values{"access_token": {accessToken}})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, requestURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求微信API失败: %v", err)
 	}
 	defer resp.Body.Close()
-	
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("读取响应失败: %v", err)
+	}
+	var result struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return fmt.Errorf("解析响应失败: %v", err)
+	}
+	if result.ErrCode != 0 {
+		return &APIError{ErrCode: result.ErrCode, ErrMsg: result.ErrMsg}
+	}
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("解析响应失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// fetchAccessToken 从微信接口获取新的access_token，只由tokenManager调用，其他地方通过w.tokens获取
+func (w *WeChatService) fetchAccessToken(ctx context.Context) (_ *AccessToken, err error) {
+	ctx, done := startWeChatCall(ctx, "token")
+	defer func() {
+		done(err)
+		w.recordTokenResult(err)
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	requestURL := w.apiURL("/cgi-bin/token", url.Values{
+		"grant_type": {"client_credential"},
+		"appid":      {w.appID},
+		"secret":     {w.appSecret},
+	})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求access_token失败: %v", err)
+	}
+	defer resp.Body.Close()
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("读取响应失败: %v", err)
+		return nil, fmt.Errorf("读取响应失败: %v", err)
 	}
-	
+
 	var result struct {
 		AccessToken string `json:"access_token"`
 		ExpiresIn   int    `json:"expires_in"`
 		ErrCode     int    `json:"errcode"`
 		ErrMsg      string `json:"errmsg"`
 	}
-	
+
 	if err := json.Unmarshal(body, &result); err != nil {
-		return "", fmt.Errorf("解析响应失败: %v", err)
+		return nil, fmt.Errorf("解析响应失败: %v", err)
 	}
-	
+
 	if result.ErrCode != 0 {
-		return "", fmt.Errorf("获取access_token失败: %d - %s", result.ErrCode, result.ErrMsg)
+		return nil, fmt.Errorf("获取access_token失败: %d - %s", result.ErrCode, result.ErrMsg)
 	}
-	
-	return result.AccessToken, nil
+	if result.AccessToken == "" || result.ExpiresIn <= 0 {
+		return nil, fmt.Errorf("获取access_token失败: 响应缺少access_token或expires_in")
+	}
+
+	logger.InfoContext(ctx, "已刷新access_token", "expires_in", result.ExpiresIn)
+	return &AccessToken{
+		Value:     result.AccessToken,
+		ExpiresAt: time.Now().Add(time.Duration(result.ExpiresIn) * time.Second),
+	}, nil
 }
@@ -0,0 +1,298 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"mahjong-server/internal/wechatfake"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n")
+
+var loginUserColumns = []string{"id", "openid", "unionid", "nickname", "avatar_url", "merged_into", "created_at", "updated_at"}
+
+// decodeLogin 解析AutoLogin的响应数据
+func decodeLogin(t *testing.T, resp *Response) (user User, sessionID string) {
+	t.Helper()
+	if resp.Code != 200 {
+		t.Fatalf("AutoLogin返回 %d %s", resp.Code, resp.Message)
+	}
+	var data struct {
+		User      User   `json:"user"`
+		SessionID string `json:"session_id"`
+	}
+	if err := json.Unmarshal([]byte(resp.Data), &data); err != nil {
+		t.Fatalf("解析登录响应失败: %v", err)
+	}
+	if len(data.SessionID) != 32 {
+		t.Fatalf("session_id = %q", data.SessionID)
+	}
+	return data.User, data.SessionID
+}
+
+func TestAutoLoginCreatesUser(t *testing.T) {
+	wechat, fake := newFakeWeChat(t)
+	service, mock := newMockService(t, wechat)
+	openID := wechatfake.OpenID(testAppID, "code-new")
+	unionID := wechatfake.UnionID("code-new")
+
+	mock.ExpectQuery("FROM users WHERE unionid = ").WithArgs(unionID).WillReturnRows(sqlmock.NewRows(loginUserColumns))
+	mock.ExpectQuery("FROM user_openids WHERE app_id = ").WithArgs(testAppID, openID).WillReturnRows(sqlmock.NewRows(loginUserColumns))
+	mock.ExpectQuery("FROM users WHERE openid = ").WithArgs(openID).WillReturnRows(sqlmock.NewRows(loginUserColumns))
+	mock.ExpectExec("INSERT INTO users").
+		WithArgs(openID, unionID, "微信用户", "/images/default-avatar.png").
+		WillReturnResult(sqlmock.NewResult(41, 1))
+	mock.ExpectExec("INSERT INTO user_openids").
+		WithArgs(testAppID, openID, int64(41)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO user_sessions").
+		WithArgs(sqlmock.AnyArg(), int64(41), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	resp, err := service.AutoLogin(context.Background(), &AutoLoginRequest{Code: "code-new"})
+	if err != nil {
+		t.Fatalf("AutoLogin返回错误: %v", err)
+	}
+	user, _ := decodeLogin(t, resp)
+	if user.Id != 41 || user.Openid != openID {
+		t.Fatalf("新用户 = %+v", user)
+	}
+	if calls := fake.Calls(wechatfake.APIJSCode2Session); calls != 1 {
+		t.Fatalf("jscode2session调用了 %d 次", calls)
+	}
+}
+
+func TestAutoLoginExistingUser(t *testing.T) {
+	wechat, _ := newFakeWeChat(t)
+	service, mock := newMockService(t, wechat)
+	openID := wechatfake.OpenID(testAppID, "code-old")
+	unionID := wechatfake.UnionID("code-old")
+	now := time.Now()
+
+	// 按unionid找到的用户已被合并到7，登录到合并后的用户
+	mock.ExpectQuery("FROM users WHERE unionid = ").WithArgs(unionID).
+		WillReturnRows(sqlmock.NewRows(loginUserColumns).AddRow(3, openID, unionID, "老用户", "", 7, now, now))
+	mock.ExpectQuery("FROM user_openids WHERE app_id = ").WithArgs(testAppID, openID).
+		WillReturnRows(sqlmock.NewRows(loginUserColumns).AddRow(3, openID, unionID, "老用户", "", 7, now, now))
+	mock.ExpectQuery("FROM users WHERE id = ").WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows(loginUserColumns).AddRow(7, "oOther", unionID, "合并后", "", 0, now, now))
+	mock.ExpectExec("UPDATE users SET updated_at").WithArgs(int64(7)).WillReturnResult(sqlmock.NewResult(0, 1))
+	// 当前小程序的openid改为指向合并后的用户
+	mock.ExpectExec("INSERT INTO user_openids").
+		WithArgs(testAppID, openID, int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO user_sessions").
+		WithArgs(sqlmock.AnyArg(), int64(7), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	resp, _ := service.AutoLogin(context.Background(), &AutoLoginRequest{Code: "code-old"})
+	if user, _ := decodeLogin(t, resp); user.Id != 7 || user.Nickname != "合并后" {
+		t.Fatalf("应登录到合并后的用户: %+v", user)
+	}
+}
+
+func TestAutoLoginFromSecondApp(t *testing.T) {
+	wechat, _ := newFakeWeChat(t)
+	service, mock := newMockService(t, wechat)
+	openID := wechatfake.OpenID(testAppID, "code-second")
+	unionID := wechatfake.UnionID("code-second")
+	now := time.Now()
+
+	// 用户在另一个小程序中创建，users.openid是那个小程序的openid
+	mock.ExpectQuery("FROM users WHERE unionid = ").WithArgs(unionID).
+		WillReturnRows(sqlmock.NewRows(loginUserColumns).AddRow(5, "oFirstApp", unionID, "玩家", "", 0, now, now))
+	mock.ExpectQuery("FROM user_openids WHERE app_id = ").WithArgs(testAppID, openID).
+		WillReturnRows(sqlmock.NewRows(loginUserColumns))
+	mock.ExpectQuery("FROM users WHERE openid = ").WithArgs(openID).
+		WillReturnRows(sqlmock.NewRows(loginUserColumns))
+	mock.ExpectExec("UPDATE users SET updated_at").WithArgs(int64(5)).WillReturnResult(sqlmock.NewResult(0, 1))
+	// 保存当前小程序的openid，之后发送订阅消息用它
+	mock.ExpectExec("INSERT INTO user_openids").
+		WithArgs(testAppID, openID, int64(5)).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectExec("INSERT INTO user_sessions").
+		WithArgs(sqlmock.AnyArg(), int64(5), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	resp, _ := service.AutoLogin(context.Background(), &AutoLoginRequest{Code: "code-second"})
+	user, _ := decodeLogin(t, resp)
+	if user.Id != 5 || user.Openid != openID {
+		t.Fatalf("应登录到同一用户并返回当前小程序的openid: %+v", user)
+	}
+}
+
+func TestAutoLoginConcurrentFirstLogin(t *testing.T) {
+	wechat, _ := newFakeWeChat(t)
+	service, mock := newMockService(t, wechat)
+	openID := wechatfake.OpenID(testAppID, "code-race")
+	unionID := wechatfake.UnionID("code-race")
+	now := time.Now()
+
+	mock.ExpectQuery("FROM users WHERE unionid = ").WithArgs(unionID).WillReturnRows(sqlmock.NewRows(loginUserColumns))
+	mock.ExpectQuery("FROM user_openids WHERE app_id = ").WithArgs(testAppID, openID).WillReturnRows(sqlmock.NewRows(loginUserColumns))
+	mock.ExpectQuery("FROM users WHERE openid = ").WithArgs(openID).WillReturnRows(sqlmock.NewRows(loginUserColumns))
+	// 并发的另一个登录请求先插入了同一unionid的用户
+	mock.ExpectExec("INSERT INTO users").
+		WithArgs(openID, unionID, "微信用户", "/images/default-avatar.png").
+		WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry for key 'unionid'"})
+	mock.ExpectQuery("FROM users WHERE unionid = ").WithArgs(unionID).
+		WillReturnRows(sqlmock.NewRows(loginUserColumns).AddRow(42, openID, unionID, "微信用户", "", 0, now, now))
+	mock.ExpectQuery("FROM user_openids WHERE app_id = ").WithArgs(testAppID, openID).
+		WillReturnRows(sqlmock.NewRows(loginUserColumns))
+	mock.ExpectQuery("FROM users WHERE openid = ").WithArgs(openID).
+		WillReturnRows(sqlmock.NewRows(loginUserColumns).AddRow(42, openID, unionID, "微信用户", "", 0, now, now))
+	mock.ExpectExec("UPDATE users SET updated_at").WithArgs(int64(42)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO user_openids").
+		WithArgs(testAppID, openID, int64(42)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO user_sessions").
+		WithArgs(sqlmock.AnyArg(), int64(42), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	resp, _ := service.AutoLogin(context.Background(), &AutoLoginRequest{Code: "code-race"})
+	if user, _ := decodeLogin(t, resp); user.Id != 42 || user.Openid != openID {
+		t.Fatalf("唯一索引冲突后应登录到已创建的用户: %+v", user)
+	}
+}
+
+func TestAutoLoginInsertError(t *testing.T) {
+	wechat, _ := newFakeWeChat(t)
+	service, mock := newMockService(t, wechat)
+	openID := wechatfake.OpenID(testAppID, "code-fail")
+	unionID := wechatfake.UnionID("code-fail")
+
+	mock.ExpectQuery("FROM users WHERE unionid = ").WithArgs(unionID).WillReturnRows(sqlmock.NewRows(loginUserColumns))
+	mock.ExpectQuery("FROM user_openids WHERE app_id = ").WithArgs(testAppID, openID).WillReturnRows(sqlmock.NewRows(loginUserColumns))
+	mock.ExpectQuery("FROM users WHERE openid = ").WithArgs(openID).WillReturnRows(sqlmock.NewRows(loginUserColumns))
+	mock.ExpectExec("INSERT INTO users").WillReturnError(errors.New("connection reset"))
+
+	// 唯一索引冲突以外的错误不重新查询
+	if resp, _ := service.AutoLogin(context.Background(), &AutoLoginRequest{Code: "code-fail"}); resp.Code != 500 {
+		t.Fatalf("插入失败应返回500，实际 %d %s", resp.Code, resp.Message)
+	}
+}
+
+func TestAutoLoginWeChatError(t *testing.T) {
+	wechat, fake := newFakeWeChat(t)
+	service, _ := newMockService(t, wechat)
+	fake.FailNext(wechatfake.APIJSCode2Session, 40163, "code been used")
+
+	resp, _ := service.AutoLogin(context.Background(), &AutoLoginRequest{Code: "code-used"})
+	if resp.Code != 500 || !strings.Contains(resp.Message, "40163") {
+		t.Fatalf("code已使用时应返回微信错误，实际 %d %s", resp.Code, resp.Message)
+	}
+}
+
+func TestAutoLoginWrongSecret(t *testing.T) {
+	// 模拟服务只接受另一个AppSecret
+	wechat := newWeChatFor(newFakeServer(t, wechatfake.New(testAppID, "another_secret")))
+	service, _ := newMockService(t, wechat)
+
+	resp, _ := service.AutoLogin(context.Background(), &AutoLoginRequest{Code: "code"})
+	if resp.Code != 500 || !strings.Contains(resp.Message, "40125") {
+		t.Fatalf("AppSecret错误时应返回微信错误，实际 %d %s", resp.Code, resp.Message)
+	}
+}
+
+func TestGenerateUnlimitedQRCode(t *testing.T) {
+	wechat, fake := newFakeWeChat(t)
+	image, err := wechat.GenerateUnlimitedQRCode(context.Background(), 42, "release")
+	if err != nil {
+		t.Fatalf("生成小程序码失败: %v", err)
+	}
+	if !bytes.HasPrefix(image, pngHeader) {
+		t.Fatalf("返回的不是PNG图片，长度 %d", len(image))
+	}
+	// 再次生成复用缓存的access_token
+	if _, err := wechat.GenerateUnlimitedQRCode(context.Background(), 43, "trial"); err != nil {
+		t.Fatalf("生成小程序码失败: %v", err)
+	}
+	if calls := fake.Calls(wechatfake.APIToken); calls != 1 {
+		t.Fatalf("access_token获取了 %d 次", calls)
+	}
+}
+
+func TestQRCodeRetriesOnInvalidToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(fake *wechatfake.Server)
+	}{
+		// token在别处被刷新，本实例手中的token失效，微信返回40001
+		{"token被撤销", func(fake *wechatfake.Server) { fake.RevokeTokens() }},
+		{"预设40001", func(fake *wechatfake.Server) {
+			fake.FailNext(wechatfake.APIGetWxaCodeUnlimit, 40001, "invalid credential")
+		}},
+		{"预设42001", func(fake *wechatfake.Server) {
+			fake.FailNext(wechatfake.APIGetWxaCodeUnlimit, 42001, "access_token expired")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wechat, fake := newFakeWeChat(t)
+			ctx := context.Background()
+			if _, err := wechat.GenerateUnlimitedQRCode(ctx, 1, "release"); err != nil {
+				t.Fatalf("首次生成失败: %v", err)
+			}
+
+			tt.prepare(fake)
+			image, err := wechat.GenerateUnlimitedQRCode(ctx, 2, "release")
+			if err != nil {
+				t.Fatalf("token失效后应刷新并重试成功: %v", err)
+			}
+			if !bytes.HasPrefix(image, pngHeader) {
+				t.Fatal("重试后返回的不是PNG图片")
+			}
+			if calls := fake.Calls(wechatfake.APIToken); calls != 2 {
+				t.Fatalf("access_token应刷新一次，共获取 %d 次", calls)
+			}
+			if calls := fake.Calls(wechatfake.APIGetWxaCodeUnlimit); calls != 3 {
+				t.Fatalf("getwxacodeunlimit调用了 %d 次", calls)
+			}
+		})
+	}
+}
+
+func TestQRCodeDoesNotRetryOtherErrors(t *testing.T) {
+	wechat, fake := newFakeWeChat(t)
+	fake.FailNext(wechatfake.APIGetWxaCodeUnlimit, 45009, "reach max api daily quota limit")
+
+	_, err := wechat.GenerateUnlimitedQRCode(context.Background(), 1, "release")
+	if err == nil || !strings.Contains(err.Error(), "45009") {
+		t.Fatalf("应返回45009错误，实际 %v", err)
+	}
+	if calls := fake.Calls(wechatfake.APIGetWxaCodeUnlimit); calls != 1 {
+		t.Fatalf("与token无关的错误不应重试，调用了 %d 次", calls)
+	}
+}
+
+func TestCallWithTokenRetriesOnce(t *testing.T) {
+	wechat, fake := newFakeWeChat(t)
+	msg := &SubscribeMessage{ToUser: "oUser", TemplateID: "tmpl", Data: map[string]string{"thing1": "房间"}}
+
+	// 刷新后的token仍然无效时只重试一次
+	fake.FailNext(wechatfake.APISubscribeSend, 40001, "invalid credential")
+	fake.FailNext(wechatfake.APISubscribeSend, 40001, "invalid credential")
+	err := wechat.SendSubscribeMessage(context.Background(), msg)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.ErrCode != 40001 {
+		t.Fatalf("连续两次40001应返回APIError，实际 %v", err)
+	}
+	if calls := fake.Calls(wechatfake.APISubscribeSend); calls != 2 {
+		t.Fatalf("只应重试一次，调用了 %d 次", calls)
+	}
+
+	fake.RevokeTokens()
+	if err := wechat.SendSubscribeMessage(context.Background(), msg); err != nil {
+		t.Fatalf("token被撤销后应刷新并重试成功: %v", err)
+	}
+	if got := fake.Messages(); len(got) != 1 || got[0].ToUser != "oUser" || got[0].Data["thing1"]["value"] != "房间" {
+		t.Fatalf("收到的订阅消息 = %+v", got)
+	}
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"mahjong-server/internal/logger"
+)
+
+const (
+	// tokenRefreshMargin 距过期不足该时长时后台提前刷新，请求路径上基本不会遇到过期
+	tokenRefreshMargin = 5 * time.Minute
+	// tokenExpirySafety 距过期不足该时长视为已过期，留出网络传输和时钟误差
+	tokenExpirySafety = 30 * time.Second
+	// tokenRefreshInterval 后台检查是否需要刷新的间隔
+	tokenRefreshInterval = time.Minute
+	// tokenFetchTimeout 单次刷新（含等待其他实例）的超时，与发起请求的ctx无关
+	tokenFetchTimeout = 15 * time.Second
+	// tokenLockTTL 多实例刷新锁的有效期
+	tokenLockTTL = 10 * time.Second
+	// tokenWaitInterval 未获得刷新锁时轮询共享存储的间隔
+	tokenWaitInterval = 200 * time.Millisecond
+)
+
+// 微信接口返回的access_token无效或过期的错误码，收到后强制刷新并重试一次
+const (
+	errCodeInvalidCredential = 40001
+	errCodeInvalidToken      = 40014
+	errCodeTokenExpired      = 42001
+)
+
+func isInvalidTokenErrCode(code int) bool {
+	return code == errCodeInvalidCredential || code == errCodeInvalidToken || code == errCodeTokenExpired
+}
+
+// AccessToken 微信接口调用凭证
+type AccessToken struct {
+	Value     string
+	ExpiresAt time.Time
+}
+
+// validFor token在d之后是否仍然有效
+func (t *AccessToken) validFor(d time.Duration) bool {
+	return t != nil && t.Value != "" && time.Until(t.ExpiresAt) > d
+}
+
+// TokenStore access_token的共享存储，多实例部署时各实例共用同一个token，
+// 避免各自刷新使其他实例手中的token失效
+type TokenStore interface {
+	// Load 读取token，不存在时返回nil
+	Load(ctx context.Context) (*AccessToken, error)
+	Save(ctx context.Context, token *AccessToken) error
+	// TryLock 尝试获取刷新锁，成功的实例负责调用微信接口，返回的函数用于释放锁
+	TryLock(ctx context.Context) (bool, func(), error)
+}
+
+// tokenCall 进行中的一次刷新，并发请求等待同一次结果
+type tokenCall struct {
+	done  chan struct{}
+	token *AccessToken
+	err   error
+}
+
+// tokenManager 缓存access_token，按expires_in提前刷新，并发刷新合并为一次
+type tokenManager struct {
+	fetch func(ctx context.Context) (*AccessToken, error)
+	store TokenStore // 为nil时只在进程内缓存
+
+	mu       sync.Mutex
+	current  *AccessToken
+	inflight *tokenCall
+}
+
+func newTokenManager(fetch func(ctx context.Context) (*AccessToken, error)) *tokenManager {
+	return &tokenManager{fetch: fetch}
+}
+
+// Token 返回有效的token，缓存过期时刷新
+func (m *tokenManager) Token(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	if m.current.validFor(tokenExpirySafety) {
+		token := m.current.Value
+		m.mu.Unlock()
+		return token, nil
+	}
+	m.mu.Unlock()
+	return m.refresh(ctx, "", tokenRefreshMargin)
+}
+
+// Invalidate 微信返回token无效时调用，丢弃stale并刷新；其他请求已刷新过时直接返回新token
+func (m *tokenManager) Invalidate(ctx context.Context, stale string) (string, error) {
+	m.mu.Lock()
+	if m.current != nil && m.current.Value != stale && m.current.validFor(tokenExpirySafety) {
+		token := m.current.Value
+		m.mu.Unlock()
+		return token, nil
+	}
+	m.current = nil
+	m.mu.Unlock()
+	return m.refresh(ctx, stale, tokenExpirySafety)
+}
+
+// refresh 刷新token，同一时间只有一次刷新在进行，其余调用等待其结果
+// 刷新在独立的ctx中进行，发起请求的ctx取消只影响自己的等待
+// minValid为共享存储中的token可直接使用所需的剩余有效期，提前刷新时为tokenRefreshMargin
+func (m *tokenManager) refresh(ctx context.Context, stale string, minValid time.Duration) (string, error) {
+	m.mu.Lock()
+	call := m.inflight
+	if call == nil {
+		call = &tokenCall{done: make(chan struct{})}
+		m.inflight = call
+		go m.doRefresh(context.WithoutCancel(ctx), call, stale, minValid)
+	}
+	m.mu.Unlock()
+
+	select {
+	case <-call.done:
+		if call.err != nil {
+			return "", call.err
+		}
+		return call.token.Value, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (m *tokenManager) doRefresh(ctx context.Context, call *tokenCall, stale string, minValid time.Duration) {
+	ctx, cancel := context.WithTimeout(ctx, tokenFetchTimeout)
+	defer cancel()
+
+	call.token, call.err = m.obtain(ctx, stale, minValid)
+
+	m.mu.Lock()
+	if call.err == nil {
+		m.current = call.token
+	}
+	m.inflight = nil
+	m.mu.Unlock()
+	close(call.done)
+}
+
+// obtain 优先使用共享存储中其他实例刷新好的token，否则获取刷新锁后调用微信接口
+func (m *tokenManager) obtain(ctx context.Context, stale string, minValid time.Duration) (*AccessToken, error) {
+	if m.store == nil {
+		return m.fetch(ctx)
+	}
+
+	if token := m.loadShared(ctx, stale, minValid); token != nil {
+		return token, nil
+	}
+
+	locked, unlock, err := m.store.TryLock(ctx)
+	if err != nil {
+		// 共享存储不可用时退化为各实例自行刷新
+		logger.WarnContext(ctx, "获取access_token刷新锁失败，直接刷新", "error", err.Error())
+		return m.fetch(ctx)
+	}
+	if !locked {
+		// 其他实例正在刷新，等待其写入共享存储
+		ticker := time.NewTicker(tokenWaitInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if token := m.loadShared(ctx, stale, minValid); token != nil {
+					return token, nil
+				}
+			case <-ctx.Done():
+				return nil, fmt.Errorf("等待其他实例刷新access_token超时: %w", ctx.Err())
+			}
+		}
+	}
+	defer unlock()
+
+	// 获得锁前其他实例可能刚刷新完
+	if token := m.loadShared(ctx, stale, minValid); token != nil {
+		return token, nil
+	}
+	token, err := m.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.store.Save(ctx, token); err != nil {
+		logger.WarnContext(ctx, "保存access_token到共享存储失败", "error", err.Error())
+	}
+	return token, nil
+}
+
+// loadShared 读取共享存储中剩余有效期超过minValid的token，与stale相同（已知无效）时忽略
+func (m *tokenManager) loadShared(ctx context.Context, stale string, minValid time.Duration) *AccessToken {
+	token, err := m.store.Load(ctx)
+	if err != nil {
+		logger.WarnContext(ctx, "读取共享存储中的access_token失败", "error", err.Error())
+		return nil
+	}
+	if !token.validFor(minValid) || token.Value == stale {
+		return nil
+	}
+	return token
+}
+
+// run 在后台定期检查，token将在tokenRefreshMargin内过期时提前刷新
+// 尚未获取过token时不刷新，避免未使用微信接口的实例消耗调用次数
+func (m *tokenManager) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(tokenRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			m.refreshIfDue(context.Background())
+		}
+	}
+}
+
+// refreshIfDue token将在tokenRefreshMargin内过期时刷新；共享存储中同样即将过期的token
+// （通常就是本实例手中的这个）不能作为刷新结果，否则要等到过期前tokenExpirySafety才真正刷新
+func (m *tokenManager) refreshIfDue(ctx context.Context) {
+	m.mu.Lock()
+	due := m.current != nil && !m.current.validFor(tokenRefreshMargin)
+	m.mu.Unlock()
+	if !due {
+		return
+	}
+	if _, err := m.refresh(ctx, "", tokenRefreshMargin); err != nil {
+		logger.WarnContext(ctx, "提前刷新access_token失败", "error", err.Error())
+	}
+}
+
+// unlockScript 值与持有者一致时才删除锁
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisTokenStore 将access_token保存在Redis中，供多实例共享
+type RedisTokenStore struct {
+	client *redis.Client
+	key    string
+}
+
+// NewRedisTokenStore 创建Redis共享存储，key区分不同的小程序
+func NewRedisTokenStore(client *redis.Client, key string) *RedisTokenStore {
+	return &RedisTokenStore{client: client, key: key}
+}
+
+// Load 值的格式为 过期时间(Unix秒):token
+func (s *RedisTokenStore) Load(ctx context.Context) (*AccessToken, error) {
+	value, err := s.client.Get(ctx, s.key).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	expiresAt, token, ok := strings.Cut(value, ":")
+	if !ok {
+		return nil, fmt.Errorf("access_token格式错误")
+	}
+	unix, err := strconv.ParseInt(expiresAt, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("access_token过期时间格式错误: %v", err)
+	}
+	return &AccessToken{Value: token, ExpiresAt: time.Unix(unix, 0)}, nil
+}
+
+func (s *RedisTokenStore) Save(ctx context.Context, token *AccessToken) error {
+	ttl := time.Until(token.ExpiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.client.Set(ctx, s.key, strconv.FormatInt(token.ExpiresAt.Unix(), 10)+":"+token.Value, ttl).Err()
+}
+
+func (s *RedisTokenStore) TryLock(ctx context.Context) (bool, func(), error) {
+	var b [8]byte
+	rand.Read(b[:])
+	owner := hex.EncodeToString(b[:])
+	lockKey := s.key + ":lock"
+
+	locked, err := s.client.SetNX(ctx, lockKey, owner, tokenLockTTL).Result()
+	if err != nil || !locked {
+		return false, nil, err
+	}
+	return true, func() {
+		// 只删除自己持有的锁，锁已过期被其他实例取得时不删除
+		if err := unlockScript.Run(context.Background(), s.client, []string{lockKey}, owner).Err(); err != nil && !errors.Is(err, redis.Nil) {
+			logger.Warn("释放access_token刷新锁失败", "error", err.Error())
+		}
+	}, nil
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"mahjong-server/internal/wechatfake"
+)
+
+const (
+	testAppID     = "wx_test_app"
+	testAppSecret = "test_secret"
+)
+
+// newFakeWeChat 启动模拟微信接口，返回指向它的WeChatService
+func newFakeWeChat(t *testing.T) (*WeChatService, *wechatfake.Server) {
+	t.Helper()
+	fake := wechatfake.New(testAppID, testAppSecret)
+	return newWeChatFor(newFakeServer(t, fake)), fake
+}
+
+// newFakeServer 以httptest启动模拟微信接口，返回其地址
+func newFakeServer(t *testing.T, fake *wechatfake.Server) string {
+	t.Helper()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func newWeChatFor(baseURL string) *WeChatService {
+	w := NewWeChatService(testAppID, testAppSecret)
+	w.SetBaseURL(baseURL)
+	return w
+}
+
+// useSharedTokenStore 为各实例设置同一个miniredis中的共享存储
+func useSharedTokenStore(t *testing.T, services ...*WeChatService) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	for _, w := range services {
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { client.Close() })
+		w.SetTokenStore(NewRedisTokenStore(client, "mahjong:test:wechat_token"))
+	}
+}
+
+func TestTokenCachedUntilExpirySafety(t *testing.T) {
+	w, fake := newFakeWeChat(t)
+	ctx := context.Background()
+
+	first, err := w.tokens.Token(ctx)
+	if err != nil {
+		t.Fatalf("获取token失败: %v", err)
+	}
+	if again, _ := w.tokens.Token(ctx); again != first || fake.Calls(wechatfake.APIToken) != 1 {
+		t.Fatalf("有效期内应使用缓存，调用了 %d 次", fake.Calls(wechatfake.APIToken))
+	}
+
+	// 剩余有效期不足tokenExpirySafety的token视为已过期
+	fake.SetTokenTTL(tokenExpirySafety / 2)
+	short, _ := w.tokens.Invalidate(ctx, first)
+	next, _ := w.tokens.Token(ctx)
+	if short == next || fake.Calls(wechatfake.APIToken) != 3 {
+		t.Fatalf("即将过期的token应重新获取，调用了 %d 次", fake.Calls(wechatfake.APIToken))
+	}
+}
+
+func TestProactiveRefreshIgnoresExpiringSharedToken(t *testing.T) {
+	fake := wechatfake.New(testAppID, testAppSecret)
+	baseURL := newFakeServer(t, fake)
+	instanceA, instanceB := newWeChatFor(baseURL), newWeChatFor(baseURL)
+	useSharedTokenStore(t, instanceA, instanceB)
+	ctx := context.Background()
+
+	// 签发的token在tokenRefreshMargin内过期，但距tokenExpirySafety还很远
+	fake.SetTokenTTL(tokenRefreshMargin - time.Minute)
+	expiring, err := instanceA.tokens.Token(ctx)
+	if err != nil {
+		t.Fatalf("获取token失败: %v", err)
+	}
+	fake.SetTokenTTL(wechatfake.DefaultTokenTTL)
+
+	// 共享存储中就是这个即将过期的token，提前刷新不能把它当作刷新结果
+	instanceA.tokens.refreshIfDue(ctx)
+	if calls := fake.Calls(wechatfake.APIToken); calls != 2 {
+		t.Fatalf("提前刷新应调用微信接口，共调用 %d 次", calls)
+	}
+	refreshed, _ := instanceA.tokens.Token(ctx)
+	if refreshed == expiring {
+		t.Fatal("提前刷新后仍在使用即将过期的token")
+	}
+
+	// 刷新后不再到期，不会重复调用
+	instanceA.tokens.refreshIfDue(ctx)
+	if calls := fake.Calls(wechatfake.APIToken); calls != 2 {
+		t.Fatalf("未到期时不应刷新，共调用 %d 次", calls)
+	}
+
+	// 另一实例直接使用共享存储中的新token
+	if shared, _ := instanceB.tokens.Token(ctx); shared != refreshed {
+		t.Fatalf("实例B拿到 %q，期望共享的 %q", shared, refreshed)
+	}
+	if calls := fake.Calls(wechatfake.APIToken); calls != 2 {
+		t.Fatalf("实例B不应自行刷新，共调用 %d 次", calls)
+	}
+}
+
+func TestProactiveRefreshSkipsUnusedInstance(t *testing.T) {
+	w, fake := newFakeWeChat(t)
+	w.tokens.refreshIfDue(context.Background())
+	if calls := fake.Calls(wechatfake.APIToken); calls != 0 {
+		t.Fatalf("尚未获取过token的实例不应刷新，调用了 %d 次", calls)
+	}
+}
+
+func TestInvalidateAfterRevoke(t *testing.T) {
+	fake := wechatfake.New(testAppID, testAppSecret)
+	baseURL := newFakeServer(t, fake)
+	instanceA, instanceB := newWeChatFor(baseURL), newWeChatFor(baseURL)
+	useSharedTokenStore(t, instanceA, instanceB)
+	ctx := context.Background()
+
+	stale, _ := instanceA.tokens.Token(ctx)
+	if token, _ := instanceB.tokens.Token(ctx); token != stale {
+		t.Fatalf("两个实例应共用同一个token")
+	}
+
+	// token在别处被刷新后失效，实例B收到40001后刷新
+	fake.RevokeTokens()
+	fresh, err := instanceB.tokens.Invalidate(ctx, stale)
+	if err != nil || fresh == stale {
+		t.Fatalf("Invalidate = %q, %v", fresh, err)
+	}
+
+	// 实例A随后也收到40001，直接使用实例B写入共享存储的token
+	if token, _ := instanceA.tokens.Invalidate(ctx, stale); token != fresh {
+		t.Fatalf("实例A拿到 %q，期望 %q", token, fresh)
+	}
+	if calls := fake.Calls(wechatfake.APIToken); calls != 2 {
+		t.Fatalf("失效后只应刷新一次，共调用 %d 次", calls)
+	}
+}
+
+func TestConcurrentRefreshCoalesced(t *testing.T) {
+	w, fake := newFakeWeChat(t)
+	ctx := context.Background()
+
+	results := make(chan string, 20)
+	for i := 0; i < cap(results); i++ {
+		go func() {
+			token, _ := w.tokens.Token(ctx)
+			results <- token
+		}()
+	}
+	first := <-results
+	for i := 1; i < cap(results); i++ {
+		if token := <-results; token != first {
+			t.Fatalf("并发请求拿到不同的token: %q %q", first, token)
+		}
+	}
+	if calls := fake.Calls(wechatfake.APIToken); calls != 1 {
+		t.Fatalf("并发刷新应合并为一次，调用了 %d 次", calls)
+	}
+}
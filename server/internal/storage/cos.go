@@ -0,0 +1,299 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cosSignValidity 请求签名的有效期
+const cosSignValidity = 10 * time.Minute
+
+// COS 腾讯云对象存储，通过XML API访问，请求按COS签名算法（q-sign-algorithm=sha1）签名
+type COS struct {
+	endpoint  string // https://<bucket>.cos.<region>.myqcloud.com
+	publicURL string // 公开访问地址前缀，如CDN域名，为空时使用endpoint
+	host      string
+	secretID  string
+	secretKey string
+	client    *http.Client
+}
+
+// NewCOS 创建COS存储，bucket为带APPID的完整名称，如 examplebucket-1250000000
+// 头像等公开访问的对象要求存储桶（或对应前缀）为公有读
+func NewCOS(bucket, region, secretID, secretKey, publicURL string) (*COS, error) {
+	if bucket == "" || region == "" || secretID == "" || secretKey == "" {
+		return nil, fmt.Errorf("COS配置不完整，需要COS_BUCKET、COS_REGION、COS_SECRET_ID、COS_SECRET_KEY")
+	}
+	host := fmt.Sprintf("%s.cos.%s.myqcloud.com", bucket, region)
+	if publicURL == "" {
+		publicURL = "https://" + host
+	}
+	return &COS{
+		endpoint:  "https://" + host,
+		publicURL: publicURL,
+		host:      host,
+		secretID:  secretID,
+		secretKey: secretKey,
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// objectPath 对key的每一段分别转义
+func objectPath(key string) string {
+	parts := strings.Split(key, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return "/" + strings.Join(parts, "/")
+}
+
+func (c *COS) URL(key string) string {
+	return joinURL(c.publicURL, key)
+}
+
+// PresignPut 签名放在查询参数中，只对host签名，客户端上传时可自行设置Content-Type
+func (c *COS) PresignPut(key string, expires time.Duration) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	path := "/" + key
+	now := time.Now()
+	authorization := c.sign(http.MethodPut, path, url.Values{}, http.Header{"Host": {c.host}}, now, now.Add(expires))
+	return c.endpoint + objectPath(key) + "?" + authorization, nil
+}
+
+func (c *COS) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	req, err := c.newRequest(ctx, http.MethodPut, key, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = int64(len(data))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (c *COS) Get(ctx context.Context, key string) (*Object, error) {
+	return c.get(ctx, key, -1)
+}
+
+// GetLimited Content-Length超过maxSize时不读取响应体，读取时同样限制字节数
+func (c *COS) GetLimited(ctx context.Context, key string, maxSize int64) (*Object, error) {
+	return c.get(ctx, key, maxSize)
+}
+
+// get 读取对象，maxSize小于0时不限制大小
+func (c *COS) get(ctx context.Context, key string, maxSize int64) (*Object, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	req, err := c.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var body io.Reader = resp.Body
+	if maxSize >= 0 {
+		if resp.ContentLength > maxSize {
+			return nil, ErrTooLarge
+		}
+		body = io.LimitReader(resp.Body, maxSize+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("读取COS对象失败: %v", err)
+	}
+	if maxSize >= 0 && int64(len(data)) > maxSize {
+		return nil, ErrTooLarge
+	}
+	object := &Object{Data: data, ContentType: resp.Header.Get("Content-Type")}
+	if modTime, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		object.ModTime = modTime
+	}
+	return object, nil
+}
+
+// cosListResult GET Bucket（列出对象）的响应
+type cosListResult struct {
+	IsTruncated bool   `xml:"IsTruncated"`
+	NextMarker  string `xml:"NextMarker"`
+	Contents    []struct {
+		Key          string `xml:"Key"`
+		LastModified string `xml:"LastModified"`
+		Size         int64  `xml:"Size"`
+	} `xml:"Contents"`
+}
+
+// List 分页列出prefix下的所有对象
+func (c *COS) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	marker := ""
+	for {
+		query := url.Values{"prefix": {prefix}, "max-keys": {"1000"}}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+"/?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Host = c.host
+		resp, err := c.do(req)
+		if err != nil {
+			return nil, err
+		}
+		var result cosListResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("解析COS对象列表失败: %v", err)
+		}
+		for _, item := range result.Contents {
+			modTime, _ := time.Parse(time.RFC3339, item.LastModified)
+			objects = append(objects, ObjectInfo{Key: item.Key, Size: item.Size, ModTime: modTime})
+		}
+		if !result.IsTruncated || len(result.Contents) == 0 {
+			return objects, nil
+		}
+		// 未指定delimiter时COS可能不返回NextMarker，此时以本页最后一个key继续
+		marker = result.NextMarker
+		if marker == "" {
+			marker = result.Contents[len(result.Contents)-1].Key
+		}
+	}
+}
+
+func (c *COS) Delete(ctx context.Context, key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	req, err := c.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(req)
+	if err == ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (c *COS) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+objectPath(key), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Host = c.host
+	return req, nil
+}
+
+// do 签名并发送请求，404返回ErrNotFound，其他非2xx状态返回COS的错误信息
+func (c *COS) do(req *http.Request) (*http.Response, error) {
+	headers := http.Header{"Host": {c.host}}
+	if contentType := req.Header.Get("Content-Type"); contentType != "" {
+		headers.Set("Content-Type", contentType)
+	}
+	if req.ContentLength > 0 {
+		headers.Set("Content-Length", strconv.FormatInt(req.ContentLength, 10))
+	}
+	now := time.Now()
+	req.Header.Set("Authorization", c.sign(req.Method, req.URL.Path, req.URL.Query(), headers, now, now.Add(cosSignValidity)))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求COS失败: %v", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("COS返回错误: %d %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+// sign 按COS签名算法生成Authorization，path为未转义的对象路径，参与签名的查询参数和请求头由调用方指定
+func (c *COS) sign(method, path string, params url.Values, headers http.Header, start, end time.Time) string {
+	keyTime := fmt.Sprintf("%d;%d", start.Unix(), end.Unix())
+	paramList, paramString := canonicalize(params)
+	headerList, headerString := canonicalize(url.Values(headers))
+
+	httpString := strings.ToLower(method) + "\n" + path + "\n" + paramString + "\n" + headerString + "\n"
+	httpStringSum := sha1.Sum([]byte(httpString))
+	stringToSign := "sha1\n" + keyTime + "\n" + hex.EncodeToString(httpStringSum[:]) + "\n"
+	signKey := hmacSHA1(c.secretKey, keyTime)
+	signature := hmacSHA1(signKey, stringToSign)
+
+	return "q-sign-algorithm=sha1" +
+		"&q-ak=" + c.secretID +
+		"&q-sign-time=" + keyTime +
+		"&q-key-time=" + keyTime +
+		"&q-header-list=" + headerList +
+		"&q-url-param-list=" + paramList +
+		"&q-signature=" + signature
+}
+
+// canonicalize 键转小写后排序，返回以;分隔的键列表和key=value&...形式的字符串，键和值均按URL编码
+func canonicalize(values url.Values) (string, string) {
+	encoded := make(map[string]string, len(values))
+	keys := make([]string, 0, len(values))
+	for key, items := range values {
+		name := cosEscape(strings.ToLower(key))
+		value := ""
+		if len(items) > 0 {
+			value = items[0]
+		}
+		encoded[name] = cosEscape(value)
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + encoded[key]
+	}
+	return strings.Join(keys, ";"), strings.Join(pairs, "&")
+}
+
+// cosEscape 按RFC 3986编码，空格编码为%20
+func cosEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func hmacSHA1(key, message string) string {
+	mac := hmac.New(sha1.New, []byte(key))
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestCOS 请求发往httptest服务，签名仍按存储桶域名计算
+func newTestCOS(t *testing.T, handler http.HandlerFunc) *COS {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "q-sign-algorithm=sha1&") {
+			http.Error(w, "missing signature", http.StatusForbidden)
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	cos, err := NewCOS("examplebucket-1250000000", "ap-guangzhou", "AKIDtest", "secret", "")
+	if err != nil {
+		t.Fatalf("创建COS失败: %v", err)
+	}
+	cos.endpoint = server.URL
+	cos.client = server.Client()
+	return cos
+}
+
+func TestCOSGetLimited(t *testing.T) {
+	cos := newTestCOS(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/small":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("12345"))
+		case "/large":
+			w.Header().Set("Content-Length", "1048576")
+			w.WriteHeader(http.StatusOK)
+			// 只写出一部分，客户端应根据Content-Length直接拒绝
+			w.Write([]byte("123456"))
+		case "/chunked":
+			// 不带Content-Length，读取时按上限截断
+			w.Write([]byte("1234"))
+			w.(http.Flusher).Flush()
+			w.Write([]byte("5678"))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	ctx := context.Background()
+
+	object, err := cos.GetLimited(ctx, "small", 5)
+	if err != nil || string(object.Data) != "12345" || object.ContentType != "image/png" {
+		t.Fatalf("GetLimited(small) = %v, %v", object, err)
+	}
+	if _, err := cos.GetLimited(ctx, "large", 1024); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("Content-Length超过上限应返回ErrTooLarge，实际 %v", err)
+	}
+	if _, err := cos.GetLimited(ctx, "chunked", 6); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("分块响应超过上限应返回ErrTooLarge，实际 %v", err)
+	}
+	if object, err := cos.Get(ctx, "chunked"); err != nil || string(object.Data) != "12345678" {
+		t.Fatalf("Get不限制大小: %v %v", object, err)
+	}
+	if _, err := cos.GetLimited(ctx, "missing", 5); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("404应返回ErrNotFound，实际 %v", err)
+	}
+}
+
+func TestCOSList(t *testing.T) {
+	keys := []string{"uploads/avatars/1/a", "uploads/avatars/1/b", "uploads/avatars/2/c"}
+	var markers []string
+	cos := newTestCOS(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" || r.URL.Query().Get("prefix") != "uploads/avatars/" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		// url.ParseQuery不接受;分隔的值，这里直接查找参数列表
+		if !strings.Contains(r.Header.Get("Authorization"), "max-keys;prefix&") {
+			http.Error(w, "query not signed", http.StatusForbidden)
+			return
+		}
+		marker := r.URL.Query().Get("marker")
+		markers = append(markers, marker)
+		// 每页两个对象，不返回NextMarker
+		start := 0
+		for i, key := range keys {
+			if key == marker {
+				start = i + 1
+			}
+		}
+		end := start + 2
+		if end > len(keys) {
+			end = len(keys)
+		}
+		fmt.Fprintf(w, "<ListBucketResult><IsTruncated>%t</IsTruncated>", end < len(keys))
+		for _, key := range keys[start:end] {
+			fmt.Fprintf(w, "<Contents><Key>%s</Key><LastModified>2024-05-01T08:00:00.000Z</LastModified><Size>%d</Size></Contents>", key, len(key))
+		}
+		fmt.Fprint(w, "</ListBucketResult>")
+	})
+
+	objects, err := cos.List(context.Background(), "uploads/avatars/")
+	if err != nil {
+		t.Fatalf("List失败: %v", err)
+	}
+	if len(objects) != len(keys) {
+		t.Fatalf("列出 %d 个对象，期望 %d", len(objects), len(keys))
+	}
+	for i, object := range objects {
+		if object.Key != keys[i] || object.Size != int64(len(keys[i])) || object.ModTime.IsZero() {
+			t.Errorf("对象 %d = %+v", i, object)
+		}
+	}
+	if strings.Join(markers, ",") != ",uploads/avatars/1/b" {
+		t.Fatalf("分页marker = %q", markers)
+	}
+}
@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Local 保存在本地目录中，适用于单实例部署和开发环境，公开访问由本服务的文件接口提供
+type Local struct {
+	dir       string
+	publicURL string // 文件接口的地址前缀，如 https://example.com/api/v1/files
+}
+
+// NewLocal 创建本地存储，目录不存在时创建
+func NewLocal(dir, publicURL string) (*Local, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建存储目录失败: %v", err)
+	}
+	return &Local{dir: dir, publicURL: publicURL}, nil
+}
+
+func (l *Local) URL(key string) string {
+	return joinURL(l.publicURL, key)
+}
+
+func (l *Local) path(key string) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(key)), nil
+}
+
+// Put 先写临时文件再重命名，读取方不会读到写了一半的文件
+func (l *Local) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// Get 本地文件不保存Content-Type，按内容识别
+func (l *Local) Get(ctx context.Context, key string) (*Object, error) {
+	return l.read(key, -1)
+}
+
+func (l *Local) GetLimited(ctx context.Context, key string, maxSize int64) (*Object, error) {
+	return l.read(key, maxSize)
+}
+
+// read 读取文件，maxSize小于0时不限制大小
+func (l *Local) read(key string, maxSize int64) (*Object, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	var reader io.Reader = file
+	if maxSize >= 0 {
+		if info.Size() > maxSize {
+			return nil, ErrTooLarge
+		}
+		// 文件可能在Stat之后被追加，读取时仍需限制
+		reader = io.LimitReader(file, maxSize+1)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if maxSize >= 0 && int64(len(data)) > maxSize {
+		return nil, ErrTooLarge
+	}
+	return &Object{Data: data, ContentType: http.DetectContentType(data), ModTime: info.ModTime()}, nil
+}
+
+// List 遍历prefix所在的目录，跳过Put写了一半的临时文件
+func (l *Local) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	root := l.dir
+	if dir := path.Dir(prefix); strings.Contains(prefix, "/") && dir != "." {
+		if err := validateKey(dir); err != nil {
+			return nil, err
+		}
+		root = filepath.Join(l.dir, filepath.FromSlash(dir))
+	}
+	var objects []ObjectInfo
+	err := filepath.WalkDir(root, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".tmp-") {
+			return nil
+		}
+		rel, err := filepath.Rel(l.dir, file)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, ObjectInfo{Key: key, Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return objects, err
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
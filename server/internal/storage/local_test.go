@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLocal(t *testing.T) *Local {
+	t.Helper()
+	local, err := NewLocal(t.TempDir(), "https://example.com/api/v1/files")
+	if err != nil {
+		t.Fatalf("创建本地存储失败: %v", err)
+	}
+	return local
+}
+
+func TestLocalGetLimited(t *testing.T) {
+	ctx := context.Background()
+	local := newTestLocal(t)
+	if err := local.Put(ctx, "uploads/a", []byte("12345"), ""); err != nil {
+		t.Fatalf("Put失败: %v", err)
+	}
+
+	object, err := local.GetLimited(ctx, "uploads/a", 5)
+	if err != nil || string(object.Data) != "12345" {
+		t.Fatalf("等于上限时应读取成功: %v %v", object, err)
+	}
+	if _, err := local.GetLimited(ctx, "uploads/a", 4); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("超过上限应返回ErrTooLarge，实际 %v", err)
+	}
+	if _, err := local.GetLimited(ctx, "uploads/missing", 4); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("不存在时应返回ErrNotFound，实际 %v", err)
+	}
+	if _, err := local.GetLimited(ctx, "../a", 4); err == nil {
+		t.Fatal("应拒绝目录之外的路径")
+	}
+}
+
+func TestLocalList(t *testing.T) {
+	ctx := context.Background()
+	local := newTestLocal(t)
+	for _, key := range []string{"uploads/avatars/1/a", "uploads/avatars/2/b", "avatars/1/c.jpg", "uploads/other"} {
+		if err := local.Put(ctx, key, []byte(key), ""); err != nil {
+			t.Fatalf("Put %s失败: %v", key, err)
+		}
+	}
+	// Put中途失败遗留的临时文件不是对象
+	if err := os.WriteFile(filepath.Join(local.dir, "uploads", "avatars", "1", ".tmp-123"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filepath.Join(local.dir, "uploads", "avatars", "1", "a"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	objects, err := local.List(ctx, "uploads/avatars/")
+	if err != nil {
+		t.Fatalf("List失败: %v", err)
+	}
+	var keys []string
+	for _, object := range objects {
+		keys = append(keys, object.Key)
+		if object.Key == "uploads/avatars/1/a" && !object.ModTime.Equal(old) {
+			t.Errorf("ModTime = %v，期望 %v", object.ModTime, old)
+		}
+		if object.Size != int64(len(object.Key)) {
+			t.Errorf("%s的Size = %d", object.Key, object.Size)
+		}
+	}
+	sort.Strings(keys)
+	if strings.Join(keys, ",") != "uploads/avatars/1/a,uploads/avatars/2/b" {
+		t.Fatalf("列出的对象 = %v", keys)
+	}
+
+	if objects, err := local.List(ctx, "missing/"); err != nil || len(objects) != 0 {
+		t.Fatalf("目录不存在时应返回空列表: %v %v", objects, err)
+	}
+}
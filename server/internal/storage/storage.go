@@ -0,0 +1,74 @@
+// Package storage 文件存储抽象，支持本地目录和腾讯云COS
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound 对象不存在
+	ErrNotFound = errors.New("对象不存在")
+	// ErrTooLarge 对象超过读取上限
+	ErrTooLarge = errors.New("对象超过大小上限")
+)
+
+// Object 存储的对象
+type Object struct {
+	Data        []byte
+	ContentType string
+	ModTime     time.Time
+}
+
+// Storage 按key读写对象，key为以/分隔的相对路径，如 qrcode/room/1/release.png
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get 读取对象，不存在时返回ErrNotFound
+	Get(ctx context.Context, key string) (*Object, error)
+	// GetLimited 读取不超过maxSize字节的对象，超过时返回ErrTooLarge，不会读入整个对象
+	// 用于读取客户端上传、大小不受本服务控制的对象
+	GetLimited(ctx context.Context, key string, maxSize int64) (*Object, error)
+	// Delete 删除对象，不存在时不报错
+	Delete(ctx context.Context, key string) error
+	// URL 对象的公开访问地址，如头像地址
+	URL(key string) string
+}
+
+// Presigner 支持预签名URL的存储，客户端凭URL直接上传，不经过本服务
+type Presigner interface {
+	// PresignPut 生成有效期为expires的PUT上传地址
+	PresignPut(key string, expires time.Duration) (string, error)
+}
+
+// ObjectInfo 列举对象时返回的信息
+type ObjectInfo struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// Lister 支持按前缀列举对象的存储，用于清理过期的临时文件
+type Lister interface {
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+}
+
+// joinURL 拼接公开地址前缀和key
+func joinURL(base, key string) string {
+	return strings.TrimSuffix(base, "/") + objectPath(key)
+}
+
+// validateKey 拒绝绝对路径、..和反斜杠，避免本地存储写到目录之外
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("无效的存储路径: %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("无效的存储路径: %q", key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// Package tracing 初始化OpenTelemetry链路追踪：按配置选择导出器，
+// 设置全局TracerProvider和W3C traceparent传播，各包通过otel.Tracer创建span
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Config 追踪配置
+type Config struct {
+	Exporter    string    // none、stdout、otlp
+	Endpoint    string    // OTLP/HTTP地址，如 http://127.0.0.1:4318
+	ServiceName string
+	SampleRatio float64
+	Output      io.Writer // stdout导出的目标，nil时为标准输出
+}
+
+// Init 按配置启用追踪，返回的函数用于关闭时导出剩余span
+// 未启用时仍设置traceparent传播，上游的追踪上下文可以继续传给下游
+func Init(cfg Config) (func(ctx context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "stdout":
+		output := cfg.Output
+		if output == nil {
+			output = os.Stdout
+		}
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(output))
+	case "otlp":
+		exporter, err = otlptracehttp.New(context.Background(),
+			otlptracehttp.WithEndpointURL(strings.TrimSuffix(cfg.Endpoint, "/")+"/v1/traces"))
+	default:
+		return nil, fmt.Errorf("未知的追踪导出方式: %s", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("创建追踪导出器失败: %w", err)
+	}
+
+	// 上游已做出采样决定时沿用，否则按追踪ID比例采样，同一追踪在各服务中的决定一致
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(cfg.ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
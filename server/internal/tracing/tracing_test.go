@@ -0,0 +1,129 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// useTracing 按配置启用追踪，返回的函数导出剩余span；测试结束时恢复为未启用
+func useTracing(t *testing.T, cfg Config) func() {
+	t.Helper()
+	shutdown, err := Init(cfg)
+	if err != nil {
+		t.Fatalf("初始化追踪失败: %v", err)
+	}
+	var once sync.Once
+	flush := func() {
+		once.Do(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdown(ctx); err != nil {
+				t.Errorf("关闭追踪失败: %v", err)
+			}
+		})
+	}
+	t.Cleanup(func() {
+		flush()
+		otel.SetTracerProvider(noop.NewTracerProvider())
+	})
+	return flush
+}
+
+func TestInitUnknownExporter(t *testing.T) {
+	if _, err := Init(Config{Exporter: "jaeger"}); err == nil {
+		t.Fatal("未知的导出方式应返回错误")
+	}
+}
+
+func TestStdoutExporter(t *testing.T) {
+	var buf bytes.Buffer
+	flush := useTracing(t, Config{Exporter: "stdout", ServiceName: "mahjong-test", SampleRatio: 1, Output: &buf})
+
+	ctx, root := otel.Tracer("test").Start(context.Background(), "HTTP GET getRoom")
+	_, child := otel.Tracer("test").Start(ctx, "sql.Query")
+	child.End()
+	root.End()
+	flush()
+
+	output := buf.String()
+	for _, want := range []string{"HTTP GET getRoom", "sql.Query", "mahjong-test", root.SpanContext().TraceID().String()} {
+		if !strings.Contains(output, want) {
+			t.Errorf("stdout输出中缺少 %q", want)
+		}
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	var bodies int
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		if bytes.Contains(body, []byte("MahjongService.GetRoom")) {
+			bodies++
+		}
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	flush := useTracing(t, Config{Exporter: "otlp", Endpoint: collector.URL + "/", ServiceName: "mahjong-test", SampleRatio: 1})
+	_, span := otel.Tracer("test").Start(context.Background(), "MahjongService.GetRoom")
+	span.End()
+	flush()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(paths) == 0 || paths[0] != "/v1/traces" {
+		t.Fatalf("Collector收到的请求路径 = %v，期望 /v1/traces", paths)
+	}
+	if bodies != 1 {
+		t.Fatalf("Collector未收到span")
+	}
+}
+
+func TestSamplingFollowsParent(t *testing.T) {
+	var buf bytes.Buffer
+	useTracing(t, Config{Exporter: "stdout", SampleRatio: 0, Output: &buf})
+
+	// 本服务不采样，但上游已采样的追踪要继续记录
+	tests := []struct {
+		name        string
+		traceparent string
+		recording   bool
+	}{
+		{"没有上游", "", false},
+		{"上游已采样", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", true},
+		{"上游未采样", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.traceparent != "" {
+				header.Set("traceparent", tt.traceparent)
+			}
+			ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.HeaderCarrier(header))
+			_, span := otel.Tracer("test").Start(ctx, "HTTP GET")
+			defer span.End()
+			if span.IsRecording() != tt.recording {
+				t.Fatalf("IsRecording = %v，期望 %v", span.IsRecording(), tt.recording)
+			}
+			if tt.traceparent != "" && span.SpanContext().TraceID().String() != "0af7651916cd43dd8448eb211c80319c" {
+				t.Fatalf("应沿用上游的追踪ID，实际 %s", span.SpanContext().TraceID())
+			}
+		})
+	}
+}
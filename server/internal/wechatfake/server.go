@@ -0,0 +1,459 @@
+// Package wechatfake 本地模拟的微信服务端接口，用于开发模式（--dev）和测试，
+// 不需要真实的AppID、AppSecret和外网访问
+package wechatfake
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 模拟的接口名，与WeChatService指标中的api标签一致
+const (
+	APIJSCode2Session    = "jscode2session"
+	APIToken             = "token"
+	APIGetWxaCodeUnlimit = "getwxacodeunlimit"
+	APISubscribeSend     = "subscribe_send"
+	APIMsgSecCheck       = "msg_sec_check"
+)
+
+// DefaultRiskyWords 默认判定为违规的词，开发时可用于验证内容安全检测的处理
+var DefaultRiskyWords = []string{"测试违规"}
+
+// DefaultTokenTTL 与微信一致，access_token有效期7200秒
+const DefaultTokenTTL = 2 * time.Hour
+
+// Failure 预设的错误响应，用于测试错误处理
+type Failure struct {
+	ErrCode int
+	ErrMsg  string
+}
+
+// SubscribeMessage 收到的订阅消息
+type SubscribeMessage struct {
+	ToUser           string                       `json:"touser"`
+	TemplateID       string                       `json:"template_id"`
+	Page             string                       `json:"page"`
+	MiniprogramState string                       `json:"miniprogram_state"`
+	Lang             string                       `json:"lang"`
+	Data             map[string]map[string]string `json:"data"`
+}
+
+// Server 模拟微信接口的HTTP服务，实现http.Handler，测试中可直接用httptest.NewServer启动
+type Server struct {
+	appID     string // 为空时接受任意appid
+	appSecret string // 为空时接受任意secret
+	tokenTTL  time.Duration
+
+	mu       sync.Mutex
+	tokens   map[string]time.Time // 有效的access_token及其过期时间
+	tokenSeq int
+	failures map[string][]Failure
+	calls    map[string]int
+	messages []SubscribeMessage
+	risky    []string
+
+	mux *http.ServeMux
+}
+
+// New 创建模拟服务，appID和appSecret用于校验请求参数
+func New(appID, appSecret string) *Server {
+	s := &Server{
+		appID:     appID,
+		appSecret: appSecret,
+		tokenTTL:  DefaultTokenTTL,
+		tokens:    make(map[string]time.Time),
+		failures:  make(map[string][]Failure),
+		calls:     make(map[string]int),
+		risky:     DefaultRiskyWords,
+		mux:       http.NewServeMux(),
+	}
+	s.mux.HandleFunc("/sns/jscode2session", s.handleJSCode2Session)
+	s.mux.HandleFunc("/cgi-bin/token", s.handleToken)
+	s.mux.HandleFunc("/wxa/getwxacodeunlimit", s.handleGetWxaCodeUnlimit)
+	s.mux.HandleFunc("/cgi-bin/message/subscribe/send", s.handleSubscribeSend)
+	s.mux.HandleFunc("/wxa/msg_sec_check", s.handleMsgSecCheck)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Start 在addr（如127.0.0.1:0）上启动服务，返回可传给WeChatService.SetBaseURL的地址和停止函数
+func (s *Server) Start(addr string) (string, func() error, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", nil, fmt.Errorf("启动微信模拟服务失败: %v", err)
+	}
+	server := &http.Server{Handler: s, ReadHeaderTimeout: 5 * time.Second}
+	go server.Serve(listener)
+	return "http://" + listener.Addr().String(), server.Close, nil
+}
+
+// SetTokenTTL 修改之后签发的access_token的有效期，用于测试过期和提前刷新
+func (s *Server) SetTokenTTL(ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokenTTL = ttl
+}
+
+// FailNext 让api的下一次调用返回指定错误，多次调用依次生效
+func (s *Server) FailNext(api string, errCode int, errMsg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[api] = append(s.failures[api], Failure{ErrCode: errCode, ErrMsg: errMsg})
+}
+
+// RevokeTokens 使已签发的access_token全部失效，模拟token在其他地方被刷新
+func (s *Server) RevokeTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = make(map[string]time.Time)
+}
+
+// Calls 返回api被调用的次数（含失败的调用）
+func (s *Server) Calls(api string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[api]
+}
+
+// Messages 返回已成功发送的订阅消息
+func (s *Server) Messages() []SubscribeMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SubscribeMessage(nil), s.messages...)
+}
+
+// SetRiskyWords 设置msg_sec_check判定为违规的词，包含其中任一词的文本返回risky
+func (s *Server) SetRiskyWords(words ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.risky = append([]string(nil), words...)
+}
+
+// OpenID 按appid和code生成固定的openid，同一个code总是对应同一个用户，
+// 开发时可在模拟器中传入固定的code切换用户
+func OpenID(appID, code string) string {
+	sum := sha256.Sum256([]byte(appID + ":" + code))
+	return "odev" + hex.EncodeToString(sum[:12])
+}
+
+// UnionID 按code生成固定的unionid，与appid无关，用不同的appid和同一个code登录可模拟同一个人使用多个小程序
+func UnionID(code string) string {
+	sum := sha256.Sum256([]byte("unionid:" + code))
+	return "udev" + hex.EncodeToString(sum[:12])
+}
+
+// SessionKey 按openid生成固定的session_key（16字节，base64编码），与jscode2session返回的一致
+func SessionKey(openID string) string {
+	sum := sha256.Sum256([]byte("session_key:" + openID))
+	return base64.StdEncoding.EncodeToString(sum[:16])
+}
+
+// EncryptData 按微信开放数据的方式加密data（AES-128-CBC，PKCS#7填充），并加上appid水印，
+// 用于模拟getPhoneNumber、getShareInfo等接口返回的encryptedData和iv
+func EncryptData(appID, sessionKey string, data map[string]interface{}) (encryptedData, iv string, err error) {
+	key, err := base64.StdEncoding.DecodeString(sessionKey)
+	if err != nil {
+		return "", "", fmt.Errorf("session_key格式错误: %v", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", "", err
+	}
+
+	payload := make(map[string]interface{}, len(data)+1)
+	for k, v := range data {
+		payload[k] = v
+	}
+	payload["watermark"] = map[string]interface{}{"appid": appID, "timestamp": time.Now().Unix()}
+	plain, _ := json.Marshal(payload)
+	padding := aes.BlockSize - len(plain)%aes.BlockSize
+	plain = append(plain, bytes.Repeat([]byte{byte(padding)}, padding)...)
+
+	ivBytes := make([]byte, aes.BlockSize)
+	rand.Read(ivBytes)
+	encrypted := make([]byte, len(plain))
+	cipher.NewCBCEncrypter(block, ivBytes).CryptBlocks(encrypted, plain)
+	return base64.StdEncoding.EncodeToString(encrypted), base64.StdEncoding.EncodeToString(ivBytes), nil
+}
+
+// begin 记录一次调用并返回预设的错误
+func (s *Server) begin(api string) *Failure {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls[api]++
+	if queue := s.failures[api]; len(queue) > 0 {
+		failure := queue[0]
+		s.failures[api] = queue[1:]
+		return &failure
+	}
+	return nil
+}
+
+func (s *Server) handleJSCode2Session(w http.ResponseWriter, r *http.Request) {
+	if failure := s.begin(APIJSCode2Session); failure != nil {
+		writeError(w, failure.ErrCode, failure.ErrMsg)
+		return
+	}
+	query := r.URL.Query()
+	if failure := s.checkCredentials(query.Get("appid"), query.Get("secret")); failure != nil {
+		writeError(w, failure.ErrCode, failure.ErrMsg)
+		return
+	}
+	code := query.Get("js_code")
+	if code == "" || query.Get("grant_type") != "authorization_code" {
+		writeError(w, 40029, "invalid code")
+		return
+	}
+
+	openID := OpenID(query.Get("appid"), code)
+	writeJSON(w, map[string]interface{}{
+		"openid":      openID,
+		"session_key": SessionKey(openID),
+		"unionid":     UnionID(code),
+	})
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if failure := s.begin(APIToken); failure != nil {
+		writeError(w, failure.ErrCode, failure.ErrMsg)
+		return
+	}
+	query := r.URL.Query()
+	if query.Get("grant_type") != "client_credential" {
+		writeError(w, 40002, "invalid grant_type")
+		return
+	}
+	if failure := s.checkCredentials(query.Get("appid"), query.Get("secret")); failure != nil {
+		writeError(w, failure.ErrCode, failure.ErrMsg)
+		return
+	}
+
+	s.mu.Lock()
+	s.tokenSeq++
+	token := fmt.Sprintf("fake_access_token_%d", s.tokenSeq)
+	ttl := s.tokenTTL
+	// 与微信一致，获取新token后旧token立即失效
+	s.tokens = map[string]time.Time{token: time.Now().Add(ttl)}
+	s.mu.Unlock()
+
+	writeJSON(w, map[string]interface{}{
+		"access_token": token,
+		"expires_in":   int(ttl.Seconds()),
+	})
+}
+
+func (s *Server) handleGetWxaCodeUnlimit(w http.ResponseWriter, r *http.Request) {
+	if failure := s.begin(APIGetWxaCodeUnlimit); failure != nil {
+		writeError(w, failure.ErrCode, failure.ErrMsg)
+		return
+	}
+	if failure := s.checkToken(r.URL.Query().Get("access_token")); failure != nil {
+		writeError(w, failure.ErrCode, failure.ErrMsg)
+		return
+	}
+
+	var req struct {
+		Scene string `json:"scene"`
+		Page  string `json:"page"`
+		Width int    `json:"width"`
+	}
+	if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&req) != nil {
+		writeError(w, 47001, "data format error")
+		return
+	}
+	if req.Scene == "" || len(req.Scene) > 32 {
+		writeError(w, 40169, "invalid length for scene")
+		return
+	}
+
+	data, err := qrCodeImage(req.Page+"?"+req.Scene, req.Width)
+	if err != nil {
+		writeError(w, -1, "system error")
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(data)
+}
+
+func (s *Server) handleSubscribeSend(w http.ResponseWriter, r *http.Request) {
+	if failure := s.begin(APISubscribeSend); failure != nil {
+		writeError(w, failure.ErrCode, failure.ErrMsg)
+		return
+	}
+	if failure := s.checkToken(r.URL.Query().Get("access_token")); failure != nil {
+		writeError(w, failure.ErrCode, failure.ErrMsg)
+		return
+	}
+
+	var msg SubscribeMessage
+	if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&msg) != nil {
+		writeError(w, 47001, "data format error")
+		return
+	}
+	switch {
+	case msg.ToUser == "":
+		writeError(w, 40003, "invalid openid")
+		return
+	case msg.TemplateID == "":
+		writeError(w, 40037, "invalid template_id")
+		return
+	case len(msg.Data) == 0:
+		writeError(w, 47003, "argument invalid! data is empty")
+		return
+	}
+	for key, item := range msg.Data {
+		if item["value"] == "" {
+			writeError(w, 47003, "argument invalid! data."+key+".value empty")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	s.messages = append(s.messages, msg)
+	s.mu.Unlock()
+	writeError(w, 0, "ok")
+}
+
+func (s *Server) handleMsgSecCheck(w http.ResponseWriter, r *http.Request) {
+	if failure := s.begin(APIMsgSecCheck); failure != nil {
+		writeError(w, failure.ErrCode, failure.ErrMsg)
+		return
+	}
+	if failure := s.checkToken(r.URL.Query().Get("access_token")); failure != nil {
+		writeError(w, failure.ErrCode, failure.ErrMsg)
+		return
+	}
+
+	var req struct {
+		Content string `json:"content"`
+		Version int    `json:"version"`
+		Scene   int    `json:"scene"`
+		OpenID  string `json:"openid"`
+	}
+	if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&req) != nil {
+		writeError(w, 47001, "data format error")
+		return
+	}
+	switch {
+	case req.Version != 2 || req.Scene < 1 || req.Scene > 4:
+		writeError(w, 47003, "argument invalid!")
+		return
+	case req.OpenID == "":
+		writeError(w, 40003, "invalid openid")
+		return
+	}
+
+	suggest, label := "pass", 100
+	detail := []map[string]interface{}{}
+	s.mu.Lock()
+	for _, word := range s.risky {
+		if word != "" && strings.Contains(req.Content, word) {
+			suggest, label = "risky", 20001
+			detail = append(detail, map[string]interface{}{
+				"strategy": "keyword", "errcode": 0, "suggest": "risky", "label": label, "level": 90, "keyword": word,
+			})
+		}
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, map[string]interface{}{
+		"errcode":  0,
+		"errmsg":   "ok",
+		"result":   map[string]interface{}{"suggest": suggest, "label": label},
+		"detail":   detail,
+		"trace_id": fmt.Sprintf("dev-%d", time.Now().UnixNano()),
+	})
+}
+
+// checkCredentials 校验appid和secret，未配置时不校验
+func (s *Server) checkCredentials(appID, secret string) *Failure {
+	if appID == "" || (s.appID != "" && appID != s.appID) {
+		return &Failure{ErrCode: 40013, ErrMsg: "invalid appid"}
+	}
+	if secret == "" || (s.appSecret != "" && secret != s.appSecret) {
+		return &Failure{ErrCode: 40125, ErrMsg: "invalid appsecret"}
+	}
+	return nil
+}
+
+// checkToken 校验access_token是否由本服务签发且未过期
+func (s *Server) checkToken(token string) *Failure {
+	if token == "" {
+		return &Failure{ErrCode: 41001, ErrMsg: "access_token missing"}
+	}
+	s.mu.Lock()
+	expiresAt, ok := s.tokens[token]
+	s.mu.Unlock()
+	if !ok {
+		return &Failure{ErrCode: 40001, ErrMsg: "invalid credential, access_token is invalid or not latest"}
+	}
+	if time.Now().After(expiresAt) {
+		return &Failure{ErrCode: 42001, ErrMsg: "access_token expired"}
+	}
+	return nil
+}
+
+// writeJSON 与微信一致，出错时也返回200，通过errcode区分
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json; encoding=utf-8")
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, errCode int, errMsg string) {
+	writeJSON(w, map[string]interface{}{"errcode": errCode, "errmsg": errMsg})
+}
+
+// qrCodeImage 生成与内容对应的占位图片（并非可扫描的小程序码），同一内容总是生成相同的图片
+func qrCodeImage(content string, width int) ([]byte, error) {
+	if width < 280 {
+		width = 280
+	}
+	if width > 1280 {
+		width = 1280
+	}
+	sum := sha256.Sum256([]byte(content))
+	const modules = 16
+	cell := width / modules
+	img := image.NewGray(image.Rect(0, 0, width, width))
+	for y := 0; y < width; y++ {
+		for x := 0; x < width; x++ {
+			img.SetGray(x, y, color.Gray{Y: 255})
+		}
+	}
+	for row := 0; row < modules; row++ {
+		for col := 0; col < modules; col++ {
+			bit := row*modules + col
+			if sum[bit/8]>>(bit%8)&1 == 0 {
+				continue
+			}
+			for y := row * cell; y < (row+1)*cell; y++ {
+				for x := col * cell; x < (col+1)*cell; x++ {
+					img.SetGray(x, y, color.Gray{Y: 0})
+				}
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
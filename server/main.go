@@ -3,24 +3,45 @@ package main
 import (
 	"bufio"
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"mahjong-server/internal/config"
 	"mahjong-server/internal/database"
+	"mahjong-server/internal/events"
 	"mahjong-server/internal/handler"
 	"mahjong-server/internal/logger"
+	"mahjong-server/internal/metrics"
 	"mahjong-server/internal/service"
+	"mahjong-server/internal/storage"
+	"mahjong-server/internal/tracing"
+	"mahjong-server/internal/wechatfake"
+)
+
+// 构建信息，由构建脚本通过 -ldflags "-X main.version=... -X main.commit=... -X main.buildTime=..." 注入
+var (
+	version   = "dev"
+	commit    = "unknown"
+	buildTime = ""
 )
 
 func main() {
+	startTime := time.Now()
+
+	dev := flag.Bool("dev", false, "开发模式：微信接口使用本地模拟服务，按登录code生成固定的openid")
+	flag.Parse()
+
 	// 加载环境变量文件
 	if err := loadEnvFile("env.conf"); err != nil {
 		// 尝试加载旧的文件名
@@ -31,15 +52,11 @@ func main() {
 		}
 	}
 
-	// 初始化日志系统
-	if err := logger.InitLogger("./logs", logger.INFO); err != nil {
-		log.Fatalf("Failed to initialize logger: %v", err)
+	if *dev {
+		applyDevDefaults()
 	}
-	defer logger.GetLogger().Close()
 
-	logger.Info("麻将记分服务启动", "version", "1.0.0", "pid", os.Getpid())
-
-	// 加载配置
+	// 加载配置，日志系统初始化前的日志只输出到控制台
 	var cfg *config.Config
 	func() {
 		defer func() {
@@ -49,6 +66,37 @@ func main() {
 		}()
 		cfg = config.Load()
 	}()
+
+	// 初始化日志系统
+	logLevel, levelErr := logger.ParseLevel(cfg.Log.Level)
+	rotate := logger.RotateConfig{
+		MaxSize:    int64(cfg.Log.MaxSizeMB) << 20,
+		MaxAge:     time.Duration(cfg.Log.MaxAgeDays) * 24 * time.Hour,
+		MaxBackups: cfg.Log.MaxBackups,
+		Compress:   cfg.Log.Compress,
+	}
+	if err := logger.InitLogger(cfg.Log.Dir, logLevel, logger.Format(cfg.Log.Format), rotate); err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+	defer logger.GetLogger().Close()
+	if levelErr != nil {
+		logger.Warn("日志级别配置无效，使用INFO", "log_level", cfg.Log.Level)
+	}
+
+	// 收到SIGHUP时重新打开日志文件，配合logrotate等外部工具移走文件
+	reopen := make(chan os.Signal, 1)
+	signal.Notify(reopen, syscall.SIGHUP)
+	go func() {
+		for range reopen {
+			if err := logger.Reopen(); err != nil {
+				logger.Error("重新打开日志文件失败", "error", err.Error())
+			} else {
+				logger.Info("已重新打开日志文件")
+			}
+		}
+	}()
+
+	logger.Info("麻将记分服务启动", "version", version, "commit", commit, "build_time", buildTime, "pid", os.Getpid())
 	logger.Info("配置加载完成", "http_port", cfg.HTTP.Port, "database_host", cfg.Database.Host)
 
 	// 初始化数据库
@@ -66,12 +114,131 @@ func main() {
 
 	logger.Info("数据库连接成功")
 
+	// 初始化链路追踪，退出时导出剩余span
+	shutdownTracing, err := tracing.Init(tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		ServiceName: cfg.Service.Name,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		logger.Fatal("链路追踪初始化失败", "error", err.Error())
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("导出剩余追踪数据失败", "error", err.Error())
+		}
+	}()
+	logger.Info("链路追踪初始化完成", "exporter", cfg.Tracing.Exporter, "sample_ratio", cfg.Tracing.SampleRatio)
+
 	// 创建微信服务
 	wechatService := service.NewWeChatService(cfg.WeChat.AppID, cfg.WeChat.AppSecret)
-	logger.Info("微信服务初始化完成", "app_id", cfg.WeChat.AppID)
+	wechatService.SetBaseURL(cfg.WeChat.APIBase)
+	if *dev {
+		fake := wechatfake.New(cfg.WeChat.AppID, cfg.WeChat.AppSecret)
+		baseURL, stopFake, err := fake.Start("127.0.0.1:0")
+		if err != nil {
+			logger.Fatal("微信模拟服务启动失败", "error", err.Error())
+		}
+		defer stopFake()
+		wechatService.SetBaseURL(baseURL)
+		logger.Warn("开发模式：微信接口使用本地模拟服务", "base_url", baseURL)
+	}
+	if err := setupTokenStore(wechatService, cfg); err != nil {
+		logger.Fatal("access_token存储初始化失败", "error", err.Error())
+	}
+	stopTokenRefresher := wechatService.StartTokenRefresher()
+	defer stopTokenRefresher()
+	logger.Info("微信服务初始化完成", "app_id", cfg.WeChat.AppID, "token_store", cfg.WeChat.TokenStore)
+
+	// 创建广播通道（多实例部署时使用Redis）
+	broadcaster, err := newBroadcaster(cfg.Broadcast)
+	if err != nil {
+		logger.Fatal("广播通道初始化失败", "error", err.Error())
+	}
+	defer broadcaster.Close()
+	logger.Info("广播通道初始化完成", "backend", cfg.Broadcast.Backend)
+
+	hub, err := handler.NewHub(broadcaster)
+	if err != nil {
+		logger.Fatal("WebSocket Hub初始化失败", "error", err.Error())
+	}
+
+	// 创建事件总线，业务事件推送到WebSocket、统计和审计日志
+	eventBus := events.NewBus()
+	eventBus.Subscribe("websocket", hub.HandleEvent)
+	eventStats := events.NewStats()
+	eventBus.Subscribe("stats", eventStats.Handle)
+	eventBus.Subscribe("audit", events.AuditLog)
+
+	// 房间小程序码和用户头像保存在文件存储中，小程序码在房间结算后删除
+	store, err := newStorage(cfg)
+	if err != nil {
+		logger.Fatal("文件存储初始化失败", "error", err.Error())
+	}
+	logger.Info("文件存储初始化完成", "backend", cfg.Storage.Backend)
+	stopAvatarCleanup := service.StartAvatarUploadCleanup(store)
+	defer stopAvatarCleanup()
+	roomQRCodes := service.NewRoomQRCodes(db, wechatService, store)
+	eventBus.Subscribe("qrcode", roomQRCodes.HandleEvent)
+
+	// 订阅消息：房间结算时通知玩家，后台发送并重试
+	notifier, err := service.NewNotifier(db, wechatService, service.NotifierOptions{
+		RoomSettledTemplate:     cfg.Notify.RoomSettledTemplate,
+		PaymentReminderTemplate: cfg.Notify.PaymentReminderTemplate,
+		RoomSettledFields:       cfg.Notify.RoomSettledFields,
+		PaymentReminderFields:   cfg.Notify.PaymentReminderFields,
+		MiniprogramState:        cfg.Notify.MiniprogramState,
+		UserHourlyLimit:         cfg.Notify.UserHourlyLimit,
+		ReminderInterval:        cfg.Notify.ReminderInterval,
+		MaxAttempts:             cfg.Notify.MaxAttempts,
+	})
+	if err != nil {
+		logger.Fatal("订阅消息初始化失败", "error", err.Error())
+	}
+	eventBus.Subscribe("notify", notifier.HandleEvent)
+	stopNotifier := notifier.Start()
+	defer stopNotifier()
+	logger.Info("订阅消息初始化完成", "templates", len(notifier.Templates()))
+
+	// session_key加密保存，用于解密手机号、群信息等开放数据
+	var sessionKeys *service.SessionKeyCipher
+	if cfg.WeChat.SessionKeySecret != "" {
+		sessionKeys, err = service.NewSessionKeyCipher(cfg.WeChat.SessionKeySecret)
+		if err != nil {
+			logger.Fatal("session_key加密初始化失败", "error", err.Error())
+		}
+	} else {
+		logger.Warn("未设置WECHAT_SESSION_KEY_SECRET，不保存session_key，开放数据解密接口不可用")
+	}
+
+	// 昵称、房间名的内容安全检测
+	contentChecker, err := newContentChecker(cfg, wechatService)
+	if err != nil {
+		logger.Fatal("内容安全检测初始化失败", "error", err.Error())
+	}
+	logger.Info("内容安全检测初始化完成", "action", cfg.Content.Action, "wechat_check", cfg.Content.WeChatCheck)
+
+	// 导出Prometheus指标
+	registerMetrics(hub, db, eventStats)
 
 	// 创建HTTP处理器
-	httpHandler := handler.NewHTTPHandler(db, wechatService)
+	httpHandler := handler.NewHTTPHandler(db, wechatService, hub, eventBus, cfg.Admin.Token, cfg.HTTP.RequestTimeout)
+	httpHandler.SetBuildInfo(handler.BuildInfo{
+		Version:   version,
+		Commit:    commit,
+		BuildTime: buildTime,
+		StartTime: startTime,
+	})
+	httpHandler.SetRoomQRCodes(roomQRCodes)
+	httpHandler.SetStorage(store)
+	httpHandler.SetNotifier(notifier)
+	httpHandler.SetContentChecker(contentChecker)
+	httpHandler.SetSessionKeyCipher(sessionKeys)
+	httpHandler.SetRouteTimeout("admin/checkConsistency", cfg.HTTP.AdminTimeout)
+	httpHandler.SetRouteTimeout("uploadAvatar", cfg.HTTP.UploadTimeout)
 
 	// 添加CORS支持和请求日志
 	corsHandler := func(h http.Handler) http.Handler {
@@ -80,7 +247,7 @@ func main() {
 			
 			// WebSocket请求跳过CORS处理，直接传递给处理器
 			if r.URL.Path == "/ws" {
-				logger.Info("CORS处理器: WebSocket请求", "path", r.URL.Path, "method", r.Method)
+				logger.InfoContext(r.Context(), "CORS处理器: WebSocket请求", "path", r.URL.Path, "method", r.Method)
 				h.ServeHTTP(w, r)
 				return
 			}
@@ -88,11 +255,11 @@ func main() {
 			// 设置CORS头
 			w.Header().Set("Access-Control-Allow-Origin", "*")
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Session-Id")
 
 			if r.Method == "OPTIONS" {
 				w.WriteHeader(http.StatusOK)
-				logger.LogHTTPRequest(r.Method, r.URL.Path, r.RemoteAddr, http.StatusOK, time.Since(start))
+				logger.LogHTTPRequest(r.Context(), r.Method, r.URL.Path, r.RemoteAddr, http.StatusOK, time.Since(start))
 				return
 			}
 
@@ -101,7 +268,7 @@ func main() {
 			h.ServeHTTP(wrapper, r)
 			
 			// 记录请求日志
-			logger.LogHTTPRequest(r.Method, r.URL.Path, r.RemoteAddr, wrapper.statusCode, time.Since(start))
+			logger.LogHTTPRequest(r.Context(), r.Method, r.URL.Path, r.RemoteAddr, wrapper.statusCode, time.Since(start))
 		})
 	}
 
@@ -110,17 +277,27 @@ func main() {
 	
 	// WebSocket路由直接处理，不经过CORS包装器
 	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
-		logger.Info("直接WebSocket处理", "path", r.URL.Path, "method", r.Method)
+		logger.InfoContext(r.Context(), "直接WebSocket处理", "path", r.URL.Path, "method", r.Method)
 		httpHandler.ServeHTTP(w, r)
 	})
 	
-	// 其他路由经过CORS包装器
-	mux.Handle("/", corsHandler(httpHandler))
+	// Prometheus指标，仅供内网直接抓取，Nginx不对外暴露
+	mux.Handle("/metrics", metrics.Handler())
+	
+	// 存活和就绪探针，请求频繁，不经过CORS包装器和请求日志
+	mux.HandleFunc("/healthz", httpHandler.ServeLiveness)
+	mux.HandleFunc("/readyz", httpHandler.ServeReadiness)
+	
+	// 其他路由经过CORS包装器，每个请求由otelhttp创建span，旧的健康检查接口不记录追踪
+	mux.Handle("/", otelhttp.NewHandler(corsHandler(httpHandler), "HTTP",
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			return r.URL.Path != "/health" && r.URL.Path != "/api/v1/health"
+		})))
 	
 	// 启动HTTP服务器（由Nginx处理HTTPS）
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.HTTP.Port),
-		Handler:      mux,
+		Handler:      handler.WithRequestID(mux),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 	}
@@ -144,13 +321,24 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// 先停止接收新请求并等待进行中的HTTP请求完成；已升级的WebSocket连接不在其中
 	if err := server.Shutdown(ctx); err != nil {
 		logger.Error("服务器关闭失败", "error", err.Error())
 	} else {
 		logger.Info("服务器已优雅关闭")
 	}
+
+	// 通知WebSocket客户端稍后重连，并等待WebSocket命令触发的事务完成，之后由defer关闭广播通道和数据库
+	if err := httpHandler.Shutdown(ctx, restartRetryAfter); err != nil {
+		logger.Error("等待进行中的事务失败", "error", err.Error())
+	} else {
+		logger.Info("WebSocket连接和进行中的事务已处理完毕")
+	}
 }
 
+// restartRetryAfter 关闭时建议客户端重连的等待时间
+const restartRetryAfter = 5 * time.Second
+
 // responseWriter 包装http.ResponseWriter以捕获状态码
 type responseWriter struct {
 	http.ResponseWriter
@@ -162,6 +350,11 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap 供http.ResponseController访问底层连接
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // 实现http.Hijacker接口以支持WebSocket升级
 func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	hijacker, ok := rw.ResponseWriter.(http.Hijacker)
@@ -171,6 +364,126 @@ func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	return hijacker.Hijack()
 }
 
+// devDefaults 开发模式下未设置时使用的环境变量，微信接口由本地模拟服务提供
+var devDefaults = map[string]string{
+	"WECHAT_APP_ID":      "wx_dev_appid",
+	"WECHAT_APP_SECRET":  "dev_app_secret",
+	"LOG_DIR":            "logs",
+	"STORAGE_LOCAL_DIR":  "data",
+	"STORAGE_PUBLIC_URL": "http://localhost:8080/api/v1/files",
+
+	"WECHAT_SESSION_KEY_SECRET": "dev_session_key_secret",
+
+	"NOTIFY_TEMPLATE_ROOM_SETTLED":     "dev_tmpl_room_settled",
+	"NOTIFY_TEMPLATE_PAYMENT_REMINDER": "dev_tmpl_payment_reminder",
+	"NOTIFY_MINIPROGRAM_STATE":         "developer",
+
+	"CONTENT_WECHAT_CHECK": "true",
+}
+
+// applyDevDefaults 为开发模式补齐必填配置，已设置的环境变量不覆盖
+func applyDevDefaults() {
+	for key, value := range devDefaults {
+		if os.Getenv(key) == "" {
+			os.Setenv(key, value)
+		}
+	}
+}
+
+// newBroadcaster 根据配置创建房间消息的广播通道
+func newBroadcaster(cfg config.BroadcastConfig) (handler.Broadcaster, error) {
+	switch cfg.Backend {
+	case "", "memory":
+		return handler.NewMemoryBroadcaster(), nil
+	case "redis":
+		client, err := newRedisClient(cfg.Redis)
+		if err != nil {
+			return nil, err
+		}
+		return handler.NewRedisBroadcaster(client, cfg.Channel), nil
+	default:
+		return nil, fmt.Errorf("未知的广播后端: %s", cfg.Backend)
+	}
+}
+
+// newStorage 根据配置创建文件存储
+func newStorage(cfg *config.Config) (storage.Storage, error) {
+	switch cfg.Storage.Backend {
+	case "", "local":
+		// 头像地址保存在数据库中并由小程序直接加载，相对路径在小程序里无法访问
+		if !isAbsoluteURL(cfg.Storage.PublicURL) {
+			return nil, fmt.Errorf("local存储需要将STORAGE_PUBLIC_URL设为本服务文件接口的完整地址，如 https://example.com/api/v1/files")
+		}
+		return storage.NewLocal(cfg.Storage.LocalDir, cfg.Storage.PublicURL)
+	case "cos":
+		if cfg.Storage.PublicURL != "" && !isAbsoluteURL(cfg.Storage.PublicURL) {
+			return nil, fmt.Errorf("STORAGE_PUBLIC_URL必须是以http://或https://开头的完整地址")
+		}
+		return storage.NewCOS(cfg.COS.Bucket, cfg.COS.Region, cfg.COS.SecretID, cfg.COS.SecretKey, cfg.Storage.PublicURL)
+	default:
+		return nil, fmt.Errorf("未知的存储后端: %s", cfg.Storage.Backend)
+	}
+}
+
+// isAbsoluteURL 是否为带主机名的http(s)地址
+func isAbsoluteURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// newContentChecker 根据配置创建内容安全检测
+func newContentChecker(cfg *config.Config, wechatService *service.WeChatService) (*service.ContentChecker, error) {
+	opts := service.ContentCheckerOptions{WeChatCheck: cfg.Content.WeChatCheck}
+	switch cfg.Content.Action {
+	case "", "reject":
+	case "mask":
+		opts.Mask = true
+	default:
+		return nil, fmt.Errorf("未知的内容安全处理方式: %s", cfg.Content.Action)
+	}
+	if cfg.Content.KeywordsFile != "" {
+		keywords, err := service.LoadKeywords(cfg.Content.KeywordsFile)
+		if err != nil {
+			return nil, err
+		}
+		opts.Keywords = keywords
+	}
+	return service.NewContentChecker(wechatService, opts), nil
+}
+
+// setupTokenStore 根据配置设置access_token的共享存储，memory时只在进程内缓存
+func setupTokenStore(wechatService *service.WeChatService, cfg *config.Config) error {
+	switch cfg.WeChat.TokenStore {
+	case "", "memory":
+		return nil
+	case "redis":
+		client, err := newRedisClient(cfg.Broadcast.Redis)
+		if err != nil {
+			return err
+		}
+		wechatService.SetTokenStore(service.NewRedisTokenStore(client, "mahjong:wechat_access_token:"+cfg.WeChat.AppID))
+		return nil
+	default:
+		return fmt.Errorf("未知的access_token存储: %s", cfg.WeChat.TokenStore)
+	}
+}
+
+// newRedisClient 创建Redis客户端并确认可以连接
+func newRedisClient(cfg config.RedisConfig) (*redis.Client, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Addr,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("连接Redis失败: %w", err)
+	}
+	return client, nil
+}
+
 // loadEnvFile 加载环境变量文件
 func loadEnvFile(filename string) error {
 	file, err := os.Open(filename)
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"database/sql"
+
+	"mahjong-server/internal/events"
+	"mahjong-server/internal/handler"
+	"mahjong-server/internal/metrics"
+)
+
+// registerMetrics 将Hub、数据库连接池和业务计数导出为Prometheus指标
+// HTTP请求和微信接口指标由各自的包直接记录，Go运行时指标由metrics包的默认注册表提供
+func registerMetrics(hub *handler.Hub, db *sql.DB, stats *events.Stats) {
+	// WebSocket
+	metrics.NewGaugeFunc("mahjong_websocket_connections", "当前WebSocket连接数", func() float64 {
+		return float64(hub.Stats().Connections)
+	})
+	metrics.NewGaugeFunc("mahjong_websocket_rooms", "当前有WebSocket连接的房间数", func() float64 {
+		return float64(hub.Stats().Rooms)
+	})
+	metrics.NewCounterFunc("mahjong_websocket_messages_delivered_total", "写入客户端发送队列的消息数", func() float64 {
+		return float64(hub.Stats().MessagesDelivered)
+	})
+	metrics.NewCounterFunc("mahjong_websocket_messages_dropped_total", "因客户端发送队列已满而丢弃的消息数", func() float64 {
+		return float64(hub.Stats().MessagesDropped)
+	})
+	metrics.NewCounterFunc("mahjong_websocket_clients_evicted_total", "因发送队列已满被断开的客户端数", func() float64 {
+		return float64(hub.Stats().ClientsEvicted)
+	})
+	metrics.NewCounterFunc("mahjong_broadcast_publish_errors_total", "向Broadcaster发布房间消息失败的次数", func() float64 {
+		return float64(hub.Stats().PublishErrors)
+	})
+
+	// 数据库连接池
+	metrics.NewGaugeFunc("mahjong_db_max_open_connections", "数据库最大连接数", func() float64 {
+		return float64(db.Stats().MaxOpenConnections)
+	})
+	metrics.NewGaugeFunc("mahjong_db_open_connections", "当前数据库连接数", func() float64 {
+		return float64(db.Stats().OpenConnections)
+	})
+	metrics.NewGaugeFunc("mahjong_db_in_use_connections", "使用中的数据库连接数", func() float64 {
+		return float64(db.Stats().InUse)
+	})
+	metrics.NewGaugeFunc("mahjong_db_idle_connections", "空闲的数据库连接数", func() float64 {
+		return float64(db.Stats().Idle)
+	})
+	metrics.NewCounterFunc("mahjong_db_wait_count_total", "等待数据库连接的次数", func() float64 {
+		return float64(db.Stats().WaitCount)
+	})
+	metrics.NewCounterFunc("mahjong_db_wait_duration_seconds_total", "等待数据库连接的总耗时（秒）", func() float64 {
+		return db.Stats().WaitDuration.Seconds()
+	})
+
+	// 业务计数
+	metrics.NewCounterFunc("mahjong_rooms_created_total", "创建的房间数", func() float64 {
+		return float64(stats.Snapshot().RoomsCreated)
+	})
+	metrics.NewCounterFunc("mahjong_players_joined_total", "加入房间的玩家数", func() float64 {
+		return float64(stats.Snapshot().PlayersJoined)
+	})
+	metrics.NewCounterFunc("mahjong_transfers_total", "分数转移次数", func() float64 {
+		return float64(stats.Snapshot().Transfers)
+	})
+	metrics.NewCounterFunc("mahjong_transfers_voided_total", "撤销的分数转移次数", func() float64 {
+		return float64(stats.Snapshot().TransfersVoided)
+	})
+	metrics.NewCounterFunc("mahjong_rooms_settled_total", "结算的房间数", func() float64 {
+		return float64(stats.Snapshot().RoomsSettled)
+	})
+}
@@ -43,11 +43,18 @@ echo "检查微信配置..."
 check_required_var "WECHAT_APP_ID"
 check_required_var "WECHAT_APP_SECRET"
 
-echo "检查COS配置..."
-check_required_var "COS_BUCKET"
-check_required_var "COS_REGION"
-check_required_var "COS_SECRET_ID"
-check_required_var "COS_SECRET_KEY"
+# 只有使用COS作为文件存储时才需要COS配置
+if grep -q "^STORAGE_BACKEND=cos" ../env.conf; then
+    echo "检查COS配置..."
+    check_required_var "COS_BUCKET"
+    check_required_var "COS_REGION"
+    check_required_var "COS_SECRET_ID"
+    check_required_var "COS_SECRET_KEY"
+else
+    # 本地存储的头像地址由小程序直接加载，需要完整的文件接口地址
+    echo "检查本地存储配置..."
+    check_required_var "STORAGE_PUBLIC_URL"
+fi
 
 if [ $MISSING_COUNT -gt 0 ]; then
     echo ""
@@ -284,7 +291,11 @@ fi
 echo "9. 构建Go应用..."
 cd ..
 go mod tidy
-go build -o mahjong-server .
+# 注入版本信息，/healthz和/readyz中报告
+VERSION=$(git describe --tags --always --dirty 2>/dev/null || echo dev)
+COMMIT=$(git rev-parse --short HEAD 2>/dev/null || echo unknown)
+BUILD_TIME=$(date -u +%Y-%m-%dT%H:%M:%SZ)
+go build -ldflags "-X main.version=$VERSION -X main.commit=$COMMIT -X main.buildTime=$BUILD_TIME" -o mahjong-server .
 cp mahjong-server /usr/local/bin/
 chmod +x /usr/local/bin/mahjong-server
 echo "✅ Go应用构建完成"
@@ -354,7 +365,7 @@ fi
 # 12. 测试服务
 echo "12. 测试服务..."
 sleep 2
-if curl -s http://127.0.0.1:8080/health > /dev/null; then
+if curl -sf http://127.0.0.1:8080/readyz > /dev/null; then
     echo "✅ 服务健康检查通过"
 else
     echo "⚠️  服务健康检查失败，但服务可能仍在启动中"
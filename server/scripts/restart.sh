@@ -25,7 +25,11 @@ sleep 2
 echo "2. 重新构建并启动..."
 cd ..
 go mod tidy
-go build -o mahjong-server .
+# 注入版本信息，/healthz和/readyz中报告
+VERSION=$(git describe --tags --always --dirty 2>/dev/null || echo dev)
+COMMIT=$(git rev-parse --short HEAD 2>/dev/null || echo unknown)
+BUILD_TIME=$(date -u +%Y-%m-%dT%H:%M:%SZ)
+go build -ldflags "-X main.version=$VERSION -X main.commit=$COMMIT -X main.buildTime=$BUILD_TIME" -o mahjong-server .
 cp mahjong-server /usr/local/bin/
 chmod +x /usr/local/bin/mahjong-server
 
@@ -45,7 +49,7 @@ fi
 echo "3. 测试服务..."
 sleep 2
 HTTP_PORT=$(grep "^HTTP_PORT=" ../env.conf 2>/dev/null | cut -d'=' -f2 || echo "8080")
-if curl -s http://127.0.0.1:$HTTP_PORT/health > /dev/null; then
+if curl -sf http://127.0.0.1:$HTTP_PORT/readyz > /dev/null; then
     echo "✅ 服务健康检查通过"
 else
     echo "⚠️  服务健康检查失败，但服务可能仍在启动中"